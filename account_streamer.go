@@ -0,0 +1,319 @@
+package tasty
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const accountStreamerHeartbeat = 20 * time.Second
+
+// AccountStreamer receives account notifications such as order, position
+// and balance updates from the tastytrade account streamer.
+type AccountStreamer struct {
+	conn      StreamerConn
+	authToken string
+	events    chan AccountEvent
+	done      chan struct{}
+	// closed once the read loop has stopped
+	finished  chan struct{}
+	writeMu   sync.Mutex
+	requestID int
+	closeOnce sync.Once
+	errMu     sync.Mutex
+	err       error
+	listenMu  sync.Mutex
+	listeners map[chan AccountEvent]struct{}
+	stopped   bool
+	dropped   uint64
+}
+
+// ConnectAccountStreamer dials the account streamer for the client's
+// environment and connects the given accounts using the current session.
+func (c *Client) ConnectAccountStreamer(dial StreamerDialer, accountNumbers ...string) (*AccountStreamer, error) {
+	if c.Session.SessionToken == nil {
+		return nil, &Error{Code: "invalid_session", Message: "Session is invalid: Session Token cannot be nil."}
+	}
+
+	conn, err := dial(c.websocket)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := NewAccountStreamer(conn, *c.Session.SessionToken, accountNumbers...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetAccountStreamer(s)
+
+	return s, nil
+}
+
+// SetAccountStreamer sets the account streamer used by the client to watch
+// orders i.e. WaitForOrder. ConnectAccountStreamer sets this for you. The
+// streamer is detached from the client once it stops.
+func (c *Client) SetAccountStreamer(s *AccountStreamer) {
+	c.accountStreamer.Store(s)
+
+	if s != nil {
+		go func() {
+			<-s.finished
+			c.accountStreamer.CompareAndSwap(s, nil)
+		}()
+	}
+}
+
+// NewAccountStreamer sends the connect message for the accounts over an
+// open connection, waits for it to be acknowledged and starts streaming events.
+func NewAccountStreamer(conn StreamerConn, authToken string, accountNumbers ...string) (*AccountStreamer, error) {
+	s := &AccountStreamer{
+		conn:      conn,
+		authToken: authToken,
+		events:    make(chan AccountEvent, defaultEventBuffer),
+		done:      make(chan struct{}),
+		finished:  make(chan struct{}),
+		listeners: map[chan AccountEvent]struct{}{},
+	}
+
+	if err := s.connect(accountNumbers); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go s.readLoop()
+	go s.heartbeatLoop()
+
+	return s, nil
+}
+
+// Events returns the account notifications. The channel is closed when the
+// streamer stops. Like listeners it never blocks the streamer, events are
+// dropped when its buffer is full so it needn't be drained when only Listen
+// is used.
+func (s *AccountStreamer) Events() <-chan AccountEvent {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because Events wasn't drained.
+func (s *AccountStreamer) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Listen registers an additional receiver of account events with the given
+// buffer size. Listeners never block the streamer, events are dropped when a
+// listener's buffer is full. The channel is closed when stop is called or the
+// streamer stops.
+func (s *AccountStreamer) Listen(buffer int) (events <-chan AccountEvent, stop func()) {
+	ch := make(chan AccountEvent, buffer)
+
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+
+	if s.stopped {
+		close(ch)
+		return ch, func() {}
+	}
+
+	s.listeners[ch] = struct{}{}
+
+	return ch, func() {
+		s.listenMu.Lock()
+		defer s.listenMu.Unlock()
+
+		if _, ok := s.listeners[ch]; ok {
+			delete(s.listeners, ch)
+			close(ch)
+		}
+	}
+}
+
+// Err returns the error that terminated the streamer, if any.
+func (s *AccountStreamer) Err() error {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	return s.err
+}
+
+// Close stops the streamer and closes the underlying connection.
+func (s *AccountStreamer) Close() error {
+	var err error
+
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+
+	return err
+}
+
+// Order decodes an OrderNotification.
+func (ae AccountEvent) Order() (Order, error) {
+	var order Order
+	if ae.Type != OrderNotification {
+		return order, fmt.Errorf("account event %s is not an order", ae.Type)
+	}
+
+	err := json.Unmarshal(ae.Data, &order)
+
+	return order, err
+}
+
+// Position decodes a PositionNotification.
+func (ae AccountEvent) Position() (AccountPosition, error) {
+	var position AccountPosition
+	if ae.Type != PositionNotification {
+		return position, fmt.Errorf("account event %s is not a position", ae.Type)
+	}
+
+	err := json.Unmarshal(ae.Data, &position)
+
+	return position, err
+}
+
+// Balance decodes a BalanceNotification.
+func (ae AccountEvent) Balance() (AccountBalance, error) {
+	var balance AccountBalance
+	if ae.Type != BalanceNotification {
+		return balance, fmt.Errorf("account event %s is not a balance", ae.Type)
+	}
+
+	err := json.Unmarshal(ae.Data, &balance)
+
+	return balance, err
+}
+
+func (s *AccountStreamer) connect(accountNumbers []string) error {
+	id, err := s.send("connect", accountNumbers)
+	if err != nil {
+		return err
+	}
+
+	for {
+		data, err := s.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var res AccountStreamerResponse
+		if err = json.Unmarshal(data, &res); err != nil {
+			return err
+		}
+
+		if res.RequestID != id || res.Action != "connect" {
+			continue
+		}
+
+		if res.Status != "ok" {
+			return errors.New("account streamer: connect failed: " + res.Message)
+		}
+
+		return nil
+	}
+}
+
+func (s *AccountStreamer) readLoop() {
+	defer close(s.finished)
+	defer close(s.events)
+	defer s.stopListeners()
+
+	for {
+		data, err := s.conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				s.setErr(err)
+			}
+			return
+		}
+
+		var event AccountEvent
+		if err = json.Unmarshal(data, &event); err != nil {
+			s.setErr(err)
+			continue
+		}
+
+		// action responses i.e. heartbeats don't carry a notification type
+		if event.Type == "" {
+			continue
+		}
+
+		s.notifyListeners(event)
+
+		select {
+		case s.events <- event:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
+
+func (s *AccountStreamer) notifyListeners(event AccountEvent) {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+
+	for ch := range s.listeners {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (s *AccountStreamer) stopListeners() {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+
+	s.stopped = true
+	for ch := range s.listeners {
+		close(ch)
+	}
+	s.listeners = map[chan AccountEvent]struct{}{}
+}
+
+func (s *AccountStreamer) heartbeatLoop() {
+	ticker := time.NewTicker(accountStreamerHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.send("heartbeat", nil); err != nil {
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *AccountStreamer) send(action string, value []string) (int, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.requestID++
+
+	data, err := json.Marshal(AccountStreamerMessage{
+		Action:    action,
+		Value:     value,
+		AuthToken: s.authToken,
+		RequestID: s.requestID,
+	})
+	if err != nil {
+		return s.requestID, err
+	}
+
+	return s.requestID, s.conn.WriteMessage(data)
+}
+
+func (s *AccountStreamer) setErr(err error) {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	s.err = err
+}
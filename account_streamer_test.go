@@ -0,0 +1,152 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func connectedAccountStreamer(t *testing.T) (*AccountStreamer, *fakeConn) {
+	t.Helper()
+
+	conn := newFakeConn()
+	conn.in <- []byte(`{"status":"ok","action":"connect","web-socket-session-id":"abc","value":["5WT00000"],"request-id":1}`)
+
+	s, err := NewAccountStreamer(conn, testToken, "5WT00000")
+	require.NoError(t, err)
+
+	return s, conn
+}
+
+func TestNewAccountStreamer(t *testing.T) {
+	s, conn := connectedAccountStreamer(t)
+	defer s.Close()
+
+	msg := conn.next(t)
+	require.Equal(t, "connect", msg["action"])
+	require.Equal(t, []any{"5WT00000"}, msg["value"])
+	require.Equal(t, testToken, msg["auth-token"])
+	require.Equal(t, float64(1), msg["request-id"])
+}
+
+func TestNewAccountStreamerError(t *testing.T) {
+	conn := newFakeConn()
+	conn.in <- []byte(`{"status":"error","action":"connect","request-id":1,"message":"not permitted"}`)
+
+	_, err := NewAccountStreamer(conn, testToken, "5WT00000")
+	require.EqualError(t, err, "account streamer: connect failed: not permitted")
+
+	conn = newFakeConn()
+	conn.Close()
+
+	_, err = NewAccountStreamer(conn, testToken)
+	require.Error(t, err)
+}
+
+func TestAccountStreamerEvents(t *testing.T) {
+	s, conn := connectedAccountStreamer(t)
+
+	conn.in <- []byte(`{"status":"ok","action":"heartbeat","request-id":2}`)
+	conn.in <- []byte(`{"type":"Order","data":{"id":1,"account-number":"5WT00000","status":"Live","legs":[]},"timestamp":1688595114405}`)
+	conn.in <- []byte(`{"type":"CurrentPosition","data":{"symbol":"AAPL","quantity":10,"quantity-direction":"Long"},"timestamp":1688595114406}`)
+	conn.in <- []byte(`{"type":"AccountBalance","data":{"account-number":"5WT00000","cash-balance":"1000.5"},"timestamp":1688595114407}`)
+
+	event := <-s.Events()
+	require.Equal(t, OrderNotification, event.Type)
+	require.Equal(t, EpochMillis(1688595114405), event.Timestamp)
+
+	order, err := event.Order()
+	require.NoError(t, err)
+	require.Equal(t, 1, order.ID)
+	require.Equal(t, Live, order.Status)
+
+	_, err = event.Position()
+	require.EqualError(t, err, "account event Order is not a position")
+
+	position, err := (<-s.Events()).Position()
+	require.NoError(t, err)
+	require.Equal(t, "AAPL", position.Symbol)
+	require.Equal(t, Long, position.QuantityDirection)
+
+	event = <-s.Events()
+	balance, err := event.Balance()
+	require.NoError(t, err)
+	require.Equal(t, "1000.5", balance.CashBalance.String())
+
+	_, err = event.Order()
+	require.EqualError(t, err, "account event AccountBalance is not an order")
+	_, err = AccountEvent{Type: OrderNotification}.Balance()
+	require.EqualError(t, err, "account event Order is not a balance")
+
+	require.NoError(t, s.Close())
+
+	_, open := <-s.Events()
+	require.False(t, open)
+	require.NoError(t, s.Err())
+}
+
+func TestAccountStreamerUndrainedEvents(t *testing.T) {
+	s, conn := connectedAccountStreamer(t)
+	defer s.Close()
+
+	events, stop := s.Listen(1)
+	defer stop()
+
+	// listeners keep receiving while nobody reads Events
+	for i := 0; i <= defaultEventBuffer; i++ {
+		conn.in <- []byte(`{"type":"Order","data":{"id":1,"status":"Live","legs":[]},"timestamp":1688595114405}`)
+		require.Equal(t, OrderNotification, (<-events).Type)
+	}
+
+	require.Eventually(t, func() bool { return s.Dropped() == 1 }, time.Second, time.Millisecond)
+	require.Len(t, s.Events(), defaultEventBuffer)
+}
+
+func TestAccountStreamerReadError(t *testing.T) {
+	s, conn := connectedAccountStreamer(t)
+
+	conn.Close()
+
+	_, open := <-s.Events()
+	require.False(t, open)
+	require.EqualError(t, s.Err(), "connection closed")
+}
+
+func TestConnectAccountStreamer(t *testing.T) {
+	c := NewCertClient(nil)
+
+	_, err := c.ConnectAccountStreamer(nil, "5WT00000")
+	require.Error(t, err)
+
+	c.Session.SessionToken = &testToken
+
+	_, err = c.ConnectAccountStreamer(func(url string) (StreamerConn, error) {
+		require.Equal(t, streamerCertBaseURL, url)
+		return nil, errors.New("dial failed")
+	}, "5WT00000")
+	require.EqualError(t, err, "dial failed")
+}
+
+func TestSetAccountStreamer(t *testing.T) {
+	c := NewCertClient(nil)
+
+	s, _ := connectedAccountStreamer(t)
+	c.SetAccountStreamer(s)
+	require.Same(t, s, c.accountStreamer.Load())
+
+	// detached once the streamer stops
+	require.NoError(t, s.Close())
+	require.Eventually(t, func() bool { return c.accountStreamer.Load() == nil }, time.Second, time.Millisecond)
+
+	// a replaced streamer doesn't detach its successor
+	first, _ := connectedAccountStreamer(t)
+	second, _ := connectedAccountStreamer(t)
+	defer second.Close()
+	c.SetAccountStreamer(first)
+	c.SetAccountStreamer(second)
+	require.NoError(t, first.Close())
+	<-first.finished
+	require.Never(t, func() bool { return c.accountStreamer.Load() != second }, 20*time.Millisecond, time.Millisecond)
+}
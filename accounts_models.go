@@ -153,6 +153,7 @@ type AccountPosition struct {
 	RealizedToday                 decimal.Decimal `json:"realized-today"`
 	RealizedTodayEffect           PriceEffect     `json:"realized-today-effect"`
 	RealizedTodayDate             string          `json:"realized-today-date"`
+	StreamerSymbol                string          `json:"streamer-symbol"`
 	CreatedAt                     time.Time       `json:"created-at"`
 	UpdatedAt                     time.Time       `json:"updated-at"`
 }
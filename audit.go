@@ -0,0 +1,388 @@
+package tasty
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrAuditWrite is returned with the result of an order action whose
+	// audit record couldn't be written to a sink.
+	ErrAuditWrite = errors.New("audit record not written")
+	// ErrAuditGap is returned by VerifyAuditLog and VerifyAuditLogFrom when
+	// records are missing.
+	ErrAuditGap = errors.New("audit log has a gap")
+	// ErrAuditTampered is returned by VerifyAuditLog and VerifyAuditLogFrom
+	// when a record was changed.
+	ErrAuditTampered = errors.New("audit log was tampered with")
+)
+
+// AuditRecord is the record of one order action. Each record holds the hash
+// of the record before it, chaining the log so removing or changing a record
+// is detected by VerifyAuditLog.
+type AuditRecord struct {
+	// Position in the log, starting at 1
+	Seq    uint64      `json:"seq"`
+	Action AuditAction `json:"action"`
+	// When the request was sent and the response received
+	RequestedAt time.Time `json:"requested-at"`
+	RespondedAt time.Time `json:"responded-at"`
+	// Identity of the automation taking the action
+	Caller string `json:"caller"`
+	// Username of the client's session
+	Username      string `json:"username"`
+	AccountNumber string `json:"account-number"`
+	OrderID       int    `json:"order-id,omitempty"`
+	// JSON of the order, order replacement or patch
+	Request json.RawMessage `json:"request,omitempty"`
+	// JSON of the returned order or order response
+	Response   json.RawMessage     `json:"response,omitempty"`
+	OrderError *OrderErrorResponse `json:"order-error,omitempty"`
+	StatusCode int                 `json:"status-code,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	PrevHash   string              `json:"prev-hash"`
+	Hash       string              `json:"hash"`
+}
+
+// AuditSink receives every record of an audit log.
+type AuditSink interface {
+	WriteRecord(record AuditRecord) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(record AuditRecord) error
+
+// WriteRecord calls the function.
+func (f AuditSinkFunc) WriteRecord(record AuditRecord) error {
+	return f(record)
+}
+
+// WriterAuditSink writes records as JSON lines to a writer.
+type WriterAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// FileAuditSink appends records as JSON lines to a file, synced to disk on
+// every record.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// AuditLog chains order action records and writes them to its sinks. It is
+// safe for concurrent use.
+type AuditLog struct {
+	mu    sync.Mutex
+	sinks []AuditSink
+	seq   uint64
+	head  string
+}
+
+// AuditedOrders records every order action taken through it in an audit log.
+type AuditedOrders struct {
+	client  *Client
+	service OrderService
+	log     *AuditLog
+	caller  string
+}
+
+// NewWriterAuditSink creates a sink writing JSON lines to the writer.
+func NewWriterAuditSink(w io.Writer) *WriterAuditSink {
+	return &WriterAuditSink{w: w}
+}
+
+// WriteRecord writes the record as a line of JSON.
+func (ws *WriterAuditSink) WriteRecord(record AuditRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	_, err = ws.w.Write(append(b, '\n'))
+
+	return err
+}
+
+// OpenFileAuditSink opens the JSON lines file at the path for appending,
+// creating it if it doesn't exist.
+func OpenFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileAuditSink{file: file}, nil
+}
+
+// WriteRecord appends the record as a line of JSON.
+func (fs *FileAuditSink) WriteRecord(record AuditRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, err = fs.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+
+	return fs.file.Sync()
+}
+
+// Close closes the file.
+func (fs *FileAuditSink) Close() error {
+	return fs.file.Close()
+}
+
+// NewAuditLog creates an audit log writing to the sinks, starting a new chain.
+func NewAuditLog(sinks ...AuditSink) *AuditLog {
+	return &AuditLog{sinks: sinks}
+}
+
+// Resume continues the chain from the last record of an earlier log, i.e.
+// after a restart.
+func (al *AuditLog) Resume(last AuditRecord) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	al.seq = last.Seq
+	al.head = last.Hash
+}
+
+// Head returns the sequence number and hash of the last record. Keeping them
+// elsewhere detects records removed from the end of the log.
+func (al *AuditLog) Head() (uint64, string) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	return al.seq, al.head
+}
+
+// Record chains the record to the log and writes it to every sink, returning
+// the chained record. Sink failures wrap ErrAuditWrite; the record stays in
+// the chain so sinks that missed it show a gap.
+func (al *AuditLog) Record(record AuditRecord) (AuditRecord, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	record.Seq = al.seq + 1
+	record.PrevHash = al.head
+
+	hash, err := auditHash(record)
+	if err != nil {
+		return AuditRecord{}, fmt.Errorf("%w: %w", ErrAuditWrite, err)
+	}
+	record.Hash = hash
+
+	al.seq = record.Seq
+	al.head = record.Hash
+
+	var errs []error
+	for _, sink := range al.sinks {
+		if err = sink.WriteRecord(record); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", ErrAuditWrite, err))
+		}
+	}
+
+	return record, errors.Join(errs...)
+}
+
+// ReadAuditLog reads JSON lines of audit records.
+func ReadAuditLog(r io.Reader) ([]AuditRecord, error) {
+	var records []AuditRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("audit record %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// VerifyAuditLog checks the records form an unbroken chain from the first
+// record of a log, returning ErrAuditGap for missing records and
+// ErrAuditTampered for changed ones.
+func VerifyAuditLog(records []AuditRecord) error {
+	return VerifyAuditLogFrom(records, 0, "")
+}
+
+// VerifyAuditLogFrom checks the records form an unbroken chain following the
+// head of an earlier segment of the log, as returned by AuditLog.Head, i.e.
+// records written after a Resume.
+func VerifyAuditLogFrom(records []AuditRecord, seq uint64, head string) error {
+	prev := AuditRecord{Seq: seq, Hash: head}
+
+	for _, record := range records {
+		if record.Seq != prev.Seq+1 {
+			return fmt.Errorf("%w: record %d follows record %d", ErrAuditGap, record.Seq, prev.Seq)
+		}
+
+		hash, err := auditHash(record)
+		if err != nil {
+			return err
+		}
+		if hash != record.Hash {
+			return fmt.Errorf("%w: record %d doesn't match its hash", ErrAuditTampered, record.Seq)
+		}
+		if record.PrevHash != prev.Hash {
+			return fmt.Errorf("%w: record %d doesn't chain to record %d", ErrAuditTampered, record.Seq, prev.Seq)
+		}
+
+		prev = record
+	}
+
+	return nil
+}
+
+// NewAuditedOrders creates audited orders through the client, recording the
+// caller with every action.
+func (c *Client) NewAuditedOrders(log *AuditLog, caller string) *AuditedOrders {
+	return c.NewAuditedOrdersFor(c, log, caller)
+}
+
+// NewAuditedOrdersFor creates audited orders submitting through the order
+// service, i.e. a risk engine, and replacing, patching and cancelling
+// through the client.
+func (c *Client) NewAuditedOrdersFor(service OrderService, log *AuditLog, caller string) *AuditedOrders {
+	return &AuditedOrders{client: c, service: service, log: log, caller: caller}
+}
+
+// SubmitOrder submits and records the order. The result of the submission is
+// returned even when its record fails with ErrAuditWrite.
+func (ao *AuditedOrders) SubmitOrder(accountNumber string, order NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	return ao.submit(AuditSubmitOrder, ao.service.SubmitOrder, accountNumber, order)
+}
+
+// SubmitOrderDryRun dry runs and records the order.
+func (ao *AuditedOrders) SubmitOrderDryRun(accountNumber string, order NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	return ao.submit(AuditSubmitOrderDryRun, ao.service.SubmitOrderDryRun, accountNumber, order)
+}
+
+// ReplaceOrder replaces the order and records the replacement.
+func (ao *AuditedOrders) ReplaceOrder(accountNumber string, id int, orderECR NewOrderECR) (Order, *http.Response, error) {
+	return ao.edit(AuditReplaceOrder, ao.client.ReplaceOrder, accountNumber, id, orderECR)
+}
+
+// PatchOrder patches the order and records the patch.
+func (ao *AuditedOrders) PatchOrder(accountNumber string, id int, orderECR NewOrderECR) (Order, *http.Response, error) {
+	return ao.edit(AuditPatchOrder, ao.client.PatchOrder, accountNumber, id, orderECR)
+}
+
+// CancelOrder cancels the order and records the cancel.
+func (ao *AuditedOrders) CancelOrder(accountNumber string, id int) (Order, *http.Response, error) {
+	record := ao.newRecord(AuditCancelOrder, accountNumber, id, nil)
+
+	order, resp, err := ao.client.CancelOrder(accountNumber, id)
+
+	return order, resp, ao.record(record, order, nil, resp, err)
+}
+
+func (ao *AuditedOrders) submit(
+	action AuditAction,
+	fn func(string, NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error),
+	accountNumber string,
+	order NewOrder,
+) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	record := ao.newRecord(action, accountNumber, 0, order)
+
+	orderResp, orderErr, resp, err := fn(accountNumber, order)
+	record.OrderID = orderResp.Order.ID
+
+	return orderResp, orderErr, resp, ao.record(record, orderResp, orderErr, resp, err)
+}
+
+func (ao *AuditedOrders) edit(
+	action AuditAction,
+	fn func(string, int, NewOrderECR) (Order, *http.Response, error),
+	accountNumber string,
+	id int,
+	orderECR NewOrderECR,
+) (Order, *http.Response, error) {
+	record := ao.newRecord(action, accountNumber, id, orderECR)
+
+	order, resp, err := fn(accountNumber, id, orderECR)
+
+	return order, resp, ao.record(record, order, nil, resp, err)
+}
+
+func (ao *AuditedOrders) newRecord(action AuditAction, accountNumber string, id int, request any) AuditRecord {
+	record := AuditRecord{
+		Action:        action,
+		RequestedAt:   time.Now().UTC(),
+		Caller:        ao.caller,
+		Username:      ao.client.Session.User.Username,
+		AccountNumber: accountNumber,
+		OrderID:       id,
+	}
+
+	if request != nil {
+		record.Request, _ = json.Marshal(request)
+	}
+
+	return record
+}
+
+// record completes and writes the record, returning the action's error
+// joined with any audit error.
+func (ao *AuditedOrders) record(record AuditRecord, response any, orderErr *OrderErrorResponse, resp *http.Response, err error) error {
+	record.RespondedAt = time.Now().UTC()
+	record.OrderError = orderErr
+
+	if resp != nil {
+		record.StatusCode = resp.StatusCode
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Response, _ = json.Marshal(response)
+	}
+
+	_, auditErr := ao.log.Record(record)
+	if auditErr == nil {
+		return err
+	}
+	if err == nil {
+		return auditErr
+	}
+
+	return errors.Join(err, auditErr)
+}
+
+// auditHash hashes the record without its hash.
+func auditHash(record AuditRecord) (string, error) {
+	record.Hash = ""
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
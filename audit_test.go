@@ -0,0 +1,221 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func auditServer(t *testing.T) {
+	t.Helper()
+
+	mux.HandleFunc("/accounts/5YZ55555/orders", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"order":{"id":11,"status":"Received"}}}`)
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders/dry-run", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"order":{"status":"Received"}},"error":{"code":"preflight_check_failure","message":"insufficient buying power"}}`)
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders/11", func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodPut:
+			fmt.Fprint(writer, `{"data":{"id":12,"status":"Live","replaces-order-id":"11"}}`)
+		case http.MethodPatch:
+			fmt.Fprint(writer, `{"data":{"id":11,"status":"Live"}}`)
+		case http.MethodDelete:
+			fmt.Fprint(writer, `{"data":{"id":11,"status":"Cancel Requested"}}`)
+		}
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders/13", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+}
+
+func TestAuditedOrders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	auditServer(t)
+	client.Session.User.Username = "trader"
+
+	var buf bytes.Buffer
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	file, err := OpenFileAuditSink(path)
+	require.NoError(t, err)
+
+	log := NewAuditLog(NewWriterAuditSink(&buf), file)
+	orders := client.NewAuditedOrders(log, "hedger")
+
+	resp, _, _, err := orders.SubmitOrder("5YZ55555", buyAAPL(5, "2.10"))
+	require.NoError(t, err)
+	require.Equal(t, 11, resp.Order.ID)
+
+	_, orderErr, _, err := orders.SubmitOrderDryRun("5YZ55555", buyAAPL(5, "2.10"))
+	require.NoError(t, err)
+	require.Equal(t, "insufficient buying power", orderErr.Message)
+
+	ecr := NewOrderECR{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("2.15"), PriceEffect: Debit}
+	replaced, _, err := orders.ReplaceOrder("5YZ55555", 11, ecr)
+	require.NoError(t, err)
+	require.Equal(t, 12, replaced.ID)
+
+	_, _, err = orders.PatchOrder("5YZ55555", 11, ecr)
+	require.NoError(t, err)
+
+	_, _, err = orders.CancelOrder("5YZ55555", 11)
+	require.NoError(t, err)
+
+	_, _, err = orders.CancelOrder("5YZ55555", 13)
+	expectedUnauthorized(t, err)
+
+	require.NoError(t, file.Close())
+
+	records, err := ReadAuditLog(&buf)
+	require.NoError(t, err)
+	require.NoError(t, VerifyAuditLog(records))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	fileRecords, err := ReadAuditLog(bytes.NewReader(b))
+	require.NoError(t, err)
+	require.Equal(t, records, fileRecords)
+
+	actions := make([]AuditAction, 0, len(records))
+	for _, record := range records {
+		require.Equal(t, "hedger", record.Caller)
+		require.Equal(t, "trader", record.Username)
+		require.Equal(t, "5YZ55555", record.AccountNumber)
+		require.False(t, record.RespondedAt.Before(record.RequestedAt))
+		actions = append(actions, record.Action)
+	}
+	require.Equal(t, []AuditAction{
+		AuditSubmitOrder, AuditSubmitOrderDryRun, AuditReplaceOrder, AuditPatchOrder, AuditCancelOrder, AuditCancelOrder,
+	}, actions)
+
+	submit := records[0]
+	require.Equal(t, uint64(1), submit.Seq)
+	require.Empty(t, submit.PrevHash)
+	require.Equal(t, 11, submit.OrderID)
+	require.Equal(t, 200, submit.StatusCode)
+
+	var submitted NewOrder
+	require.NoError(t, json.Unmarshal(submit.Request, &submitted))
+	require.Equal(t, "AAPL", submitted.Legs[0].Symbol)
+
+	var submitResp OrderResponse
+	require.NoError(t, json.Unmarshal(submit.Response, &submitResp))
+	require.Equal(t, Received, submitResp.Order.Status)
+
+	require.Equal(t, "preflight_check_failure", records[1].OrderError.Code)
+	require.Contains(t, string(records[2].Request), `"price":2.15`)
+	require.Contains(t, string(records[2].Response), `"replaces-order-id":"11"`)
+
+	failed := records[5]
+	require.Equal(t, 13, failed.OrderID)
+	require.Equal(t, 401, failed.StatusCode)
+	require.Contains(t, failed.Error, "unauthorized")
+	require.Empty(t, failed.Response)
+
+	seq, head := log.Head()
+	require.Equal(t, uint64(6), seq)
+	require.Equal(t, failed.Hash, head)
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	log := NewAuditLog()
+
+	records := make([]AuditRecord, 0, 4)
+	for i := 1; i <= 4; i++ {
+		record, err := log.Record(AuditRecord{Action: AuditCancelOrder, OrderID: i})
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+	require.NoError(t, VerifyAuditLog(records))
+
+	gap := append(append([]AuditRecord{}, records[:1]...), records[2:]...)
+	require.ErrorIs(t, VerifyAuditLog(gap), ErrAuditGap)
+	require.EqualError(t, VerifyAuditLog(records[1:]), "audit log has a gap: record 2 follows record 0")
+
+	changed := append([]AuditRecord{}, records...)
+	changed[2].OrderID = 99
+	require.EqualError(t, VerifyAuditLog(changed), "audit log was tampered with: record 3 doesn't match its hash")
+
+	// rehashing a changed record breaks the link to the next one
+	changed[2].Hash, _ = auditHash(changed[2])
+	require.EqualError(t, VerifyAuditLog(changed), "audit log was tampered with: record 4 doesn't chain to record 3")
+
+	// a resumed log continues the chain
+	resumed := NewAuditLog()
+	resumed.Resume(records[3])
+	record, err := resumed.Record(AuditRecord{Action: AuditCancelOrder, OrderID: 5})
+	require.NoError(t, err)
+	require.NoError(t, VerifyAuditLog(append(records, record)))
+}
+
+func TestVerifyAuditLogFrom(t *testing.T) {
+	log := NewAuditLog()
+	for i := 1; i <= 3; i++ {
+		_, err := log.Record(AuditRecord{Action: AuditCancelOrder, OrderID: i})
+		require.NoError(t, err)
+	}
+	seq, head := log.Head()
+
+	// the segment written after a restart is verified on its own
+	resumed := NewAuditLog()
+	resumed.Resume(AuditRecord{Seq: seq, Hash: head})
+
+	segment := make([]AuditRecord, 0, 2)
+	for i := 4; i <= 5; i++ {
+		record, err := resumed.Record(AuditRecord{Action: AuditCancelOrder, OrderID: i})
+		require.NoError(t, err)
+		segment = append(segment, record)
+	}
+
+	require.NoError(t, VerifyAuditLogFrom(segment, seq, head))
+	require.ErrorIs(t, VerifyAuditLog(segment), ErrAuditGap)
+	require.EqualError(t, VerifyAuditLogFrom(segment[1:], seq, head), "audit log has a gap: record 5 follows record 3")
+	require.EqualError(t, VerifyAuditLogFrom(segment, seq, "other"),
+		"audit log was tampered with: record 4 doesn't chain to record 3")
+	require.NoError(t, VerifyAuditLogFrom(nil, seq, head))
+}
+
+func TestAuditLogSinkError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	auditServer(t)
+
+	var written []AuditRecord
+	failing := AuditSinkFunc(func(record AuditRecord) error {
+		if record.Seq == 1 {
+			return errors.New("disk full")
+		}
+		written = append(written, record)
+		return nil
+	})
+
+	var buf bytes.Buffer
+	orders := client.NewAuditedOrders(NewAuditLog(failing, NewWriterAuditSink(&buf)), "hedger")
+
+	resp, _, _, err := orders.SubmitOrder("5YZ55555", buyAAPL(5, "2.10"))
+	require.ErrorIs(t, err, ErrAuditWrite)
+	require.EqualError(t, err, "audit record not written: disk full")
+	require.Equal(t, 11, resp.Order.ID)
+
+	_, _, err = orders.CancelOrder("5YZ55555", 11)
+	require.NoError(t, err)
+
+	// the other sink has the whole log, the failed sink shows a gap
+	records, err := ReadAuditLog(&buf)
+	require.NoError(t, err)
+	require.NoError(t, VerifyAuditLog(records))
+	require.ErrorIs(t, VerifyAuditLog(written), ErrAuditGap)
+}
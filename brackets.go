@@ -0,0 +1,311 @@
+package tasty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+type bracketRuleKind int
+
+const (
+	percentOfFillRule bracketRuleKind = iota + 1
+	atPriceRule
+	underlyingTriggerRule
+)
+
+// BracketRule is the exit rule of a profit target or stop loss.
+// Create one with PercentOfFill, AtPrice or UnderlyingTrigger.
+type BracketRule struct {
+	kind       bracketRuleKind
+	value      decimal.Decimal
+	symbol     string
+	comparator Comparator
+}
+
+// Bracket is an entry order with a profit target and a stop loss submitted
+// together as an OTOCO complex order.
+type Bracket struct {
+	// Limit entry order, priced when percent of fill rules are used
+	Entry  NewOrder
+	Target BracketRule
+	Stop   BracketRule
+	// Time in force of the exit orders, defaults to GTC
+	TimeInForce TimeInForce
+	// Tick schedule percent of fill exits are rounded to, pennies when empty.
+	// GetOrderTicks returns the schedule of the entry.
+	Ticks Ticks
+}
+
+// PercentOfFill exits at a percent of the entry price i.e. a profit target at
+// 50% of the credit received is PercentOfFill(50) and a stop at 2x the
+// credit is PercentOfFill(200).
+func PercentOfFill(percent decimal.Decimal) BracketRule {
+	return BracketRule{kind: percentOfFillRule, value: percent}
+}
+
+// AtPrice exits at an absolute price.
+func AtPrice(price decimal.Decimal) BracketRule {
+	return BracketRule{kind: atPriceRule, value: price}
+}
+
+// UnderlyingTrigger exits with a market order routed once the last price of
+// the symbol compares to the threshold i.e. UnderlyingTrigger("SPY", LTE, 420).
+func UnderlyingTrigger(symbol string, comparator Comparator, threshold decimal.Decimal) BracketRule {
+	return BracketRule{
+		kind:       underlyingTriggerRule,
+		value:      threshold,
+		symbol:     symbol,
+		comparator: comparator,
+	}
+}
+
+// ComplexOrder returns the OTOCO order for the bracket using the entry price.
+func (b Bracket) ComplexOrder() (NewComplexOrder, error) {
+	return b.ComplexOrderAt(b.Entry.Price)
+}
+
+// ComplexOrderAt returns the OTOCO order for the bracket with exits priced
+// from the fill price of the entry.
+func (b Bracket) ComplexOrderAt(fill decimal.Decimal) (NewComplexOrder, error) {
+	if len(b.Entry.Legs) == 0 {
+		return NewComplexOrder{}, errors.New("bracket entry requires legs")
+	}
+
+	target, err := b.exit(b.Target, fill, false)
+	if err != nil {
+		return NewComplexOrder{}, fmt.Errorf("bracket target: %w", err)
+	}
+
+	stop, err := b.exit(b.Stop, fill, true)
+	if err != nil {
+		return NewComplexOrder{}, fmt.Errorf("bracket stop: %w", err)
+	}
+
+	entry := b.Entry
+
+	return NewComplexOrder{
+		Type:         OTOCO,
+		TriggerOrder: &entry,
+		Orders:       []NewOrder{target, stop},
+	}, nil
+}
+
+// SubmitBracket submits the entry, profit target and stop loss atomically as an OTOCO order.
+func (c *Client) SubmitBracket(accountNumber string, bracket Bracket) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	order, err := bracket.ComplexOrder()
+	if err != nil {
+		return OrderResponse{}, nil, nil, err
+	}
+
+	return c.SubmitComplexOrder(accountNumber, order)
+}
+
+// ManageBracket waits for the entry of a submitted bracket to fill and then
+// adjusts the exits to the fill price with AdjustBracket.
+func (c *Client) ManageBracket(ctx context.Context, accountNumber string, bracket Bracket, complexOrderID int) ([]Order, error) {
+	co, _, err := c.GetComplexOrder(accountNumber, complexOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = c.WaitForOrder(ctx, accountNumber, co.TriggerOrder.ID, Filled); err != nil {
+		return nil, err
+	}
+
+	co, _, err = c.GetComplexOrder(accountNumber, complexOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.AdjustBracket(accountNumber, bracket, co)
+}
+
+// AdjustBracket replaces the working exits of a filled bracket whose entry
+// filled at a different price than the entry order, repricing percent of
+// fill rules from the actual fill. Exits with a stop trigger are stops, a
+// stop limit keeping its limit's offset from the trigger, and other priced
+// exits are targets. The replaced orders are returned.
+func (c *Client) AdjustBracket(accountNumber string, bracket Bracket, complexOrder ComplexOrder) ([]Order, error) {
+	if complexOrder.TriggerOrder.Status != Filled {
+		return nil, fmt.Errorf("bracket entry %d is %s, not Filled", complexOrder.TriggerOrder.ID, complexOrder.TriggerOrder.Status)
+	}
+
+	fill, ok := complexOrder.TriggerOrder.FillPrice()
+	if !ok || fill.Equal(bracket.Entry.Price) {
+		return nil, nil
+	}
+
+	var replaced []Order
+	for _, child := range complexOrder.Orders {
+		if containsOrderStatus(terminalOrderStatuses, child.Status) {
+			continue
+		}
+
+		isStop := child.StopTrigger.IsPositive() || child.OrderType == Stop || child.OrderType == StopLimit
+		rule := bracket.Target
+		if isStop {
+			rule = bracket.Stop
+		} else if child.OrderType != Limit {
+			continue
+		}
+
+		if rule.kind != percentOfFillRule {
+			continue
+		}
+
+		exit, err := bracket.exit(rule, fill, isStop)
+		if err != nil {
+			return replaced, err
+		}
+
+		if child.OrderType == StopLimit {
+			exit.OrderType = StopLimit
+			exit.Price = exit.StopTrigger.Add(child.Price.Sub(child.StopTrigger))
+		}
+
+		order, _, err := c.ReplaceOrder(accountNumber, child.ID, NewOrderECR{
+			TimeInForce: exit.TimeInForce,
+			GtcDate:     exit.GtcDate,
+			OrderType:   exit.OrderType,
+			Price:       exit.Price,
+			StopTrigger: exit.StopTrigger,
+			PriceEffect: exit.PriceEffect,
+		})
+		if err != nil {
+			return replaced, err
+		}
+
+		replaced = append(replaced, order)
+	}
+
+	return replaced, nil
+}
+
+// FillPrice returns the net price per unit the order filled at, the greatest
+// common divisor of the leg quantities being one unit. False is returned when
+// the order has no fills.
+func (o Order) FillPrice() (decimal.Decimal, bool) {
+	net := decimal.Zero
+	units := 0
+	filled := false
+
+	for _, leg := range o.Legs {
+		units = gcd(units, int(leg.Quantity.IntPart()))
+
+		for _, fill := range leg.Fills {
+			value := fill.FillPrice.Mul(fill.Quantity)
+			if isSellAction(leg.Action) {
+				net = net.Add(value)
+			} else {
+				net = net.Sub(value)
+			}
+			filled = true
+		}
+	}
+
+	if !filled || units == 0 {
+		return decimal.Zero, false
+	}
+
+	return net.Abs().Div(decimal.NewFromInt(int64(units))), true
+}
+
+// exit builds the closing order for the rule with the entry's legs reversed.
+func (b Bracket) exit(rule BracketRule, fill decimal.Decimal, isStop bool) (NewOrder, error) {
+	tif := b.TimeInForce
+	if tif == "" {
+		tif = GTC
+	}
+
+	order := NewOrder{
+		TimeInForce: tif,
+		PriceEffect: closingEffect(b.Entry.PriceEffect),
+		Legs:        closingLegs(b.Entry.Legs),
+	}
+
+	var price decimal.Decimal
+	switch rule.kind {
+	case percentOfFillRule:
+		if !fill.IsPositive() {
+			return NewOrder{}, errors.New("percent of fill rules require a priced entry")
+		}
+		ticks := b.Ticks
+		if len(ticks.Schedule) == 0 {
+			ticks = pennyTicks()
+		}
+
+		var err error
+		price, err = ticks.Round(fill.Mul(rule.value).Div(decimal.NewFromInt(100)), RoundNearest)
+		if err != nil {
+			return NewOrder{}, err
+		}
+	case atPriceRule:
+		price = rule.value
+	case underlyingTriggerRule:
+		rules, err := NewRuleBuilder().RouteWhen(rule.symbol, rule.comparator, rule.value).Build()
+		if err != nil {
+			return NewOrder{}, err
+		}
+		order.OrderType = Market
+		order.PriceEffect = ""
+		order.Rules = rules
+		return order, nil
+	default:
+		return NewOrder{}, errors.New("missing rule")
+	}
+
+	if isStop {
+		order.OrderType = Stop
+		order.StopTrigger = price
+	} else {
+		order.OrderType = Limit
+		order.Price = price
+	}
+
+	return order, nil
+}
+
+// closingLegs reverses the action of every leg.
+func closingLegs(legs []NewOrderLeg) []NewOrderLeg {
+	closing := make([]NewOrderLeg, 0, len(legs))
+	for _, leg := range legs {
+		leg.Action = closingAction(leg.Action)
+		closing = append(closing, leg)
+	}
+
+	return closing
+}
+
+func closingAction(action OrderAction) OrderAction {
+	switch action {
+	case BTO:
+		return STC
+	case STO:
+		return BTC
+	case BTC:
+		return STO
+	case STC:
+		return BTO
+	case Buy:
+		return Sell
+	case Sell:
+		return Buy
+	default:
+		return action
+	}
+}
+
+func closingEffect(effect PriceEffect) PriceEffect {
+	switch effect {
+	case Credit:
+		return Debit
+	case Debit:
+		return Credit
+	default:
+		return effect
+	}
+}
@@ -0,0 +1,289 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func testCreditSpreadBracket() Bracket {
+	return Bracket{
+		Entry: NewOrder{
+			TimeInForce: Day,
+			OrderType:   Limit,
+			Price:       decimal.NewFromInt(1),
+			PriceEffect: Credit,
+			Legs: []NewOrderLeg{
+				{InstrumentType: EquityOptionIT, Symbol: "SPY   230929P00420000", Quantity: decimal.NewFromInt(2), Action: BTO},
+				{InstrumentType: EquityOptionIT, Symbol: "SPY   230929P00430000", Quantity: decimal.NewFromInt(2), Action: STO},
+			},
+		},
+		Target: PercentOfFill(decimal.NewFromInt(50)),
+		Stop:   PercentOfFill(decimal.NewFromInt(200)),
+	}
+}
+
+func TestBracketComplexOrder(t *testing.T) {
+	co, err := testCreditSpreadBracket().ComplexOrder()
+	require.NoError(t, err)
+	require.NoError(t, co.Validate())
+	require.Equal(t, OTOCO, co.Type)
+	require.Equal(t, "1", co.TriggerOrder.Price.String())
+
+	target := co.Orders[0]
+	require.Equal(t, GTC, target.TimeInForce)
+	require.Equal(t, Limit, target.OrderType)
+	require.Equal(t, "0.5", target.Price.String())
+	require.Equal(t, Debit, target.PriceEffect)
+	require.Equal(t, []NewOrderLeg{
+		{InstrumentType: EquityOptionIT, Symbol: "SPY   230929P00420000", Quantity: decimal.NewFromInt(2), Action: STC},
+		{InstrumentType: EquityOptionIT, Symbol: "SPY   230929P00430000", Quantity: decimal.NewFromInt(2), Action: BTC},
+	}, target.Legs)
+
+	stop := co.Orders[1]
+	require.Equal(t, Stop, stop.OrderType)
+	require.Equal(t, "2", stop.StopTrigger.String())
+	require.Equal(t, Debit, stop.PriceEffect)
+	require.Equal(t, target.Legs, stop.Legs)
+}
+
+func TestBracketTicks(t *testing.T) {
+	bracket := testCreditSpreadBracket()
+	bracket.Ticks = Ticks{Schedule: []TickSize{
+		{Value: decimal.RequireFromString("0.01"), Threshold: decimal.RequireFromString("3")},
+		{Value: decimal.RequireFromString("0.05")},
+	}}
+
+	co, err := bracket.ComplexOrderAt(decimal.RequireFromString("1.63"))
+	require.NoError(t, err)
+	require.Equal(t, "0.82", co.Orders[0].Price.String())
+	// 3.26 is above the threshold and trades in nickels
+	require.Equal(t, "3.25", co.Orders[1].StopTrigger.String())
+	require.NoError(t, bracket.Ticks.ValidateOrder(co.Orders[1]))
+
+	// pennies by default
+	co, err = testCreditSpreadBracket().ComplexOrderAt(decimal.RequireFromString("1.63"))
+	require.NoError(t, err)
+	require.Equal(t, "3.26", co.Orders[1].StopTrigger.String())
+}
+
+func TestBracketRules(t *testing.T) {
+	bracket := testCreditSpreadBracket()
+	bracket.Target = AtPrice(decimal.RequireFromString("0.25"))
+	bracket.Stop = UnderlyingTrigger("SPY", LTE, decimal.NewFromInt(425))
+	bracket.TimeInForce = Day
+
+	co, err := bracket.ComplexOrder()
+	require.NoError(t, err)
+	require.Equal(t, "0.25", co.Orders[0].Price.String())
+	require.Equal(t, Day, co.Orders[0].TimeInForce)
+
+	stop := co.Orders[1]
+	require.Equal(t, Market, stop.OrderType)
+	require.Empty(t, stop.PriceEffect)
+	require.Equal(t, []NewOrderCondition{{
+		Action:         Route,
+		Symbol:         "SPY",
+		InstrumentType: EquityIT,
+		Indicator:      Last,
+		Comparator:     LTE,
+		Threshold:      decimal.NewFromInt(425),
+		PriceComponents: []NewOrderPriceComponent{
+			{Symbol: "SPY", InstrumentType: EquityIT, Quantity: decimal.NewFromInt(1), QuantityDirection: Long},
+		},
+	}}, stop.Rules.Conditions)
+
+	bracket.Stop = UnderlyingTrigger("SPY", "gt", decimal.NewFromInt(425))
+	_, err = bracket.ComplexOrder()
+	require.ErrorIs(t, err, ErrInvalidRule)
+
+	bracket = testCreditSpreadBracket()
+	bracket.Entry.OrderType = Market
+	bracket.Entry.Price = decimal.NewFromInt(0)
+	_, err = bracket.ComplexOrder()
+	require.EqualError(t, err, "bracket target: percent of fill rules require a priced entry")
+
+	bracket = testCreditSpreadBracket()
+	bracket.Stop = BracketRule{}
+	_, err = bracket.ComplexOrder()
+	require.EqualError(t, err, "bracket stop: missing rule")
+
+	_, err = Bracket{}.ComplexOrder()
+	require.EqualError(t, err, "bracket entry requires legs")
+}
+
+func TestOrderFillPrice(t *testing.T) {
+	order := Order{Legs: []OrderLeg{
+		{Quantity: decimal.NewFromInt(2), Action: BTO, Fills: []OrderFill{{Quantity: decimal.NewFromInt(2), FillPrice: decimal.RequireFromString("0.80")}}},
+		{Quantity: decimal.NewFromInt(2), Action: STO, Fills: []OrderFill{
+			{Quantity: decimal.NewFromInt(1), FillPrice: decimal.RequireFromString("2.00")},
+			{Quantity: decimal.NewFromInt(1), FillPrice: decimal.RequireFromString("2.00")},
+		}},
+	}}
+
+	price, ok := order.FillPrice()
+	require.True(t, ok)
+	require.Equal(t, "1.2", price.String())
+
+	_, ok = Order{Legs: []OrderLeg{{Quantity: decimal.NewFromInt(1), Action: Buy}}}.FillPrice()
+	require.False(t, ok)
+}
+
+func TestSubmitBracket(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/complex-orders", func(writer http.ResponseWriter, request *http.Request) {
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+
+		var sent NewComplexOrder
+		require.NoError(t, json.Unmarshal(body, &sent))
+		require.Equal(t, OTOCO, sent.Type)
+		require.Equal(t, "0.5", sent.Orders[0].Price.String())
+
+		fmt.Fprint(writer, complexOrderResp)
+	})
+
+	resp, orderErr, _, err := client.SubmitBracket("5YZ55555", testCreditSpreadBracket())
+	require.NoError(t, err)
+	require.Nil(t, orderErr)
+	require.Equal(t, 3456, resp.ComplexOrder.ID)
+
+	_, _, httpResp, err := client.SubmitBracket("5YZ55555", Bracket{})
+	require.Error(t, err)
+	require.Nil(t, httpResp)
+}
+
+const filledBracketResp = `{"data":{"id":3456,"type":"OTOCO",
+	"trigger-order":{"id":100,"status":"Filled","legs":[
+		{"symbol":"SPY   230929P00420000","quantity":2,"action":"Buy to Open","fills":[{"quantity":2,"fill-price":"0.80"}]},
+		{"symbol":"SPY   230929P00430000","quantity":2,"action":"Sell to Open","fills":[{"quantity":2,"fill-price":"2.00"}]}]},
+	"orders":[
+		{"id":101,"order-type":"Limit","status":"Live"},
+		{"id":102,"order-type":"Stop","status":"Live"}]}}`
+
+func TestManageBracket(t *testing.T) {
+	setup()
+	defer teardown()
+	fastOrderPolling(t)
+
+	mux.HandleFunc("/accounts/5YZ55555/complex-orders/3456", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, filledBracketResp)
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders/100", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, orderStatusResp(100, Filled))
+	})
+
+	var mu sync.Mutex
+	replaced := map[string]NewOrderECR{}
+
+	replace := func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, http.MethodPut, request.Method)
+
+		var ecr NewOrderECR
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&ecr))
+
+		mu.Lock()
+		replaced[request.URL.Path] = ecr
+		mu.Unlock()
+
+		fmt.Fprint(writer, `{"data":{"id":200,"status":"Live"}}`)
+	}
+	mux.HandleFunc("/accounts/5YZ55555/orders/101", replace)
+	mux.HandleFunc("/accounts/5YZ55555/orders/102", replace)
+
+	orders, err := client.ManageBracket(context.Background(), "5YZ55555", testCreditSpreadBracket(), 3456)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+
+	// filled at 1.20 instead of 1.00
+	target := replaced["/accounts/5YZ55555/orders/101"]
+	require.Equal(t, Limit, target.OrderType)
+	require.Equal(t, "0.6", target.Price.String())
+	require.Equal(t, Debit, target.PriceEffect)
+
+	stop := replaced["/accounts/5YZ55555/orders/102"]
+	require.Equal(t, Stop, stop.OrderType)
+	require.Equal(t, "2.4", stop.StopTrigger.String())
+}
+
+func TestAdjustBracketUnchanged(t *testing.T) {
+	bracket := testCreditSpreadBracket()
+	bracket.Entry.Price = decimal.RequireFromString("1.2")
+
+	var resp struct {
+		ComplexOrder ComplexOrder `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(filledBracketResp), &resp))
+	co := resp.ComplexOrder
+
+	orders, err := client.AdjustBracket("5YZ55555", bracket, co)
+	require.NoError(t, err)
+	require.Empty(t, orders)
+
+	// absolute rules are left alone
+	bracket = testCreditSpreadBracket()
+	bracket.Target = AtPrice(decimal.RequireFromString("0.5"))
+	bracket.Stop = UnderlyingTrigger("SPY", LTE, decimal.NewFromInt(425))
+
+	orders, err = client.AdjustBracket("5YZ55555", bracket, co)
+	require.NoError(t, err)
+	require.Empty(t, orders)
+
+	co.TriggerOrder.Status = Live
+	_, err = client.AdjustBracket("5YZ55555", bracket, co)
+	require.EqualError(t, err, "bracket entry 100 is Live, not Filled")
+}
+
+func TestAdjustBracketStopLimit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var resp struct {
+		ComplexOrder ComplexOrder `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(filledBracketResp), &resp))
+	co := resp.ComplexOrder
+	co.Orders[1].OrderType = StopLimit
+	co.Orders[1].StopTrigger = decimal.NewFromInt(2)
+	co.Orders[1].Price = decimal.RequireFromString("2.1")
+
+	var sent NewOrderECR
+	mux.HandleFunc("/accounts/5YZ55555/orders/101", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"id":201,"status":"Live"}}`)
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders/102", func(writer http.ResponseWriter, request *http.Request) {
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&sent))
+		fmt.Fprint(writer, `{"data":{"id":202,"status":"Live"}}`)
+	})
+
+	orders, err := client.AdjustBracket("5YZ55555", testCreditSpreadBracket(), co)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+
+	// the stop limit is repriced from the fill, keeping its limit 0.10 through the trigger
+	require.Equal(t, StopLimit, sent.OrderType)
+	require.Equal(t, "2.4", sent.StopTrigger.String())
+	require.Equal(t, "2.5", sent.Price.String())
+}
+
+func TestManageBracketError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/complex-orders/3456", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.ManageBracket(context.Background(), "5YZ55555", testCreditSpreadBracket(), 3456)
+	expectedUnauthorized(t, err)
+}
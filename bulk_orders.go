@@ -0,0 +1,270 @@
+package tasty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOrdersStillLive is returned when flattening an underlying whose working
+// orders couldn't all be cancelled.
+var ErrOrdersStillLive = errors.New("orders still live")
+
+const (
+	defaultCancelGracePeriod = 2 * time.Second
+	defaultBulkConcurrency   = 8
+)
+
+// BulkCancelConfig configures bulk cancels.
+type BulkCancelConfig struct {
+	// How long to wait after cancelling before checking for orders still live.
+	// Defaults to 2 seconds; negative skips the check.
+	GracePeriod time.Duration
+	// Maximum number of requests in flight. Defaults to 8.
+	Concurrency int
+}
+
+// CancelResult is the outcome of cancelling one order.
+type CancelResult struct {
+	// The order returned by the cancel, the live order when it was skipped
+	// or the cancel failed
+	Order Order
+	// Skipped is true when the order wasn't cancellable
+	Skipped bool
+	Err     error
+}
+
+// BulkCancelResult is the outcome of cancelling many orders.
+type BulkCancelResult struct {
+	// Results of the working orders in the order they were listed
+	Results []CancelResult
+	// Orders still working after the grace period, skipped orders included
+	StillLive []Order
+}
+
+// Err returns the errors of the failed cancels joined.
+func (r BulkCancelResult) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("order %d: %w", result.Order.ID, result.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// FlattenResult is the outcome of one order closing positions of an underlying.
+type FlattenResult struct {
+	Positions []AccountPosition
+	// The market order sent to close the positions, one leg per position
+	Order         NewOrder
+	OrderResponse OrderResponse
+	OrderError    *OrderErrorResponse
+	Err           error
+}
+
+// FlattenUnderlyingResult is the outcome of flattening an underlying.
+type FlattenUnderlyingResult struct {
+	// Cancels of the underlying's working orders made before closing
+	Cancels BulkCancelResult
+	// Closes of the positions, one per order as split by ClosingOrders, empty
+	// when the underlying has no positions or the cancels failed
+	Closes []FlattenResult
+}
+
+// Err returns the errors of the failed cancels and closes joined.
+func (r FlattenUnderlyingResult) Err() error {
+	errs := []error{r.Cancels.Err()}
+	for i, closing := range r.Closes {
+		if closing.Err != nil {
+			errs = append(errs, fmt.Errorf("close %d: %w", i+1, closing.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CancelAllOrders cancels every working order of the account.
+func (c *Client) CancelAllOrders(ctx context.Context, accountNumber string, config BulkCancelConfig) (BulkCancelResult, error) {
+	return c.cancelLiveOrders(ctx, accountNumber, config, func(Order) bool { return true })
+}
+
+// CancelUnderlyingOrders cancels every working order of the account for the
+// underlying symbol.
+func (c *Client) CancelUnderlyingOrders(ctx context.Context, accountNumber, underlyingSymbol string, config BulkCancelConfig) (BulkCancelResult, error) {
+	return c.cancelLiveOrders(ctx, accountNumber, config, func(o Order) bool {
+		return o.UnderlyingSymbol == underlyingSymbol
+	})
+}
+
+// CancelOrders concurrently cancels the working orders, skipping orders that
+// aren't cancellable, and then reports the orders still working after the
+// grace period. The error is only set when the live orders can't be checked
+// or the context is done, failed cancels are reported in their results and
+// cancels not sent before the context is done fail with its error.
+func (c *Client) CancelOrders(ctx context.Context, accountNumber string, orders []Order, config BulkCancelConfig) (BulkCancelResult, error) {
+	if config.GracePeriod == 0 {
+		config.GracePeriod = defaultCancelGracePeriod
+	}
+
+	var result BulkCancelResult
+	for _, order := range orders {
+		if order.Status.IsWorking() {
+			result.Results = append(result.Results, CancelResult{Order: order, Skipped: !order.Cancellable})
+		}
+	}
+
+	if len(result.Results) == 0 {
+		return result, nil
+	}
+
+	runBulk(len(result.Results), config.Concurrency, func(i int) {
+		r := &result.Results[i]
+		if r.Skipped {
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			r.Err = err
+			return
+		}
+		if cancelled, _, err := c.CancelOrder(accountNumber, r.Order.ID); err != nil {
+			r.Err = err
+		} else {
+			r.Order = cancelled
+		}
+	})
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	if config.GracePeriod < 0 {
+		return result, nil
+	}
+
+	timer := time.NewTimer(config.GracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return result, ctx.Err()
+	case <-timer.C:
+	}
+
+	live, _, err := c.GetAccountLiveOrders(accountNumber)
+	if err != nil {
+		return result, err
+	}
+
+	targeted := map[int]bool{}
+	for _, r := range result.Results {
+		targeted[r.Order.ID] = true
+	}
+
+	for _, order := range live {
+		if targeted[order.ID] && order.Status.IsWorking() {
+			result.StillLive = append(result.StillLive, order)
+		}
+	}
+
+	return result, nil
+}
+
+// FlattenUnderlying cancels the working orders of the underlying symbol and
+// then closes its positions with market orders split as by ClosingOrders,
+// each validated before it is submitted. Nothing is closed when a cancel
+// fails or, unless the grace period is negative, orders are still working
+// after the grace period, the error wrapping ErrOrdersStillLive.
+func (c *Client) FlattenUnderlying(ctx context.Context, accountNumber, underlyingSymbol string, config BulkCancelConfig) (FlattenUnderlyingResult, error) {
+	var result FlattenUnderlyingResult
+
+	cancels, err := c.CancelUnderlyingOrders(ctx, accountNumber, underlyingSymbol, config)
+	result.Cancels = cancels
+	if err != nil {
+		return result, err
+	}
+	if err := cancels.Err(); err != nil {
+		return result, fmt.Errorf("%w: cancelling %s orders: %w", ErrOrdersStillLive, underlyingSymbol, err)
+	}
+	if len(cancels.StillLive) > 0 {
+		return result, fmt.Errorf("%w: %d %s orders", ErrOrdersStillLive, len(cancels.StillLive), underlyingSymbol)
+	}
+
+	positions, _, err := c.GetAccountPositions(accountNumber, AccountPositionQuery{UnderlyingSymbol: []string{underlyingSymbol}})
+	if err != nil {
+		return result, err
+	}
+
+	var open []AccountPosition
+	for _, position := range positions {
+		if position.Quantity != 0 && position.UnderlyingSymbol == underlyingSymbol {
+			open = append(open, position)
+		}
+	}
+
+	for _, group := range closingGroups(open) {
+		result.Closes = append(result.Closes, FlattenResult{Positions: group})
+	}
+
+	for i := range result.Closes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		r := &result.Closes[i]
+		if r.Order, r.Err = ClosingOrder(r.Positions...); r.Err != nil {
+			continue
+		}
+		if r.Err = ValidateOrder(r.Order); r.Err != nil {
+			continue
+		}
+		r.OrderResponse, r.OrderError, _, r.Err = c.SubmitOrder(accountNumber, r.Order)
+		if r.Err == nil && r.OrderError != nil {
+			r.Err = fmt.Errorf("order rejected: %s", r.OrderError.Message)
+		}
+	}
+
+	return result, nil
+}
+
+// cancelLiveOrders cancels the account's live orders matching the filter.
+func (c *Client) cancelLiveOrders(ctx context.Context, accountNumber string, config BulkCancelConfig, match func(Order) bool) (BulkCancelResult, error) {
+	live, _, err := c.GetAccountLiveOrders(accountNumber)
+	if err != nil {
+		return BulkCancelResult{}, err
+	}
+
+	var orders []Order
+	for _, order := range live {
+		if match(order) {
+			orders = append(orders, order)
+		}
+	}
+
+	return c.CancelOrders(ctx, accountNumber, orders, config)
+}
+
+// runBulk calls fn for each index with at most concurrency calls in flight.
+func runBulk(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,287 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+const bulkLiveOrdersResp = `{
+  "data": {
+    "items": [
+      {"id": 1, "account-number": "5YZ55555", "underlying-symbol": "AAPL", "status": "Live", "cancellable": true},
+      {"id": 2, "account-number": "5YZ55555", "underlying-symbol": "AAPL", "status": "Routed", "cancellable": true},
+      {"id": 3, "account-number": "5YZ55555", "underlying-symbol": "AAPL", "status": "Cancel Requested", "cancellable": false},
+      {"id": 4, "account-number": "5YZ55555", "underlying-symbol": "SPY", "status": "Live", "cancellable": true},
+      {"id": 5, "account-number": "5YZ55555", "underlying-symbol": "AAPL", "status": "Filled", "cancellable": false}
+    ]
+  }
+}`
+
+// handleBulkOrders serves the live orders and records the cancelled ids,
+// failing cancels of the failing id.
+func handleBulkOrders(t *testing.T, failing int) func() []int {
+	t.Helper()
+
+	var mu sync.Mutex
+	var cancelled []int
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, bulkLiveOrdersResp)
+	})
+
+	for id := 1; id <= 5; id++ {
+		id := id
+		mux.HandleFunc(fmt.Sprintf("/accounts/5YZ55555/orders/%d", id), func(writer http.ResponseWriter, request *http.Request) {
+			require.Equal(t, http.MethodDelete, request.Method)
+
+			if id == failing {
+				writer.WriteHeader(401)
+				fmt.Fprint(writer, tastyUnauthorizedError)
+				return
+			}
+
+			mu.Lock()
+			cancelled = append(cancelled, id)
+			mu.Unlock()
+
+			fmt.Fprint(writer, orderStatusResp(id, Cancelled))
+		})
+	}
+
+	return func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		sort.Ints(cancelled)
+		return cancelled
+	}
+}
+
+func TestCancelAllOrders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cancelled := handleBulkOrders(t, 4)
+
+	result, err := client.CancelAllOrders(context.Background(), "5YZ55555", BulkCancelConfig{GracePeriod: time.Millisecond, Concurrency: 2})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, cancelled())
+
+	// filled orders aren't working
+	require.Len(t, result.Results, 4)
+	require.Equal(t, Cancelled, result.Results[0].Order.Status)
+	require.True(t, result.Results[2].Skipped)
+	require.Equal(t, 3, result.Results[2].Order.ID)
+	expectedUnauthorized(t, result.Results[3].Err)
+
+	err = result.Err()
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "order 4: "))
+
+	// the live orders are served unchanged so every working order is still live
+	require.Len(t, result.StillLive, 4)
+}
+
+func TestCancelUnderlyingOrders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cancelled := handleBulkOrders(t, 0)
+
+	result, err := client.CancelUnderlyingOrders(context.Background(), "5YZ55555", "SPY", BulkCancelConfig{GracePeriod: -1})
+	require.NoError(t, err)
+	require.Equal(t, []int{4}, cancelled())
+	require.Len(t, result.Results, 1)
+	require.NoError(t, result.Err())
+	require.Empty(t, result.StillLive)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// cancels aren't sent once the context is done
+	result, err = client.CancelOrders(ctx, "5YZ55555", []Order{{ID: 1, Status: Live, Cancellable: true}}, BulkCancelConfig{GracePeriod: time.Hour})
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, result.Results[0].Err, context.Canceled)
+	require.Equal(t, []int{4}, cancelled())
+
+	result, err = client.CancelOrders(ctx, "5YZ55555", nil, BulkCancelConfig{})
+	require.NoError(t, err)
+	require.Empty(t, result.Results)
+}
+
+func TestCancelAllOrdersError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.CancelAllOrders(context.Background(), "5YZ55555", BulkCancelConfig{})
+	expectedUnauthorized(t, err)
+}
+
+func TestFlattenUnderlying(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cancelled := handleBulkOrders(t, 0)
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, "AAPL", request.URL.Query().Get("underlying-symbol[]"))
+		fmt.Fprint(writer, `{"data":{"items":[
+			{"symbol": "AAPL", "instrument-type": "Equity", "underlying-symbol": "AAPL", "quantity": 100, "quantity-direction": "Long"},
+			{"symbol": "AAPL  230818C00185000", "instrument-type": "Equity Option", "underlying-symbol": "AAPL", "quantity": 2, "quantity-direction": "Short"},
+			{"symbol": "AAPL  230818P00170000", "instrument-type": "Equity Option", "underlying-symbol": "AAPL", "quantity": 0, "quantity-direction": "Zero"}
+		]}}`)
+	})
+
+	submitted := handleClosingOrders(t)
+
+	result, err := client.FlattenUnderlying(context.Background(), "5YZ55555", "AAPL", BulkCancelConfig{GracePeriod: -1})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, cancelled())
+	require.Len(t, result.Cancels.Results, 3)
+
+	// the equity and the option are closed by separate orders
+	orders := submitted()
+	require.Len(t, orders, 2)
+	require.Len(t, result.Closes, 2)
+	for _, order := range orders {
+		require.Equal(t, Market, order.OrderType)
+		require.Equal(t, Day, order.TimeInForce)
+		require.Len(t, order.Legs, 1)
+	}
+	require.Equal(t, "AAPL", orders[0].Legs[0].Symbol)
+	require.Equal(t, Sell, orders[0].Legs[0].Action)
+	require.Equal(t, "100", orders[0].Legs[0].Quantity.String())
+	require.Equal(t, "AAPL  230818C00185000", orders[1].Legs[0].Symbol)
+	require.Equal(t, BTC, orders[1].Legs[0].Action)
+	require.Equal(t, "2", orders[1].Legs[0].Quantity.String())
+
+	for i, closing := range result.Closes {
+		require.Len(t, closing.Positions, 1)
+		require.NoError(t, closing.Err)
+		require.Equal(t, 10+i, closing.OrderResponse.Order.ID)
+	}
+	require.NoError(t, result.Err())
+}
+
+// handleClosingOrders accepts the submitted orders, numbering them from 10,
+// and returns them in the order they were submitted.
+func handleClosingOrders(t *testing.T) func() []NewOrder {
+	t.Helper()
+
+	var mu sync.Mutex
+	var submitted []NewOrder
+
+	mux.HandleFunc("/accounts/5YZ55555/orders", func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, http.MethodPost, request.Method)
+
+		var order NewOrder
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&order))
+
+		mu.Lock()
+		submitted = append(submitted, order)
+		id := 9 + len(submitted)
+		mu.Unlock()
+
+		fmt.Fprintf(writer, `{"data":{"order":{"id":%d,"status":"Routed"}}}`, id)
+	})
+
+	return func() []NewOrder {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]NewOrder(nil), submitted...)
+	}
+}
+
+func TestFlattenUnderlyingManyPositions(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handleBulkOrders(t, 0)
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"items":[
+			{"symbol": "AAPL  230818C00185000", "instrument-type": "Equity Option", "underlying-symbol": "AAPL", "quantity": 1, "quantity-direction": "Short"},
+			{"symbol": "AAPL", "instrument-type": "Equity", "underlying-symbol": "AAPL", "quantity": 100, "quantity-direction": "Long"},
+			{"symbol": "AAPL  230818C00190000", "instrument-type": "Equity Option", "underlying-symbol": "AAPL", "quantity": 1, "quantity-direction": "Long"},
+			{"symbol": "AAPL  230818P00170000", "instrument-type": "Equity Option", "underlying-symbol": "AAPL", "quantity": 1, "quantity-direction": "Short"},
+			{"symbol": "AAPL  230818P00165000", "instrument-type": "Equity Option", "underlying-symbol": "AAPL", "quantity": 1, "quantity-direction": "Long"},
+			{"symbol": "AAPL  230915C00185000", "instrument-type": "Equity Option", "underlying-symbol": "AAPL", "quantity": 2, "quantity-direction": "Short"},
+			{"symbol": "", "instrument-type": "Equity Option", "underlying-symbol": "AAPL", "quantity": 1, "quantity-direction": "Long"}
+		]}}`)
+	})
+
+	submitted := handleClosingOrders(t)
+
+	result, err := client.FlattenUnderlying(context.Background(), "5YZ55555", "AAPL", BulkCancelConfig{GracePeriod: -1})
+	require.NoError(t, err)
+
+	// the types close in the order they are first seen, the options in orders
+	// of at most 4 legs, and the order failing validation isn't submitted
+	require.Len(t, result.Closes, 3)
+	require.Len(t, result.Closes[0].Order.Legs, 4)
+	require.Equal(t, "AAPL  230818C00185000", result.Closes[0].Order.Legs[0].Symbol)
+	require.Equal(t, "AAPL  230915C00185000", result.Closes[1].Order.Legs[0].Symbol)
+	require.Len(t, result.Closes[1].Order.Legs, 2)
+	require.ErrorIs(t, result.Closes[1].Err, ErrInvalidOrder)
+	require.Zero(t, result.Closes[1].OrderResponse.Order.ID)
+	require.Equal(t, []NewOrderLeg{
+		{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: decimal.NewFromInt(100), Action: Sell},
+	}, result.Closes[2].Order.Legs)
+
+	orders := submitted()
+	require.Len(t, orders, 2)
+	require.Len(t, orders[0].Legs, 4)
+	require.Equal(t, "AAPL", orders[1].Legs[0].Symbol)
+	require.Equal(t, 11, result.Closes[2].OrderResponse.Order.ID)
+
+	require.ErrorContains(t, result.Err(), "close 2: invalid order: leg symbol is required")
+}
+
+func TestFlattenUnderlyingStillLive(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handleBulkOrders(t, 0)
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		require.FailNow(t, "positions closed with orders still live")
+	})
+
+	// the live orders are served unchanged so the cancelled orders are still live
+	result, err := client.FlattenUnderlying(context.Background(), "5YZ55555", "AAPL", BulkCancelConfig{GracePeriod: time.Millisecond})
+	require.ErrorIs(t, err, ErrOrdersStillLive)
+	require.EqualError(t, err, "orders still live: 3 AAPL orders")
+	require.Len(t, result.Cancels.StillLive, 3)
+	require.Empty(t, result.Closes)
+}
+
+func TestFlattenUnderlyingCancelError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handleBulkOrders(t, 2)
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		require.FailNow(t, "positions closed after a failed cancel")
+	})
+
+	_, err := client.FlattenUnderlying(context.Background(), "5YZ55555", "AAPL", BulkCancelConfig{GracePeriod: -1})
+	require.ErrorIs(t, err, ErrOrdersStillLive)
+	require.Contains(t, err.Error(), "order 2: ")
+}
@@ -0,0 +1,130 @@
+package tasty
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Validate checks the complex order has the trigger order and number of
+// orders required by its type.
+func (co NewComplexOrder) Validate() error {
+	switch co.Type {
+	case OTO:
+		if co.TriggerOrder == nil {
+			return errors.New("OTO complex orders require a trigger order")
+		}
+		if len(co.Orders) != 1 {
+			return fmt.Errorf("OTO complex orders require 1 order, got %d", len(co.Orders))
+		}
+	case OCO:
+		if co.TriggerOrder != nil {
+			return errors.New("OCO complex orders can't have a trigger order")
+		}
+		if len(co.Orders) != 2 {
+			return fmt.Errorf("OCO complex orders require 2 orders, got %d", len(co.Orders))
+		}
+	case OTOCO:
+		if co.TriggerOrder == nil {
+			return errors.New("OTOCO complex orders require a trigger order")
+		}
+		if len(co.Orders) != 2 {
+			return fmt.Errorf("OTOCO complex orders require 2 orders, got %d", len(co.Orders))
+		}
+	default:
+		return fmt.Errorf("unknown complex order type: %q", co.Type)
+	}
+
+	return nil
+}
+
+// Create a complex order and then runs the preflights without placing the order.
+func (c *Client) SubmitComplexOrderDryRun(accountNumber string, order NewComplexOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	path := fmt.Sprintf("/accounts/%s/complex-orders/dry-run", accountNumber)
+
+	return c.submitComplexOrder(path, order)
+}
+
+// Create a complex order for the client. OTO, OCO and OTOCO orders are
+// placed atomically.
+func (c *Client) SubmitComplexOrder(accountNumber string, order NewComplexOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	path := fmt.Sprintf("/accounts/%s/complex-orders", accountNumber)
+
+	return c.submitComplexOrder(path, order)
+}
+
+// Returns a single complex order based on the id.
+func (c *Client) GetComplexOrder(accountNumber string, id int) (ComplexOrder, *http.Response, error) {
+	path := fmt.Sprintf("/accounts/%s/complex-orders/%d", accountNumber, id)
+
+	type ordersResponse struct {
+		ComplexOrder ComplexOrder `json:"data"`
+	}
+
+	ordersRes := new(ordersResponse)
+
+	resp, err := c.request(http.MethodGet, path, nil, nil, ordersRes)
+	if err != nil {
+		return ComplexOrder{}, resp, err
+	}
+
+	return ordersRes.ComplexOrder, resp, nil
+}
+
+// Returns a list of live complex orders for the resource.
+func (c *Client) GetAccountLiveComplexOrders(accountNumber string) ([]ComplexOrder, *http.Response, error) {
+	path := fmt.Sprintf("/accounts/%s/complex-orders/live", accountNumber)
+
+	type ordersResponse struct {
+		Data struct {
+			ComplexOrders []ComplexOrder `json:"items"`
+		} `json:"data"`
+	}
+
+	ordersRes := new(ordersResponse)
+
+	resp, err := c.request(http.MethodGet, path, nil, nil, ordersRes)
+	if err != nil {
+		return []ComplexOrder{}, resp, err
+	}
+
+	return ordersRes.Data.ComplexOrders, resp, nil
+}
+
+// Requests cancellation of every order in the complex order.
+func (c *Client) CancelComplexOrder(accountNumber string, id int) (ComplexOrder, *http.Response, error) {
+	path := fmt.Sprintf("/accounts/%s/complex-orders/%d", accountNumber, id)
+
+	type ordersResponse struct {
+		ComplexOrder ComplexOrder `json:"data"`
+	}
+
+	ordersRes := new(ordersResponse)
+
+	resp, err := c.request(http.MethodDelete, path, nil, nil, ordersRes)
+	if err != nil {
+		return ComplexOrder{}, resp, err
+	}
+
+	return ordersRes.ComplexOrder, resp, nil
+}
+
+func (c *Client) submitComplexOrder(path string, order NewComplexOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	if err := order.Validate(); err != nil {
+		return OrderResponse{}, nil, nil, err
+	}
+
+	type ordersResponse struct {
+		OrderResponse OrderResponse       `json:"data"`
+		OrderError    *OrderErrorResponse `json:"error"`
+	}
+
+	ordersRes := new(ordersResponse)
+
+	resp, err := c.request(http.MethodPost, path, nil, order, ordersRes)
+	if err != nil {
+		return OrderResponse{}, nil, resp, err
+	}
+
+	return ordersRes.OrderResponse, ordersRes.OrderError, resp, nil
+}
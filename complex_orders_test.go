@@ -0,0 +1,336 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func testBracket() NewComplexOrder {
+	return NewComplexOrder{
+		Type: OTOCO,
+		TriggerOrder: &NewOrder{
+			TimeInForce: Day,
+			OrderType:   Limit,
+			Price:       decimal.NewFromInt(150),
+			PriceEffect: Debit,
+			Legs:        []NewOrderLeg{{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Action: Buy}},
+		},
+		Orders: []NewOrder{
+			{
+				TimeInForce: GTC,
+				OrderType:   Limit,
+				Price:       decimal.NewFromInt(160),
+				PriceEffect: Credit,
+				Legs:        []NewOrderLeg{{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Action: Sell}},
+			},
+			{
+				TimeInForce: GTC,
+				OrderType:   Stop,
+				StopTrigger: decimal.NewFromInt(140),
+				Legs:        []NewOrderLeg{{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Action: Sell}},
+			},
+		},
+	}
+}
+
+func TestNewComplexOrderValidate(t *testing.T) {
+	order := testBracket()
+	require.NoError(t, order.Validate())
+
+	order.Type = OCO
+	require.EqualError(t, order.Validate(), "OCO complex orders can't have a trigger order")
+
+	order.TriggerOrder = nil
+	require.NoError(t, order.Validate())
+
+	order.Orders = order.Orders[:1]
+	require.EqualError(t, order.Validate(), "OCO complex orders require 2 orders, got 1")
+
+	order.Type = OTO
+	require.EqualError(t, order.Validate(), "OTO complex orders require a trigger order")
+
+	order = testBracket()
+	order.Type = OTO
+	require.EqualError(t, order.Validate(), "OTO complex orders require 1 order, got 2")
+
+	order.Type = OTOCO
+	order.TriggerOrder = nil
+	require.EqualError(t, order.Validate(), "OTOCO complex orders require a trigger order")
+
+	order = testBracket()
+	order.Orders = nil
+	require.EqualError(t, order.Validate(), "OTOCO complex orders require 2 orders, got 0")
+
+	order.Type = "Bracket"
+	require.EqualError(t, order.Validate(), `unknown complex order type: "Bracket"`)
+}
+
+func TestSubmitComplexOrderDryRun(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountNumber := "5YZ55555"
+
+	mux.HandleFunc(fmt.Sprintf("/accounts/%s/complex-orders/dry-run", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, http.MethodPost, request.Method)
+
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+
+		var sent map[string]any
+		require.NoError(t, json.Unmarshal(body, &sent))
+		require.Equal(t, "OTOCO", sent["type"])
+		require.Len(t, sent["orders"], 2)
+		require.Equal(t, "Limit", sent["trigger-order"].(map[string]any)["order-type"])
+
+		fmt.Fprint(writer, complexOrderResp)
+	})
+
+	resp, orderErr, httpResp, err := client.SubmitComplexOrderDryRun(accountNumber, testBracket())
+	require.NoError(t, err)
+	require.NotNil(t, httpResp)
+	require.Nil(t, orderErr)
+
+	co := resp.ComplexOrder
+
+	require.Equal(t, 3456, co.ID)
+	require.Equal(t, OTOCO, co.Type)
+	require.Equal(t, accountNumber, co.AccountNumber)
+	require.Equal(t, "OTOCO::trigger-order", co.TriggerOrder.ComplexOrderTag)
+	require.Equal(t, Received, co.TriggerOrder.Status)
+	require.Len(t, co.Orders, 2)
+	require.Equal(t, Contingent, co.Orders[0].Status)
+	require.Equal(t, "160", co.Orders[0].Price.String())
+	require.Equal(t, "140", co.Orders[1].StopTrigger.String())
+	require.Equal(t, decimal.NewFromFloat(150.13), resp.BuyingPowerEffect.ChangeInBuyingPower)
+}
+
+func TestSubmitComplexOrder(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountNumber := "5YZ55555"
+
+	mux.HandleFunc(fmt.Sprintf("/accounts/%s/complex-orders", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, http.MethodPost, request.Method)
+		fmt.Fprint(writer, complexOrderResp)
+	})
+
+	resp, orderErr, httpResp, err := client.SubmitComplexOrder(accountNumber, testBracket())
+	require.NoError(t, err)
+	require.NotNil(t, httpResp)
+	require.Nil(t, orderErr)
+	require.Equal(t, 3456, resp.ComplexOrder.ID)
+
+	invalid := testBracket()
+	invalid.Type = OCO
+
+	_, _, httpResp, err = client.SubmitComplexOrder(accountNumber, invalid)
+	require.EqualError(t, err, "OCO complex orders can't have a trigger order")
+	require.Nil(t, httpResp)
+}
+
+func TestSubmitComplexOrderError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountNumber := "5YZ55555"
+
+	mux.HandleFunc(fmt.Sprintf("/accounts/%s/complex-orders", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+	mux.HandleFunc(fmt.Sprintf("/accounts/%s/complex-orders/dry-run", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, _, httpResp, err := client.SubmitComplexOrder(accountNumber, testBracket())
+	expectedUnauthorized(t, err)
+	require.NotNil(t, httpResp)
+
+	_, _, httpResp, err = client.SubmitComplexOrderDryRun(accountNumber, testBracket())
+	expectedUnauthorized(t, err)
+	require.NotNil(t, httpResp)
+}
+
+func TestGetComplexOrder(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountNumber := "5YZ55555"
+	id := 3456
+
+	mux.HandleFunc(fmt.Sprintf("/accounts/%s/complex-orders/%d", accountNumber, id), func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, http.MethodGet, request.Method)
+		fmt.Fprintf(writer, `{"data":%s}`, complexOrderJSON)
+	})
+
+	co, httpResp, err := client.GetComplexOrder(accountNumber, id)
+	require.NoError(t, err)
+	require.NotNil(t, httpResp)
+	require.Equal(t, id, co.ID)
+	require.Equal(t, "AAPL", co.TriggerOrder.UnderlyingSymbol)
+	require.Equal(t, "OTOCO::order", co.Orders[1].ComplexOrderTag)
+}
+
+func TestGetComplexOrderError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/complex-orders/3456", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, httpResp, err := client.GetComplexOrder("5YZ55555", 3456)
+	expectedUnauthorized(t, err)
+	require.NotNil(t, httpResp)
+}
+
+func TestGetAccountLiveComplexOrders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountNumber := "5YZ55555"
+
+	mux.HandleFunc(fmt.Sprintf("/accounts/%s/complex-orders/live", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprintf(writer, `{"data":{"items":[%s]},"context":"/accounts/5YZ55555/complex-orders/live"}`, complexOrderJSON)
+	})
+
+	orders, httpResp, err := client.GetAccountLiveComplexOrders(accountNumber)
+	require.NoError(t, err)
+	require.NotNil(t, httpResp)
+	require.Len(t, orders, 1)
+	require.Equal(t, OTOCO, orders[0].Type)
+}
+
+func TestGetAccountLiveComplexOrdersError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/complex-orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, httpResp, err := client.GetAccountLiveComplexOrders("5YZ55555")
+	expectedUnauthorized(t, err)
+	require.NotNil(t, httpResp)
+}
+
+func TestCancelComplexOrder(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountNumber := "5YZ55555"
+	id := 3456
+
+	mux.HandleFunc(fmt.Sprintf("/accounts/%s/complex-orders/%d", accountNumber, id), func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, http.MethodDelete, request.Method)
+		fmt.Fprintf(writer, `{"data":%s}`, complexOrderJSON)
+	})
+
+	co, httpResp, err := client.CancelComplexOrder(accountNumber, id)
+	require.NoError(t, err)
+	require.NotNil(t, httpResp)
+	require.Equal(t, id, co.ID)
+}
+
+func TestCancelComplexOrderError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/complex-orders/3456", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, httpResp, err := client.CancelComplexOrder("5YZ55555", 3456)
+	expectedUnauthorized(t, err)
+	require.NotNil(t, httpResp)
+}
+
+const complexOrderJSON = `{
+  "id": 3456,
+  "account-number": "5YZ55555",
+  "type": "OTOCO",
+  "trigger-order": {
+    "id": 100,
+    "account-number": "5YZ55555",
+    "time-in-force": "Day",
+    "order-type": "Limit",
+    "size": 1,
+    "underlying-symbol": "AAPL",
+    "underlying-instrument-type": "Equity",
+    "price": "150.0",
+    "price-effect": "Debit",
+    "status": "Received",
+    "cancellable": true,
+    "editable": true,
+    "complex-order-id": 3456,
+    "complex-order-tag": "OTOCO::trigger-order",
+    "legs": [
+      {"instrument-type": "Equity", "symbol": "AAPL", "quantity": 1, "remaining-quantity": 1, "action": "Buy", "fills": []}
+    ]
+  },
+  "orders": [
+    {
+      "id": 101,
+      "account-number": "5YZ55555",
+      "time-in-force": "GTC",
+      "order-type": "Limit",
+      "size": 1,
+      "underlying-symbol": "AAPL",
+      "underlying-instrument-type": "Equity",
+      "price": "160.0",
+      "price-effect": "Credit",
+      "status": "Contingent",
+      "complex-order-id": 3456,
+      "complex-order-tag": "OTOCO::order",
+      "legs": [
+        {"instrument-type": "Equity", "symbol": "AAPL", "quantity": 1, "remaining-quantity": 1, "action": "Sell", "fills": []}
+      ]
+    },
+    {
+      "id": 102,
+      "account-number": "5YZ55555",
+      "time-in-force": "GTC",
+      "order-type": "Stop",
+      "size": 1,
+      "underlying-symbol": "AAPL",
+      "underlying-instrument-type": "Equity",
+      "stop-trigger": "140.0",
+      "status": "Contingent",
+      "complex-order-id": 3456,
+      "complex-order-tag": "OTOCO::order",
+      "legs": [
+        {"instrument-type": "Equity", "symbol": "AAPL", "quantity": 1, "remaining-quantity": 1, "action": "Sell", "fills": []}
+      ]
+    }
+  ],
+  "related-orders": []
+}`
+
+var complexOrderResp = `{
+  "data": {
+    "complex-order": ` + complexOrderJSON + `,
+    "warnings": [],
+    "buying-power-effect": {
+      "change-in-buying-power": "150.13",
+      "change-in-buying-power-effect": "Debit",
+      "effect": "Debit"
+    },
+    "fee-calculation": {
+      "total-fees": "0.0",
+      "total-fees-effect": "None"
+    }
+  },
+  "context": "/accounts/5YZ55555/complex-orders"
+}`
@@ -17,11 +17,38 @@ type OptionType string
 type MonthCode string
 type Exchange string
 type SortOrder string
+type MarketEventType string
+type PrintSide string
+type OverflowPolicy string
+type AccountEventType string
+type StrategyType string
+type ComplexOrderType string
+type Rounding string
+type JournalEntryType string
+type AuditAction string
+type ExecutionAlgorithm string
+type ExecutionStatus string
+type RemainderPolicy string
+type PreviewColumn string
 
 // The normal flow for a filled order would be Received -> Routed -> In Flight -> Live -> Filled.
 // Order status updates come in real-time to websocket clients that have sent the account-subscribe message.
 type OrderStatus string
 
+// Flags of indexed events such as TimeAndSale.
+const (
+	// The event is part of a transaction still in progress.
+	TxPending = 0x01
+	// The event removes the event with its index.
+	RemoveEvent = 0x02
+	// The event starts a snapshot replacing every event of its symbol.
+	SnapshotBegin = 0x04
+	// The event ends a snapshot.
+	SnapshotEnd = 0x08
+	// The event ends a snapshot cut short by the server.
+	SnapshotSnip = 0x10
+)
+
 const (
 	// InstrumentType.
 	Bond           InstrumentType = "Bond"
@@ -150,4 +177,115 @@ const (
 	// SortOrder.
 	Asc  SortOrder = "Asc"
 	Desc SortOrder = "Desc"
+	// MarketEventType.
+	QuoteEvent       MarketEventType = "Quote"
+	TradeEvent       MarketEventType = "Trade"
+	TimeAndSaleEvent MarketEventType = "TimeAndSale"
+	GreeksEvent      MarketEventType = "Greeks"
+	SummaryEvent     MarketEventType = "Summary"
+	// PrintSide.
+
+	// Print at or through the ask.
+	AtAsk PrintSide = "At Ask"
+	// Print between the mid and the ask.
+	NearAsk PrintSide = "Near Ask"
+	// Print exactly at the mid.
+	AtMid PrintSide = "At Mid"
+	// Print between the bid and the mid.
+	NearBid PrintSide = "Near Bid"
+	// Print at or through the bid.
+	AtBid PrintSide = "At Bid"
+	// No bid/ask was available to classify the print.
+	UnknownSide PrintSide = "Unknown"
+	// OverflowPolicy.
+
+	// Discard the incoming event when the consumer buffer is full.
+	DropNewest OverflowPolicy = "Drop Newest"
+	// Discard the oldest buffered event to make room for the incoming event.
+	DropOldest OverflowPolicy = "Drop Oldest"
+	// Wait for the consumer up to its block timeout before dropping the event,
+	// blocking dispatch to every other consumer meanwhile.
+	Backpressure OverflowPolicy = "Backpressure"
+	// AccountEventType.
+	OrderNotification               AccountEventType = "Order"
+	ComplexOrderNotification        AccountEventType = "ComplexOrder"
+	BalanceNotification             AccountEventType = "AccountBalance"
+	PositionNotification            AccountEventType = "CurrentPosition"
+	OrderChainNotification          AccountEventType = "OrderChain"
+	ExternalTransactionNotification AccountEventType = "ExternalTransaction"
+	TradingStatusNotification       AccountEventType = "TradingStatus"
+	UnderlyingSummaryNotification   AccountEventType = "UnderlyingYearGainSummary"
+	// StrategyType.
+	Vertical    StrategyType = "Vertical"
+	Strangle    StrategyType = "Strangle"
+	Straddle    StrategyType = "Straddle"
+	IronCondor  StrategyType = "Iron Condor"
+	Butterfly   StrategyType = "Butterfly"
+	Calendar    StrategyType = "Calendar"
+	Diagonal    StrategyType = "Diagonal"
+	CoveredCall StrategyType = "Covered Call"
+	JadeLizard  StrategyType = "Jade Lizard"
+	// ComplexOrderType.
+
+	// One triggers other, the orders are routed once the trigger order fills.
+	OTO ComplexOrderType = "OTO"
+	// One cancels other, filling either order cancels the other.
+	OCO ComplexOrderType = "OCO"
+	// One triggers one cancels other, an OCO pair routed once the trigger order fills.
+	OTOCO ComplexOrderType = "OTOCO"
+	// Rounding.
+	RoundDown    Rounding = "Down"
+	RoundUp      Rounding = "Up"
+	RoundNearest Rounding = "Nearest"
+
+	// JournalEntryType.
+
+	// The order is about to be submitted.
+	JournalIntent JournalEntryType = "Intent"
+	// The order was accepted.
+	JournalAccepted JournalEntryType = "Accepted"
+	// The order was rejected and never reached the exchange.
+	JournalRejected JournalEntryType = "Rejected"
+	// The submission failed without telling whether the order was accepted.
+	JournalUnknown JournalEntryType = "Unknown"
+	// Reconciliation found the order.
+	JournalReconciled JournalEntryType = "Reconciled"
+	// Reconciliation found no order, it can be resubmitted.
+	JournalMissing JournalEntryType = "Missing"
+
+	// AuditAction.
+	AuditSubmitOrder       AuditAction = "Submit Order"
+	AuditSubmitOrderDryRun AuditAction = "Submit Order Dry Run"
+	AuditReplaceOrder      AuditAction = "Replace Order"
+	AuditPatchOrder        AuditAction = "Patch Order"
+	AuditCancelOrder       AuditAction = "Cancel Order"
+
+	// ExecutionAlgorithm.
+
+	// Slices the order evenly over a duration.
+	TWAP ExecutionAlgorithm = "TWAP"
+	// Shows a displayed quantity at a time, the next slice submitted when it fills.
+	Iceberg ExecutionAlgorithm = "Iceberg"
+
+	// ExecutionStatus.
+	ExecutionWorking   ExecutionStatus = "Working"
+	ExecutionPaused    ExecutionStatus = "Paused"
+	ExecutionCompleted ExecutionStatus = "Completed"
+	ExecutionCancelled ExecutionStatus = "Cancelled"
+	ExecutionFailed    ExecutionStatus = "Failed"
+
+	// RemainderPolicy.
+
+	// The remaining quantity is left unfilled.
+	CancelRemainder RemainderPolicy = "Cancel"
+	// The remaining quantity is submitted as a market order.
+	MarketRemainder RemainderPolicy = "Market"
+
+	// PreviewColumn.
+	PreviewName              PreviewColumn = "name"
+	PreviewBuyingPowerChange PreviewColumn = "buying-power-change"
+	PreviewIsolatedMargin    PreviewColumn = "isolated-margin"
+	PreviewFees              PreviewColumn = "fees"
+	PreviewCredit            PreviewColumn = "credit"
+	PreviewReturnOnCapital   PreviewColumn = "return-on-capital"
 )
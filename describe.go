@@ -0,0 +1,562 @@
+package tasty
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const describeDateLayout = "Jan02'06"
+
+// DescribeConfig configures order descriptions.
+type DescribeConfig struct {
+	// Shares delivered by one contract of the option symbols, i.e. from
+	// EquityOption.SharesPerContract, recognizing covered calls of
+	// non-standard deliverables. Defaults to 100.
+	SharesPerContract map[string]int
+}
+
+// describedLeg is a leg with its option symbol parsed for descriptions.
+type describedLeg struct {
+	action   OrderAction
+	quantity decimal.Decimal
+	// The underlying of options, the symbol otherwise
+	symbol     string
+	option     bool
+	optionType OptionType
+	strike     decimal.Decimal
+	expiration time.Time
+	// Shares delivered by one option contract
+	shares decimal.Decimal
+}
+
+// String describes the leg i.e. STO 2 AAPL Jan19'24 150 Put.
+func (l NewOrderLeg) String() string {
+	return newDescribedLeg(l.InstrumentType, l.Symbol, l.Quantity, l.Action).String()
+}
+
+// String describes the leg i.e. STO 2 AAPL Jan19'24 150 Put.
+func (ol OrderLeg) String() string {
+	return newDescribedLeg(ol.InstrumentType, ol.Symbol, ol.Quantity, ol.Action).String()
+}
+
+// Describe describes the leg and its fills i.e.
+// STO 2 AAPL Jan19'24 150 Put, filled 1 @ 1.30.
+func (ol OrderLeg) Describe() string {
+	filled := ol.FilledQuantity()
+	if filled.IsZero() {
+		return ol.String()
+	}
+
+	price, _ := ol.AverageFillPrice()
+
+	return fmt.Sprintf("%s, filled %s @ %s", ol, filled, formatPrice(price))
+}
+
+// String describes the order on one line, recognizing the shape of option
+// strategies i.e. STO 2 AAPL Jan19'24 150/145 Put Vertical @ 1.25 Credit GTC.
+// Legs that aren't a strategy are listed.
+func (o NewOrder) String() string {
+	return o.StringWith(DescribeConfig{})
+}
+
+// StringWith describes the order on one line like String with the
+// deliverables of the config.
+func (o NewOrder) StringWith(config DescribeConfig) string {
+	legs := make([]describedLeg, 0, len(o.Legs))
+	for _, leg := range o.Legs {
+		legs = append(legs, config.leg(leg.InstrumentType, leg.Symbol, leg.Quantity, leg.Action))
+	}
+
+	return describeOrder(legs, orderTerms{
+		orderType:   o.OrderType,
+		price:       o.Price,
+		priceEffect: o.PriceEffect,
+		value:       o.Value,
+		valueEffect: o.ValueEffect,
+		stopTrigger: o.StopTrigger,
+		timeInForce: o.TimeInForce,
+		gtcDate:     o.GtcDate,
+	})
+}
+
+// Describe describes the order followed by each of its legs on its own line.
+func (o NewOrder) Describe() string {
+	lines := []string{o.String()}
+	for _, leg := range o.Legs {
+		lines = append(lines, "  "+leg.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// String describes the order on one line with its ID and status i.e.
+// #123 STO 2 AAPL Jan19'24 150/145 Put Vertical @ 1.25 Credit GTC [Live].
+func (o Order) String() string {
+	return o.StringWith(DescribeConfig{})
+}
+
+// StringWith describes the order on one line like String with the
+// deliverables of the config.
+func (o Order) StringWith(config DescribeConfig) string {
+	legs := make([]describedLeg, 0, len(o.Legs))
+	for _, leg := range o.Legs {
+		legs = append(legs, config.leg(leg.InstrumentType, leg.Symbol, leg.Quantity, leg.Action))
+	}
+
+	s := describeOrder(legs, orderTerms{
+		orderType:   o.OrderType,
+		price:       o.Price,
+		priceEffect: o.PriceEffect,
+		value:       o.Value,
+		valueEffect: o.ValueEffect,
+		stopTrigger: o.StopTrigger,
+		timeInForce: o.TimeInForce,
+		gtcDate:     o.GtcDate,
+	})
+
+	if o.ID != 0 {
+		s = fmt.Sprintf("#%d %s", o.ID, s)
+	}
+	if o.Status != "" {
+		s = fmt.Sprintf("%s [%s]", s, o.Status)
+	}
+
+	return s
+}
+
+// Describe describes the order followed by each of its legs and their fills
+// on its own line.
+func (o Order) Describe() string {
+	lines := []string{o.String()}
+	for _, leg := range o.Legs {
+		lines = append(lines, "  "+leg.Describe())
+	}
+
+	if o.RejectReason != "" {
+		lines = append(lines, "  rejected: "+o.RejectReason)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// String describes the position i.e. Short 3 AAPL Jan19'24 150 Put.
+func (ap AccountPosition) String() string {
+	direction := Long
+	if ap.Sign().IsNegative() {
+		direction = Short
+	}
+
+	leg := newDescribedLeg(ap.InstrumentType, ap.Symbol, decimal.NewFromInt(int64(ap.Quantity)).Abs(), "")
+
+	return fmt.Sprintf("%s %s %s", direction, leg.quantity, leg.contract())
+}
+
+// Describe describes the position with its average open price and, when it
+// has a mark, its unrealized profit and loss i.e.
+// Short 3 AAPL Jan19'24 150 Put @ 1.25 avg, mark 0.80, P/L 135.00.
+func (ap AccountPosition) Describe() string {
+	s := fmt.Sprintf("%s @ %s avg", ap, formatPrice(ap.AverageOpenPrice))
+
+	if !ap.MarkPrice.IsZero() {
+		unrealized, _ := ap.PnL(ap.MarkPrice)
+		s = fmt.Sprintf("%s, mark %s, P/L %s", s, formatPrice(ap.MarkPrice), unrealized.StringFixed(2))
+	}
+
+	return s
+}
+
+// leg parses the leg with the shares per contract of its symbol.
+func (dc DescribeConfig) leg(instrumentType InstrumentType, symbol string, quantity decimal.Decimal, action OrderAction) describedLeg {
+	leg := newDescribedLeg(instrumentType, symbol, quantity, action)
+	if shares := dc.SharesPerContract[symbol]; shares > 0 {
+		leg.shares = decimal.NewFromInt(int64(shares))
+	}
+
+	return leg
+}
+
+func newDescribedLeg(instrumentType InstrumentType, symbol string, quantity decimal.Decimal, action OrderAction) describedLeg {
+	leg := describedLeg{
+		action:   action,
+		quantity: quantity,
+		symbol:   strings.TrimSpace(symbol),
+		shares:   decimal.NewFromInt(defaultSharesPerContract),
+	}
+
+	switch instrumentType {
+	case EquityOptionIT:
+		if occ, err := NewOCCFromString(symbol); err == nil {
+			leg.symbol, leg.option = occ.Symbol, true
+			leg.optionType, leg.strike, leg.expiration = occ.OptionType, occ.Strike, occ.Expiration
+		}
+	case FutureOptionIT:
+		if fos, err := NewFOSFromString(symbol); err == nil {
+			leg.symbol, leg.option = fos.FutureContractCode, true
+			leg.optionType, leg.strike, leg.expiration = fos.OptionType, fos.Strike, fos.Expiration
+		}
+	}
+
+	return leg
+}
+
+// contract describes what the leg trades i.e. AAPL Jan19'24 150 Put.
+func (l describedLeg) contract() string {
+	if !l.option {
+		return l.symbol
+	}
+
+	return fmt.Sprintf("%s %s %s %s", l.symbol, l.expiration.Format(describeDateLayout), l.strike, optionTypeName(l.optionType))
+}
+
+func (l describedLeg) String() string {
+	// notional orders have no quantity
+	if l.quantity.IsZero() {
+		return fmt.Sprintf("%s %s", actionAbbreviation(l.action), l.contract())
+	}
+
+	return fmt.Sprintf("%s %s %s", actionAbbreviation(l.action), l.quantity, l.contract())
+}
+
+// orderTerms are the price and time in force of an order.
+type orderTerms struct {
+	orderType   OrderType
+	price       decimal.Decimal
+	priceEffect PriceEffect
+	value       decimal.Decimal
+	valueEffect PriceEffect
+	stopTrigger decimal.Decimal
+	timeInForce TimeInForce
+	gtcDate     string
+}
+
+// describeOrder describes the legs, as a strategy when they have a shape, and
+// the terms of the order.
+func describeOrder(legs []describedLeg, terms orderTerms) string {
+	parts := []string{describeLegs(legs, terms.priceEffect)}
+
+	switch terms.orderType {
+	case Market:
+		parts = append(parts, "@ Market")
+	case NotionalMarket:
+		parts = append(parts, "Notional "+formatPrice(terms.value), string(terms.valueEffect))
+	case Stop:
+		parts = append(parts, "Stop "+formatPrice(terms.stopTrigger))
+	case StopLimit:
+		parts = append(parts, "Stop "+formatPrice(terms.stopTrigger), "@ "+formatPrice(terms.price), string(terms.priceEffect))
+	default:
+		if !terms.price.IsZero() {
+			parts = append(parts, "@ "+formatPrice(terms.price), string(terms.priceEffect))
+		}
+	}
+
+	if terms.timeInForce == GTD && terms.gtcDate != "" {
+		parts = append(parts, fmt.Sprintf("%s %s", terms.timeInForce, terms.gtcDate))
+	} else {
+		parts = append(parts, string(terms.timeInForce))
+	}
+
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+
+	return strings.Join(nonEmpty, " ")
+}
+
+// describeLegs describes the legs as one strategy i.e. STO 2 AAPL Jan19'24
+// 150/145 Put Vertical, or else lists them.
+func describeLegs(legs []describedLeg, effect PriceEffect) string {
+	if len(legs) == 1 {
+		return legs[0].String()
+	}
+
+	units := 0
+	for _, leg := range legs {
+		if !leg.quantity.IsInteger() {
+			units = 1
+			break
+		}
+		units = gcd(units, int(leg.quantity.IntPart()))
+	}
+
+	if units > 0 {
+		action, ok := strategyAction(legs, effect)
+		shape, isShape := strategyShape(legs, decimal.NewFromInt(int64(units)))
+		if ok && isShape {
+			return fmt.Sprintf("%s %d %s", action, units, shape)
+		}
+	}
+
+	described := make([]string, 0, len(legs))
+	for _, leg := range legs {
+		described = append(described, leg.String())
+	}
+
+	return strings.Join(described, ", ")
+}
+
+// strategyAction returns the action of the legs as a whole, buying for debits
+// and selling for credits, or false when they open and close positions.
+func strategyAction(legs []describedLeg, effect PriceEffect) (string, bool) {
+	var opening, closing bool
+	for _, leg := range legs {
+		switch leg.action {
+		case BTO, STO:
+			opening = true
+		case BTC, STC:
+			closing = true
+		}
+	}
+
+	if opening && closing {
+		return "", false
+	}
+
+	sell := isSellAction(legs[0].action)
+	switch effect {
+	case Credit:
+		sell = true
+	case Debit:
+		sell = false
+	}
+
+	switch {
+	case opening && sell:
+		return actionAbbreviation(STO), true
+	case opening:
+		return actionAbbreviation(BTO), true
+	case closing && sell:
+		return actionAbbreviation(STC), true
+	case closing:
+		return actionAbbreviation(BTC), true
+	case sell:
+		return string(Sell), true
+	default:
+		return string(Buy), true
+	}
+}
+
+// strategyShape recognizes the strategy the legs form, describing its
+// contracts i.e. AAPL Jan19'24 150/145 Put Vertical.
+func strategyShape(legs []describedLeg, units decimal.Decimal) (string, bool) {
+	var options []describedLeg
+	var others []describedLeg
+	for _, leg := range legs {
+		if leg.option {
+			options = append(options, leg)
+		} else {
+			others = append(others, leg)
+		}
+	}
+
+	if len(options) == 0 {
+		return "", false
+	}
+
+	underlying := options[0].symbol
+	for _, leg := range options {
+		if leg.symbol != underlying {
+			return "", false
+		}
+	}
+
+	ratios := make([]decimal.Decimal, len(options))
+	for i, leg := range options {
+		ratios[i] = leg.quantity.Div(units)
+	}
+
+	if len(others) > 0 {
+		return coveredCallShape(others, options)
+	}
+
+	sameExpiration := true
+	for _, leg := range options {
+		sameExpiration = sameExpiration && leg.expiration.Equal(options[0].expiration)
+	}
+
+	date := options[0].expiration.Format(describeDateLayout)
+
+	switch {
+	case len(options) == 2 && sameExpiration:
+		return twoLegShape(options, ratios, underlying, date)
+	case len(options) == 2:
+		return timeSpreadShape(options, ratios, underlying)
+	case len(options) == 3 && sameExpiration:
+		return threeLegShape(options, ratios, underlying, date)
+	case len(options) == 4 && sameExpiration:
+		return ironCondorShape(options, ratios, underlying, date)
+	default:
+		return "", false
+	}
+}
+
+func twoLegShape(options []describedLeg, ratios []decimal.Decimal, underlying, date string) (string, bool) {
+	a, b := options[0], options[1]
+	if !ratios[0].Equal(ratios[1]) {
+		return "", false
+	}
+
+	sameSide := isSellAction(a.action) == isSellAction(b.action)
+
+	switch {
+	case a.optionType == b.optionType && !sameSide && !a.strike.Equal(b.strike):
+		return fmt.Sprintf("%s %s %s/%s %s %s", underlying, date, a.strike, b.strike, optionTypeName(a.optionType), Vertical), true
+	case a.optionType != b.optionType && sameSide && a.strike.Equal(b.strike):
+		return fmt.Sprintf("%s %s %s %s", underlying, date, a.strike, Straddle), true
+	case a.optionType != b.optionType && sameSide:
+		put, call := a, b
+		if put.optionType != Put {
+			put, call = b, a
+		}
+		return fmt.Sprintf("%s %s %s/%s %s", underlying, date, put.strike, call.strike, Strangle), true
+	default:
+		return "", false
+	}
+}
+
+func timeSpreadShape(options []describedLeg, ratios []decimal.Decimal, underlying string) (string, bool) {
+	near, far := options[0], options[1]
+	if far.expiration.Before(near.expiration) {
+		near, far = far, near
+	}
+
+	if near.optionType != far.optionType || !ratios[0].Equal(ratios[1]) ||
+		isSellAction(near.action) == isSellAction(far.action) {
+		return "", false
+	}
+
+	dates := fmt.Sprintf("%s/%s", near.expiration.Format(describeDateLayout), far.expiration.Format(describeDateLayout))
+	if near.strike.Equal(far.strike) {
+		return fmt.Sprintf("%s %s %s %s %s", underlying, dates, near.strike, optionTypeName(near.optionType), Calendar), true
+	}
+
+	return fmt.Sprintf("%s %s %s/%s %s %s", underlying, dates, near.strike, far.strike, optionTypeName(near.optionType), Diagonal), true
+}
+
+func threeLegShape(options []describedLeg, ratios []decimal.Decimal, underlying, date string) (string, bool) {
+	legs := sortedLegs(options, ratios)
+	low, mid, high := legs[0], legs[1], legs[2]
+	one, two := decimal.NewFromInt(1), decimal.NewFromInt(2)
+
+	strikes := fmt.Sprintf("%s/%s/%s", low.strike, mid.strike, high.strike)
+
+	// butterfly: equidistant wings on one side of a body of twice the size
+	if low.optionType == mid.optionType && mid.optionType == high.optionType &&
+		low.ratio.Equal(one) && mid.ratio.Equal(two) && high.ratio.Equal(one) &&
+		isSellAction(low.action) == isSellAction(high.action) && isSellAction(low.action) != isSellAction(mid.action) &&
+		mid.strike.Sub(low.strike).Equal(high.strike.Sub(mid.strike)) {
+		return fmt.Sprintf("%s %s %s %s %s", underlying, date, strikes, optionTypeName(mid.optionType), Butterfly), true
+	}
+
+	// jade lizard: short put below a short call vertical
+	if low.optionType == Put && mid.optionType == Call && high.optionType == Call &&
+		low.ratio.Equal(one) && mid.ratio.Equal(one) && high.ratio.Equal(one) &&
+		isSellAction(low.action) && isSellAction(mid.action) && !isSellAction(high.action) {
+		return fmt.Sprintf("%s %s %s %s", underlying, date, strikes, JadeLizard), true
+	}
+
+	return "", false
+}
+
+func ironCondorShape(options []describedLeg, ratios []decimal.Decimal, underlying, date string) (string, bool) {
+	legs := sortedLegs(options, ratios)
+	for _, leg := range legs {
+		if !leg.ratio.Equal(legs[0].ratio) {
+			return "", false
+		}
+	}
+
+	longPut, shortPut, shortCall, longCall := legs[0], legs[1], legs[2], legs[3]
+	if longPut.optionType != Put || shortPut.optionType != Put || shortCall.optionType != Call || longCall.optionType != Call {
+		return "", false
+	}
+
+	// the short body inside the long wings, or reversed for a long condor
+	if isSellAction(longPut.action) != isSellAction(longCall.action) ||
+		isSellAction(shortPut.action) != isSellAction(shortCall.action) ||
+		isSellAction(longPut.action) == isSellAction(shortPut.action) {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s %s %s/%s/%s/%s %s", underlying, date,
+		longPut.strike, shortPut.strike, shortCall.strike, longCall.strike, IronCondor), true
+}
+
+func coveredCallShape(others, options []describedLeg) (string, bool) {
+	if len(others) != 1 || len(options) != 1 {
+		return "", false
+	}
+
+	stock, call := others[0], options[0]
+	if stock.symbol != call.symbol || call.optionType != Call ||
+		isSellAction(stock.action) == isSellAction(call.action) ||
+		!stock.quantity.Equal(call.quantity.Mul(call.shares)) {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s %s %s %s", call.symbol, call.expiration.Format(describeDateLayout), call.strike, CoveredCall), true
+}
+
+type ratioLeg struct {
+	describedLeg
+	ratio decimal.Decimal
+}
+
+// sortedLegs returns the legs with their ratios sorted by strike, puts first.
+func sortedLegs(options []describedLeg, ratios []decimal.Decimal) []ratioLeg {
+	legs := make([]ratioLeg, len(options))
+	for i, leg := range options {
+		legs[i] = ratioLeg{leg, ratios[i]}
+	}
+
+	sort.SliceStable(legs, func(i, j int) bool {
+		if !legs[i].strike.Equal(legs[j].strike) {
+			return legs[i].strike.LessThan(legs[j].strike)
+		}
+		return legs[i].optionType == Put && legs[j].optionType == Call
+	})
+
+	return legs
+}
+
+// actionAbbreviation abbreviates option actions i.e. STO for Sell to Open.
+func actionAbbreviation(action OrderAction) string {
+	switch action {
+	case BTO:
+		return "BTO"
+	case BTC:
+		return "BTC"
+	case STO:
+		return "STO"
+	case STC:
+		return "STC"
+	default:
+		return string(action)
+	}
+}
+
+func optionTypeName(optionType OptionType) string {
+	switch optionType {
+	case Call:
+		return "Call"
+	case Put:
+		return "Put"
+	default:
+		return string(optionType)
+	}
+}
+
+// formatPrice formats prices with at least two decimal places.
+func formatPrice(price decimal.Decimal) string {
+	if price.Equal(price.Round(2)) {
+		return price.StringFixed(2)
+	}
+
+	return price.String()
+}
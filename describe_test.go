@@ -0,0 +1,216 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func describeLeg(instrumentType InstrumentType, symbol string, quantity int64, action OrderAction) NewOrderLeg {
+	return NewOrderLeg{InstrumentType: instrumentType, Symbol: symbol, Quantity: decimal.NewFromInt(quantity), Action: action}
+}
+
+func describeOption(symbol string, quantity int64, action OrderAction) NewOrderLeg {
+	return describeLeg(EquityOptionIT, symbol, quantity, action)
+}
+
+func TestNewOrderString(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		order    NewOrder
+		expected string
+	}{
+		{
+			name: "vertical",
+			order: NewOrder{TimeInForce: GTC, OrderType: Limit, Price: decimal.RequireFromString("1.25"), PriceEffect: Credit, Legs: []NewOrderLeg{
+				describeOption("AAPL  240119P00150000", 2, STO),
+				describeOption("AAPL  240119P00145000", 2, BTO),
+			}},
+			expected: "STO 2 AAPL Jan19'24 150/145 Put Vertical @ 1.25 Credit GTC",
+		},
+		{
+			name: "iron condor",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("0.8"), PriceEffect: Debit, Legs: []NewOrderLeg{
+				describeOption("SPY   230915C00450000", 3, BTC),
+				describeOption("SPY   230915C00460000", 3, STC),
+				describeOption("SPY   230915P00410000", 3, BTC),
+				describeOption("SPY   230915P00400000", 3, STC),
+			}},
+			expected: "BTC 3 SPY Sep15'23 400/410/450/460 Iron Condor @ 0.80 Debit Day",
+		},
+		{
+			name: "strangle",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("3.10"), PriceEffect: Credit, Legs: []NewOrderLeg{
+				describeOption("SPY   230915C00460000", 1, STO),
+				describeOption("SPY   230915P00400000", 1, STO),
+			}},
+			expected: "STO 1 SPY Sep15'23 400/460 Strangle @ 3.10 Credit Day",
+		},
+		{
+			name: "straddle",
+			order: NewOrder{TimeInForce: Day, OrderType: Market, Legs: []NewOrderLeg{
+				describeOption("SPY   230915C00420000", 1, BTO),
+				describeOption("SPY   230915P00420000", 1, BTO),
+			}},
+			expected: "BTO 1 SPY Sep15'23 420 Straddle @ Market Day",
+		},
+		{
+			name: "calendar",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("1.05"), PriceEffect: Debit, Legs: []NewOrderLeg{
+				describeOption("SPY   230929C00420000", 1, BTO),
+				describeOption("SPY   230915C00420000", 1, STO),
+			}},
+			expected: "BTO 1 SPY Sep15'23/Sep29'23 420 Call Calendar @ 1.05 Debit Day",
+		},
+		{
+			name: "diagonal",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("2.35"), PriceEffect: Debit, Legs: []NewOrderLeg{
+				describeOption("SPY   230915P00410000", 2, STO),
+				describeOption("SPY   230929P00420000", 2, BTO),
+			}},
+			expected: "BTO 2 SPY Sep15'23/Sep29'23 410/420 Put Diagonal @ 2.35 Debit Day",
+		},
+		{
+			name: "butterfly",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("0.45"), PriceEffect: Debit, Legs: []NewOrderLeg{
+				describeOption("SPY   230915C00420000", 2, STO),
+				describeOption("SPY   230915C00410000", 1, BTO),
+				describeOption("SPY   230915C00430000", 1, BTO),
+			}},
+			expected: "BTO 1 SPY Sep15'23 410/420/430 Call Butterfly @ 0.45 Debit Day",
+		},
+		{
+			name: "jade lizard",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("2.05"), PriceEffect: Credit, Legs: []NewOrderLeg{
+				describeOption("SPY   230915P00400000", 1, STO),
+				describeOption("SPY   230915C00440000", 1, STO),
+				describeOption("SPY   230915C00450000", 1, BTO),
+			}},
+			expected: "STO 1 SPY Sep15'23 400/440/450 Jade Lizard @ 2.05 Credit Day",
+		},
+		{
+			name: "covered call",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("148.5"), PriceEffect: Debit, Legs: []NewOrderLeg{
+				describeLeg(EquityIT, "AAPL", 100, Buy),
+				describeOption("AAPL  240119C00160000", 1, STO),
+			}},
+			expected: "BTO 1 AAPL Jan19'24 160 Covered Call @ 148.50 Debit Day",
+		},
+		{
+			name: "roll",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("1.05"), PriceEffect: Credit, Legs: []NewOrderLeg{
+				describeOption("SPY   230915P00420000", 2, BTC),
+				describeOption("SPY   230929P00420000", 2, STO),
+			}},
+			expected: "BTC 2 SPY Sep15'23 420 Put, STO 2 SPY Sep29'23 420 Put @ 1.05 Credit Day",
+		},
+		{
+			name: "future option",
+			order: NewOrder{TimeInForce: GTD, GtcDate: "2023-09-01", OrderType: Limit, Price: decimal.RequireFromString("12.25"), PriceEffect: Credit, Legs: []NewOrderLeg{
+				describeLeg(FutureOptionIT, "./ESZ3 EW4U3 230922P4300", 1, STO),
+			}},
+			expected: "STO 1 /ESZ3 Sep22'23 4300 Put @ 12.25 Credit GTD 2023-09-01",
+		},
+		{
+			name: "fractional strike",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("0.25"), PriceEffect: Debit, Legs: []NewOrderLeg{
+				describeLeg(FutureOptionIT, "./ZNZ3 OZNF4 231222P108.5", 2, BTO),
+			}},
+			expected: "BTO 2 /ZNZ3 Dec22'23 108.5 Put @ 0.25 Debit Day",
+		},
+		{
+			name: "future calendar",
+			order: NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("45.25"), PriceEffect: Debit, Legs: []NewOrderLeg{
+				describeLeg(FutureIT, "/ESM3", 1, Sell),
+				describeLeg(FutureIT, "/ESU3", 1, Buy),
+			}},
+			expected: "Sell 1 /ESM3, Buy 1 /ESU3 @ 45.25 Debit Day",
+		},
+		{
+			name: "stop limit",
+			order: NewOrder{TimeInForce: GTC, OrderType: StopLimit, StopTrigger: decimal.NewFromInt(180), Price: decimal.RequireFromString("179.5"), PriceEffect: Credit, Legs: []NewOrderLeg{
+				describeLeg(EquityIT, "AAPL", 100, Sell),
+			}},
+			expected: "Sell 100 AAPL Stop 180.00 @ 179.50 Credit GTC",
+		},
+		{
+			name: "notional",
+			order: NewOrder{TimeInForce: Day, OrderType: NotionalMarket, Value: decimal.NewFromInt(500), ValueEffect: Debit, Legs: []NewOrderLeg{
+				describeLeg(Crypto, "BTC/USD", 0, Buy),
+			}},
+			expected: "Buy BTC/USD Notional 500.00 Debit Day",
+		},
+	} {
+		require.Equal(t, tc.expected, tc.order.String(), tc.name)
+	}
+}
+
+func TestNewOrderStringWith(t *testing.T) {
+	// the call delivers 150 shares after a 3 for 2 split
+	order := NewOrder{TimeInForce: Day, OrderType: Limit, Price: decimal.RequireFromString("220"), PriceEffect: Debit, Legs: []NewOrderLeg{
+		describeLeg(EquityIT, "AAPL", 150, Buy),
+		describeOption("AAPL  240119C00160000", 1, STO),
+	}}
+
+	require.Equal(t, "Buy 150 AAPL, STO 1 AAPL Jan19'24 160 Call @ 220.00 Debit Day", order.String())
+	require.Equal(t, "BTO 1 AAPL Jan19'24 160 Covered Call @ 220.00 Debit Day",
+		order.StringWith(DescribeConfig{SharesPerContract: map[string]int{"AAPL  240119C00160000": 150}}))
+}
+
+func TestNewOrderDescribe(t *testing.T) {
+	order := NewOrder{TimeInForce: GTC, OrderType: Limit, Price: decimal.RequireFromString("1.25"), PriceEffect: Credit, Legs: []NewOrderLeg{
+		describeOption("AAPL  240119P00150000", 2, STO),
+		describeOption("AAPL  240119P00145000", 2, BTO),
+	}}
+
+	require.Equal(t, "STO 2 AAPL Jan19'24 150/145 Put Vertical @ 1.25 Credit GTC\n"+
+		"  STO 2 AAPL Jan19'24 150 Put\n"+
+		"  BTO 2 AAPL Jan19'24 145 Put", order.Describe())
+}
+
+func TestOrderDescribe(t *testing.T) {
+	order := Order{
+		ID:          123,
+		Status:      Live,
+		TimeInForce: Day,
+		OrderType:   Limit,
+		Price:       decimal.RequireFromString("2.10"),
+		PriceEffect: Debit,
+		Legs: []OrderLeg{
+			{InstrumentType: EquityOptionIT, Symbol: "SPY   230915C00420000", Quantity: decimal.NewFromInt(2), Action: BTO, Fills: []OrderFill{
+				{Quantity: decimal.NewFromInt(1), FillPrice: decimal.RequireFromString("3.2")},
+			}},
+			{InstrumentType: EquityOptionIT, Symbol: "SPY   230915C00430000", Quantity: decimal.NewFromInt(2), Action: STO},
+		},
+	}
+
+	require.Equal(t, "#123 BTO 2 SPY Sep15'23 420/430 Call Vertical @ 2.10 Debit Day [Live]", order.String())
+	require.Equal(t, "#123 BTO 2 SPY Sep15'23 420/430 Call Vertical @ 2.10 Debit Day [Live]\n"+
+		"  BTO 2 SPY Sep15'23 420 Call, filled 1 @ 3.20\n"+
+		"  STO 2 SPY Sep15'23 430 Call", order.Describe())
+
+	order.Status = Rejected
+	order.RejectReason = "Insufficient buying power"
+	require.Contains(t, order.Describe(), "\n  rejected: Insufficient buying power")
+}
+
+func TestAccountPositionDescribe(t *testing.T) {
+	position := AccountPosition{
+		InstrumentType:    EquityOptionIT,
+		Symbol:            "AAPL  240119P00150000",
+		Quantity:          3,
+		QuantityDirection: Short,
+		Multiplier:        100,
+		AverageOpenPrice:  decimal.RequireFromString("1.25"),
+	}
+
+	require.Equal(t, "Short 3 AAPL Jan19'24 150 Put", position.String())
+	require.Equal(t, "Short 3 AAPL Jan19'24 150 Put @ 1.25 avg", position.Describe())
+
+	position.MarkPrice = decimal.RequireFromString("0.8")
+	require.Equal(t, "Short 3 AAPL Jan19'24 150 Put @ 1.25 avg, mark 0.80, P/L 135.00", position.Describe())
+
+	equity := AccountPosition{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: 100, QuantityDirection: Long, AverageOpenPrice: decimal.RequireFromString("171.125")}
+	require.Equal(t, "Long 100 AAPL @ 171.125 avg", equity.Describe())
+}
@@ -0,0 +1,424 @@
+package tasty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dxlinkVersion          = "0.1-tasty-go"
+	dxlinkFeedChannel      = 1
+	dxlinkKeepaliveTimeout = 60
+	dxlinkKeepalive        = 30 * time.Second
+	defaultEventBuffer     = 1024
+)
+
+// StreamerConn is a message based websocket connection used by the streamers.
+// tasty-go does not ship a websocket implementation, wrap the websocket
+// library of your choice i.e. gorilla/websocket or nhooyr.io/websocket.
+type StreamerConn interface {
+	// ReadMessage blocks until the next text message is received.
+	ReadMessage() ([]byte, error)
+	// WriteMessage sends a single text message.
+	WriteMessage(data []byte) error
+	Close() error
+}
+
+// StreamerDialer opens a StreamerConn to the given websocket url.
+type StreamerDialer func(url string) (StreamerConn, error)
+
+// MarketEvent is a decoded market data event i.e. Quote, Trade, TimeAndSale, Greeks or Summary.
+type MarketEvent interface {
+	EventType() MarketEventType
+	Symbol() string
+}
+
+// MarketDataFeed is a source of market events that can be subscribed to by symbol.
+type MarketDataFeed interface {
+	Subscribe(subs ...FeedSubscription) error
+	Unsubscribe(subs ...FeedSubscription) error
+	// Events is closed when the feed terminates.
+	Events() <-chan MarketEvent
+}
+
+// EventType of the quote.
+func (q Quote) EventType() MarketEventType { return QuoteEvent }
+
+// Symbol of the quote.
+func (q Quote) Symbol() string { return q.EventSymbol }
+
+// EventType of the trade.
+func (t Trade) EventType() MarketEventType { return TradeEvent }
+
+// Symbol of the trade.
+func (t Trade) Symbol() string { return t.EventSymbol }
+
+// EventType of the time and sale.
+func (ts TimeAndSale) EventType() MarketEventType { return TimeAndSaleEvent }
+
+// Symbol of the time and sale.
+func (ts TimeAndSale) Symbol() string { return ts.EventSymbol }
+
+// EventType of the greeks.
+func (g Greeks) EventType() MarketEventType { return GreeksEvent }
+
+// Symbol of the greeks.
+func (g Greeks) Symbol() string { return g.EventSymbol }
+
+// EventType of the summary.
+func (s Summary) EventType() MarketEventType { return SummaryEvent }
+
+// Symbol of the summary.
+func (s Summary) Symbol() string { return s.EventSymbol }
+
+// dxlinkEvents maps the supported event types to their models.
+var dxlinkEvents = map[MarketEventType]MarketEvent{
+	QuoteEvent:       Quote{},
+	TradeEvent:       Trade{},
+	TimeAndSaleEvent: TimeAndSale{},
+	GreeksEvent:      Greeks{},
+	SummaryEvent:     Summary{},
+}
+
+type dxlinkMessage struct {
+	Type                   string                       `json:"type"`
+	Channel                int                          `json:"channel"`
+	Version                string                       `json:"version,omitempty"`
+	KeepaliveTimeout       int                          `json:"keepaliveTimeout,omitempty"`
+	AcceptKeepaliveTimeout int                          `json:"acceptKeepaliveTimeout,omitempty"`
+	Token                  string                       `json:"token,omitempty"`
+	State                  string                       `json:"state,omitempty"`
+	Service                string                       `json:"service,omitempty"`
+	Parameters             map[string]string            `json:"parameters,omitempty"`
+	AcceptDataFormat       string                       `json:"acceptDataFormat,omitempty"`
+	AcceptEventFields      map[MarketEventType][]string `json:"acceptEventFields,omitempty"`
+	Add                    []FeedSubscription           `json:"add,omitempty"`
+	Remove                 []FeedSubscription           `json:"remove,omitempty"`
+	Data                   json.RawMessage              `json:"data,omitempty"`
+	Error                  string                       `json:"error,omitempty"`
+	Message                string                       `json:"message,omitempty"`
+}
+
+// DXLinkStreamer is a market data feed over the DXLink websocket protocol.
+type DXLinkStreamer struct {
+	conn      StreamerConn
+	events    chan MarketEvent
+	done      chan struct{}
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	errMu     sync.Mutex
+	err       error
+}
+
+// ConnectDXLink requests quote streamer tokens for the current customer and
+// opens an authorized DXLink feed using the given dialer.
+func (c *Client) ConnectDXLink(dial StreamerDialer) (*DXLinkStreamer, error) {
+	tokens, _, err := c.GetQuoteStreamerTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dial(tokens.DXLinkURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDXLinkStreamer(conn, tokens.Token)
+}
+
+// NewDXLinkStreamer runs the DXLink setup, authorization and feed channel
+// handshake over an open connection and starts streaming events.
+func NewDXLinkStreamer(conn StreamerConn, token string) (*DXLinkStreamer, error) {
+	s := &DXLinkStreamer{
+		conn:   conn,
+		events: make(chan MarketEvent, defaultEventBuffer),
+		done:   make(chan struct{}),
+	}
+
+	if err := s.handshake(token); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go s.readLoop()
+	go s.keepaliveLoop()
+
+	return s, nil
+}
+
+// Subscribe adds the subscriptions to the feed channel.
+func (s *DXLinkStreamer) Subscribe(subs ...FeedSubscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	return s.send(dxlinkMessage{Type: "FEED_SUBSCRIPTION", Channel: dxlinkFeedChannel, Add: subs})
+}
+
+// Unsubscribe removes the subscriptions from the feed channel.
+func (s *DXLinkStreamer) Unsubscribe(subs ...FeedSubscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	return s.send(dxlinkMessage{Type: "FEED_SUBSCRIPTION", Channel: dxlinkFeedChannel, Remove: subs})
+}
+
+// Events returns the decoded market events. The channel is closed when the streamer stops.
+func (s *DXLinkStreamer) Events() <-chan MarketEvent {
+	return s.events
+}
+
+// Err returns the error that terminated the streamer, if any.
+func (s *DXLinkStreamer) Err() error {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	return s.err
+}
+
+// Close stops the streamer and closes the underlying connection.
+func (s *DXLinkStreamer) Close() error {
+	var err error
+
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+
+	return err
+}
+
+func (s *DXLinkStreamer) handshake(token string) error {
+	err := s.send(dxlinkMessage{
+		Type:                   "SETUP",
+		Version:                dxlinkVersion,
+		KeepaliveTimeout:       dxlinkKeepaliveTimeout,
+		AcceptKeepaliveTimeout: dxlinkKeepaliveTimeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	authSent := false
+
+	for {
+		msg, err := s.read()
+		if err != nil {
+			return err
+		}
+
+		switch msg.Type {
+		case "AUTH_STATE":
+			if msg.State == "AUTHORIZED" {
+				err = s.send(dxlinkMessage{
+					Type:       "CHANNEL_REQUEST",
+					Channel:    dxlinkFeedChannel,
+					Service:    "FEED",
+					Parameters: map[string]string{"contract": "AUTO"},
+				})
+			} else {
+				if authSent {
+					return errors.New("dxlink: authorization failed")
+				}
+				authSent = true
+				err = s.send(dxlinkMessage{Type: "AUTH", Token: token})
+			}
+		case "CHANNEL_OPENED":
+			if msg.Channel == dxlinkFeedChannel {
+				return s.send(dxlinkMessage{
+					Type:              "FEED_SETUP",
+					Channel:           dxlinkFeedChannel,
+					AcceptDataFormat:  "FULL",
+					AcceptEventFields: dxlinkEventFields(),
+				})
+			}
+		case "ERROR":
+			return fmt.Errorf("dxlink: %s: %s", msg.Error, msg.Message)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *DXLinkStreamer) readLoop() {
+	defer close(s.events)
+
+	for {
+		msg, err := s.read()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				s.setErr(err)
+			}
+			return
+		}
+
+		switch msg.Type {
+		case "FEED_DATA":
+			// events that fail to decode are reported without dropping the
+			// rest of the batch
+			events, err := decodeFeedData(msg.Data)
+			if err != nil {
+				s.setErr(err)
+			}
+			for _, event := range events {
+				select {
+				case s.events <- event:
+				case <-s.done:
+					return
+				}
+			}
+		case "ERROR":
+			s.setErr(fmt.Errorf("dxlink: %s: %s", msg.Error, msg.Message))
+		}
+	}
+}
+
+func (s *DXLinkStreamer) keepaliveLoop() {
+	ticker := time.NewTicker(dxlinkKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.send(dxlinkMessage{Type: "KEEPALIVE"}); err != nil {
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *DXLinkStreamer) read() (dxlinkMessage, error) {
+	var msg dxlinkMessage
+
+	data, err := s.conn.ReadMessage()
+	if err != nil {
+		return msg, err
+	}
+
+	err = json.Unmarshal(data, &msg)
+
+	return msg, err
+}
+
+func (s *DXLinkStreamer) send(msg dxlinkMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return s.conn.WriteMessage(data)
+}
+
+func (s *DXLinkStreamer) setErr(err error) {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	s.err = err
+}
+
+// decodeFeedData decodes FULL format feed data into market events.
+// Unknown event types are skipped and events that fail to decode are skipped
+// with their errors joined.
+func decodeFeedData(data json.RawMessage) ([]MarketEvent, error) {
+	// dxfeed sends non finite numbers as strings which aren't valid decimals
+	data = bytes.ReplaceAll(data, []byte(`"-Infinity"`), []byte("null"))
+	data = bytes.ReplaceAll(data, []byte(`"Infinity"`), []byte("null"))
+	data = bytes.ReplaceAll(data, []byte(`"NaN"`), []byte("null"))
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	events := make([]MarketEvent, 0, len(raw))
+	var errs []error
+
+	for _, r := range raw {
+		event, err := decodeMarketEvent(r)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// decodeMarketEvent decodes a single market event object based on its eventType.
+func decodeMarketEvent(data json.RawMessage) (MarketEvent, error) {
+	var header struct {
+		EventType MarketEventType `json:"eventType"`
+	}
+
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	return unmarshalMarketEvent(header.EventType, data)
+}
+
+// unmarshalMarketEvent decodes the data into the model for the event type.
+// Unknown event types return a nil event.
+func unmarshalMarketEvent(eventType MarketEventType, data json.RawMessage) (MarketEvent, error) {
+	var err error
+
+	switch eventType {
+	case QuoteEvent:
+		var e Quote
+		err = json.Unmarshal(data, &e)
+		return e, err
+	case TradeEvent:
+		var e Trade
+		err = json.Unmarshal(data, &e)
+		return e, err
+	case TimeAndSaleEvent:
+		var e TimeAndSale
+		err = json.Unmarshal(data, &e)
+		return e, err
+	case GreeksEvent:
+		var e Greeks
+		err = json.Unmarshal(data, &e)
+		return e, err
+	case SummaryEvent:
+		var e Summary
+		err = json.Unmarshal(data, &e)
+		return e, err
+	default:
+		return nil, nil
+	}
+}
+
+// dxlinkEventFields returns the fields to request for each event type based on the model json tags.
+func dxlinkEventFields() map[MarketEventType][]string {
+	fields := make(map[MarketEventType][]string, len(dxlinkEvents))
+
+	for eventType, model := range dxlinkEvents {
+		names := []string{"eventType"}
+		t := reflect.TypeOf(model)
+		for i := 0; i < t.NumField(); i++ {
+			names = append(names, strings.Split(t.Field(i).Tag.Get("json"), ",")[0])
+		}
+		fields[eventType] = names
+	}
+
+	return fields
+}
@@ -0,0 +1,306 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn is an in memory StreamerConn for testing the streamers.
+type fakeConn struct {
+	in     chan []byte
+	out    chan []byte
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{
+		in:     make(chan []byte, 100),
+		out:    make(chan []byte, 100),
+		closed: make(chan struct{}),
+	}
+}
+
+func (fc *fakeConn) ReadMessage() ([]byte, error) {
+	select {
+	case msg := <-fc.in:
+		return msg, nil
+	case <-fc.closed:
+		return nil, errors.New("connection closed")
+	}
+}
+
+func (fc *fakeConn) WriteMessage(data []byte) error {
+	select {
+	case <-fc.closed:
+		return errors.New("connection closed")
+	default:
+	}
+	fc.out <- data
+	return nil
+}
+
+func (fc *fakeConn) Close() error {
+	fc.once.Do(func() { close(fc.closed) })
+	return nil
+}
+
+func (fc *fakeConn) next(t *testing.T) map[string]any {
+	t.Helper()
+
+	select {
+	case data := <-fc.out:
+		msg := map[string]any{}
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return msg
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for message")
+		return nil
+	}
+}
+
+// fakeFeed is an in memory MarketDataFeed for testing feed consumers.
+type fakeFeed struct {
+	mu           sync.Mutex
+	subscribed   []FeedSubscription
+	unsubscribed []FeedSubscription
+	events       chan MarketEvent
+	// returned by Unsubscribe when set
+	unsubscribeErr error
+}
+
+func newFakeFeed() *fakeFeed {
+	return &fakeFeed{events: make(chan MarketEvent, 100)}
+}
+
+func (ff *fakeFeed) Subscribe(subs ...FeedSubscription) error {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	ff.subscribed = append(ff.subscribed, subs...)
+	return nil
+}
+
+func (ff *fakeFeed) Unsubscribe(subs ...FeedSubscription) error {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	if ff.unsubscribeErr != nil {
+		return ff.unsubscribeErr
+	}
+	ff.unsubscribed = append(ff.unsubscribed, subs...)
+	return nil
+}
+
+func (ff *fakeFeed) Events() <-chan MarketEvent {
+	return ff.events
+}
+
+func connectedDXLink(t *testing.T) (*DXLinkStreamer, *fakeConn) {
+	t.Helper()
+
+	conn := newFakeConn()
+	conn.in <- []byte(`{"type":"SETUP","channel":0,"keepaliveTimeout":60,"acceptKeepaliveTimeout":60,"version":"1.0"}`)
+	conn.in <- []byte(`{"type":"AUTH_STATE","channel":0,"state":"UNAUTHORIZED"}`)
+	conn.in <- []byte(`{"type":"AUTH_STATE","channel":0,"state":"AUTHORIZED","userId":"1"}`)
+	conn.in <- []byte(`{"type":"CHANNEL_OPENED","channel":1,"service":"FEED","parameters":{"contract":"AUTO"}}`)
+
+	s, err := NewDXLinkStreamer(conn, "example-token-here")
+	require.NoError(t, err)
+
+	return s, conn
+}
+
+func TestNewDXLinkStreamer(t *testing.T) {
+	s, conn := connectedDXLink(t)
+	defer s.Close()
+
+	setup := conn.next(t)
+	require.Equal(t, "SETUP", setup["type"])
+	require.Equal(t, float64(0), setup["channel"])
+
+	auth := conn.next(t)
+	require.Equal(t, "AUTH", auth["type"])
+	require.Equal(t, "example-token-here", auth["token"])
+
+	channel := conn.next(t)
+	require.Equal(t, "CHANNEL_REQUEST", channel["type"])
+	require.Equal(t, "FEED", channel["service"])
+	require.Equal(t, float64(dxlinkFeedChannel), channel["channel"])
+
+	feedSetup := conn.next(t)
+	require.Equal(t, "FEED_SETUP", feedSetup["type"])
+	require.Equal(t, "FULL", feedSetup["acceptDataFormat"])
+
+	fields, ok := feedSetup["acceptEventFields"].(map[string]any)
+	require.True(t, ok)
+	require.Contains(t, fields["Quote"], "bidPrice")
+	require.Contains(t, fields["TimeAndSale"], "aggressorSide")
+	require.Contains(t, fields["Greeks"], "delta")
+	require.Contains(t, fields["Summary"], "openInterest")
+}
+
+func TestNewDXLinkStreamerUnauthorized(t *testing.T) {
+	conn := newFakeConn()
+	conn.in <- []byte(`{"type":"AUTH_STATE","channel":0,"state":"UNAUTHORIZED"}`)
+	conn.in <- []byte(`{"type":"AUTH_STATE","channel":0,"state":"UNAUTHORIZED"}`)
+
+	_, err := NewDXLinkStreamer(conn, "bad-token")
+	require.EqualError(t, err, "dxlink: authorization failed")
+
+	conn = newFakeConn()
+	conn.in <- []byte(`{"type":"ERROR","channel":0,"error":"UNSUPPORTED_PROTOCOL","message":"bad version"}`)
+
+	_, err = NewDXLinkStreamer(conn, "token")
+	require.EqualError(t, err, "dxlink: UNSUPPORTED_PROTOCOL: bad version")
+}
+
+func TestDXLinkSubscribe(t *testing.T) {
+	s, conn := connectedDXLink(t)
+	defer s.Close()
+
+	for i := 0; i < 4; i++ {
+		conn.next(t)
+	}
+
+	require.NoError(t, s.Subscribe(FeedSubscription{Type: QuoteEvent, Symbol: "AAPL"}))
+
+	sub := conn.next(t)
+	require.Equal(t, "FEED_SUBSCRIPTION", sub["type"])
+	require.Equal(t, []any{map[string]any{"type": "Quote", "symbol": "AAPL"}}, sub["add"])
+
+	require.NoError(t, s.Unsubscribe(FeedSubscription{Type: QuoteEvent, Symbol: "AAPL"}))
+
+	unsub := conn.next(t)
+	require.Equal(t, []any{map[string]any{"type": "Quote", "symbol": "AAPL"}}, unsub["remove"])
+
+	require.NoError(t, s.Subscribe())
+}
+
+func TestDXLinkEvents(t *testing.T) {
+	s, conn := connectedDXLink(t)
+
+	conn.in <- []byte(dxlinkFeedData)
+
+	quote, ok := (<-s.Events()).(Quote)
+	require.True(t, ok)
+	require.Equal(t, "AAPL", quote.Symbol())
+	require.Equal(t, QuoteEvent, quote.EventType())
+	require.True(t, decimal.RequireFromString("189.5").Equal(quote.BidPrice))
+	require.True(t, decimal.RequireFromString("189.52").Equal(quote.AskPrice))
+	require.True(t, quote.BidSize.IsZero())
+
+	tns, ok := (<-s.Events()).(TimeAndSale)
+	require.True(t, ok)
+	require.Equal(t, TimeAndSaleEvent, tns.EventType())
+	require.Equal(t, "BUY", tns.AggressorSide)
+	require.Equal(t, time.UnixMilli(1692021600000), tns.Time.Time())
+
+	greeks, ok := (<-s.Events()).(Greeks)
+	require.True(t, ok)
+	require.Equal(t, ".AAPL230818C185", greeks.Symbol())
+	require.True(t, decimal.RequireFromString("0.55").Equal(greeks.Delta))
+
+	require.NoError(t, s.Close())
+
+	_, open := <-s.Events()
+	require.False(t, open)
+	require.NoError(t, s.Err())
+}
+
+func TestDXLinkPartialFeedData(t *testing.T) {
+	s, conn := connectedDXLink(t)
+
+	conn.in <- []byte(`{"type":"FEED_DATA","channel":1,"data":[
+		{"eventType":"Quote","eventSymbol":"AAPL","bidPrice":"bad"},
+		{"eventType":"Quote","eventSymbol":"SPY","bidPrice":440.1,"askPrice":440.2}]}`)
+
+	quote, ok := (<-s.Events()).(Quote)
+	require.True(t, ok)
+	require.Equal(t, "SPY", quote.Symbol())
+	require.Error(t, s.Err())
+
+	require.NoError(t, s.Close())
+}
+
+func TestDXLinkReadError(t *testing.T) {
+	s, conn := connectedDXLink(t)
+
+	conn.Close()
+
+	_, open := <-s.Events()
+	require.False(t, open)
+	require.EqualError(t, s.Err(), "connection closed")
+}
+
+func TestConnectDXLink(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api-quote-tokens", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, quoteStreamerTokensResp)
+	})
+
+	_, err := client.ConnectDXLink(func(url string) (StreamerConn, error) {
+		require.Equal(t, "wss://tasty-openapi-ws.dxfeed.com/realtime", url)
+		return nil, errors.New("dial failed")
+	})
+	require.EqualError(t, err, "dial failed")
+}
+
+func TestConnectDXLinkError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api-quote-tokens", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.ConnectDXLink(nil)
+	expectedUnauthorized(t, err)
+}
+
+const dxlinkFeedData = `{
+  "type": "FEED_DATA",
+  "channel": 1,
+  "data": [
+    {
+      "eventType": "Quote",
+      "eventSymbol": "AAPL",
+      "bidPrice": 189.5,
+      "askPrice": 189.52,
+      "bidSize": "NaN",
+      "askSize": 200
+    },
+    {
+      "eventType": "TimeAndSale",
+      "eventSymbol": "AAPL",
+      "time": 1692021600000,
+      "index": 7267574470706102000,
+      "price": 189.52,
+      "size": 100,
+      "bidPrice": 189.5,
+      "askPrice": 189.52,
+      "aggressorSide": "BUY",
+      "type": "NEW"
+    },
+    {
+      "eventType": "Greeks",
+      "eventSymbol": ".AAPL230818C185",
+      "volatility": 0.2231,
+      "delta": 0.55,
+      "gamma": "Infinity"
+    },
+    {
+      "eventType": "Profile",
+      "eventSymbol": "AAPL"
+    }
+  ]
+}`
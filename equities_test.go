@@ -212,7 +212,7 @@ func TestGetEquityOptions(t *testing.T) {
 
 	sym := EquityOptionsSymbology{
 		Symbol:     symbol,
-		Strike:     185,
+		Strike:     decimal.NewFromInt(185),
 		OptionType: optionType,
 		Expiration: time.Date(2023, 6, 16, 0, 0, 0, 0, time.UTC),
 	}
@@ -261,7 +261,7 @@ func TestGetEquityOptionsError(t *testing.T) {
 
 	sym := EquityOptionsSymbology{
 		Symbol:     symbol,
-		Strike:     185,
+		Strike:     decimal.NewFromInt(185),
 		OptionType: optionType,
 		Expiration: time.Date(2023, 6, 16, 0, 0, 0, 0, time.UTC),
 	}
@@ -281,7 +281,7 @@ func TestGetEquityOption(t *testing.T) {
 
 	sym := EquityOptionsSymbology{
 		Symbol:     symbol,
-		Strike:     185,
+		Strike:     decimal.NewFromInt(185),
 		OptionType: optionType,
 		Expiration: time.Date(2023, 6, 16, 0, 0, 0, 0, time.UTC),
 	}
@@ -325,7 +325,7 @@ func TestGetEquityOptionError(t *testing.T) {
 
 	sym := EquityOptionsSymbology{
 		Symbol:     symbol,
-		Strike:     185,
+		Strike:     decimal.NewFromInt(185),
 		OptionType: optionType,
 		Expiration: time.Date(2023, 6, 16, 0, 0, 0, 0, time.UTC),
 	}
@@ -0,0 +1,814 @@
+package tasty
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultExecutionSlices       = 10
+	defaultExecutionPollInterval = 5 * time.Second
+)
+
+// ErrExecutionNotFound is returned when a store has no execution with the ID.
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// ExecutionConfig configures a TWAP or iceberg execution.
+type ExecutionConfig struct {
+	Algorithm ExecutionAlgorithm `json:"algorithm"`
+	// How long a TWAP slices the order over, required for TWAP. For icebergs
+	// the time after which the remainder is handled, 0 to work the order
+	// until it fills.
+	Duration time.Duration `json:"duration"`
+	// Number of TWAP slices, at most the order quantity. Defaults to 10.
+	Slices int `json:"slices"`
+	// Quantity shown by each iceberg slice, required for icebergs.
+	DisplayQuantity decimal.Decimal `json:"display-quantity"`
+	// What's done with the quantity left when the execution ends.
+	// Defaults to CancelRemainder.
+	Remainder RemainderPolicy `json:"remainder"`
+	// How often the working child order is checked. Defaults to 5 seconds.
+	PollInterval time.Duration `json:"poll-interval"`
+}
+
+// ChildOrder is an order submitted for a slice of an execution.
+type ChildOrder struct {
+	OrderID       int             `json:"order-id"`
+	ClientOrderID string          `json:"client-order-id"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Filled        decimal.Decimal `json:"filled"`
+	Status        OrderStatus     `json:"status"`
+	SubmittedAt   time.Time       `json:"submitted-at"`
+	// Submitted for the remainder when the execution ended
+	Remainder bool `json:"remainder,omitempty"`
+	// Saved before it's submitted and until its order is known, found by its
+	// client order ID before it's ever submitted again
+	Pending bool `json:"pending,omitempty"`
+}
+
+// ExecutionState is the state of a parent order worked by an execution, saved
+// to the execution's store after every change.
+type ExecutionState struct {
+	ID            string `json:"id"`
+	AccountNumber string `json:"account-number"`
+	// The parent order, child orders are copies of it for a slice of its quantity
+	Order     NewOrder        `json:"order"`
+	Config    ExecutionConfig `json:"config"`
+	Status    ExecutionStatus `json:"status"`
+	StartedAt time.Time       `json:"started-at"`
+	// When the execution was paused and how long it was paused before,
+	// pauses pushing back the TWAP schedule
+	PausedAt  time.Time     `json:"paused-at"`
+	PausedFor time.Duration `json:"paused-for"`
+	// TWAP slices submitted so far
+	Slice    int             `json:"slice"`
+	Filled   decimal.Decimal `json:"filled"`
+	Children []ChildOrder    `json:"children"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// ExecutionStore keeps the state of executions so they can be resumed after
+// a restart.
+type ExecutionStore interface {
+	Save(state ExecutionState) error
+	// Load returns ErrExecutionNotFound when the store has no execution with the ID.
+	Load(id string) (ExecutionState, error)
+	List() ([]ExecutionState, error)
+}
+
+// MemoryExecutionStore is an ExecutionStore kept in memory.
+type MemoryExecutionStore struct {
+	mu     sync.Mutex
+	states map[string]ExecutionState
+}
+
+// FileExecutionStore is an ExecutionStore keeping each execution in a JSON
+// file named by its ID in a directory.
+type FileExecutionStore struct {
+	dir string
+}
+
+// Executor starts executions and resumes the executions of its store.
+type Executor struct {
+	client  *Client
+	service OrderService
+	store   ExecutionStore
+}
+
+// Execution works a parent order through child orders with a TWAP or iceberg
+// algorithm, tracking the aggregate fills of the children.
+type Execution struct {
+	executor *Executor
+	now      func() time.Time
+	wake     chan struct{}
+
+	mu    sync.Mutex
+	state ExecutionState
+}
+
+// Remaining returns the quantity of the parent order left to fill.
+func (es ExecutionState) Remaining() decimal.Decimal {
+	if len(es.Order.Legs) == 0 {
+		return decimal.Zero
+	}
+
+	return decimal.Max(es.Order.Legs[0].Quantity.Sub(es.Filled), decimal.Zero)
+}
+
+// IsFinished returns whether or not the execution completed, was cancelled or failed.
+func (es ExecutionState) IsFinished() bool {
+	return es.Status == ExecutionCompleted || es.Status == ExecutionCancelled || es.Status == ExecutionFailed
+}
+
+// Save keeps the state of the execution.
+func (ms *MemoryExecutionStore) Save(state ExecutionState) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.states == nil {
+		ms.states = map[string]ExecutionState{}
+	}
+	state.Children = append([]ChildOrder(nil), state.Children...)
+	ms.states[state.ID] = state
+
+	return nil
+}
+
+// Load returns the state of the execution.
+func (ms *MemoryExecutionStore) Load(id string) (ExecutionState, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	state, ok := ms.states[id]
+	if !ok {
+		return ExecutionState{}, fmt.Errorf("%w: %s", ErrExecutionNotFound, id)
+	}
+	state.Children = append([]ChildOrder(nil), state.Children...)
+
+	return state, nil
+}
+
+// List returns the state of every execution in the order they started.
+func (ms *MemoryExecutionStore) List() ([]ExecutionState, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	states := make([]ExecutionState, 0, len(ms.states))
+	for _, state := range ms.states {
+		state.Children = append([]ChildOrder(nil), state.Children...)
+		states = append(states, state)
+	}
+	sortExecutions(states)
+
+	return states, nil
+}
+
+// OpenFileExecutionStore opens the store in the directory, creating it if it
+// doesn't exist.
+func OpenFileExecutionStore(dir string) (*FileExecutionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FileExecutionStore{dir: dir}, nil
+}
+
+// Save writes the state of the execution to a temporary file synced to disk
+// and renames it over the execution's file, so a crash never leaves a partly
+// written state.
+func (fs *FileExecutionStore) Save(state ExecutionState) error {
+	path, err := fs.path(state.ID)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(fs.dir, state.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(b); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// Load reads the state of the execution.
+func (fs *FileExecutionStore) Load(id string) (ExecutionState, error) {
+	path, err := fs.path(id)
+	if err != nil {
+		return ExecutionState{}, err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ExecutionState{}, fmt.Errorf("%w: %s", ErrExecutionNotFound, id)
+	}
+	if err != nil {
+		return ExecutionState{}, err
+	}
+
+	var state ExecutionState
+	if err = json.Unmarshal(b, &state); err != nil {
+		return ExecutionState{}, fmt.Errorf("execution %s: %w", id, err)
+	}
+
+	return state, nil
+}
+
+// List reads the state of every execution in the order they started.
+func (fs *FileExecutionStore) List() ([]ExecutionState, error) {
+	paths, err := filepath.Glob(filepath.Join(fs.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]ExecutionState, 0, len(paths))
+	for _, path := range paths {
+		state, err := fs.Load(filepath.Base(path[:len(path)-len(".json")]))
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	sortExecutions(states)
+
+	return states, nil
+}
+
+func (fs *FileExecutionStore) path(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid execution ID %q", id)
+	}
+
+	return filepath.Join(fs.dir, id+".json"), nil
+}
+
+// NewExecutor creates an executor submitting child orders through the client.
+func (c *Client) NewExecutor(store ExecutionStore) *Executor {
+	return c.NewExecutorFor(c, store)
+}
+
+// NewExecutorFor creates an executor submitting child orders through the
+// order service, fetching and cancelling them with the client.
+func (c *Client) NewExecutorFor(service OrderService, store ExecutionStore) *Executor {
+	return &Executor{client: c, service: service, store: store}
+}
+
+// Start saves a new execution of the single leg parent order, worked once Run
+// is called. The execution's ID is the order's client order ID, generated
+// when it has none, and its child orders are given the ID followed by their
+// number as client order IDs.
+func (e *Executor) Start(accountNumber string, order NewOrder, config ExecutionConfig) (*Execution, error) {
+	config, err := validateExecution(order, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.ExtClientOrderID == "" {
+		order.ExtClientOrderID = NewClientOrderID()
+	}
+
+	ex := e.execution(ExecutionState{
+		ID:            order.ExtClientOrderID,
+		AccountNumber: accountNumber,
+		Order:         order,
+		Config:        config,
+		Status:        ExecutionWorking,
+		StartedAt:     time.Now(),
+		Filled:        decimal.Zero,
+	})
+
+	if _, err = e.store.Load(ex.state.ID); err == nil {
+		return nil, fmt.Errorf("execution %s already exists", ex.state.ID)
+	} else if !errors.Is(err, ErrExecutionNotFound) {
+		return nil, err
+	}
+
+	if err = e.store.Save(ex.state); err != nil {
+		return nil, err
+	}
+
+	return ex, nil
+}
+
+// Load returns the execution with the ID from the store, i.e. to run it
+// again after a restart.
+func (e *Executor) Load(id string) (*Execution, error) {
+	state, err := e.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.execution(state), nil
+}
+
+// Unfinished returns the working and paused executions of the store.
+func (e *Executor) Unfinished() ([]*Execution, error) {
+	states, err := e.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var executions []*Execution
+	for _, state := range states {
+		if !state.IsFinished() {
+			executions = append(executions, e.execution(state))
+		}
+	}
+
+	return executions, nil
+}
+
+func (e *Executor) execution(state ExecutionState) *Execution {
+	return &Execution{executor: e, now: time.Now, wake: make(chan struct{}, 1), state: state}
+}
+
+// ID returns the ID of the execution.
+func (ex *Execution) ID() string {
+	return ex.state.ID
+}
+
+// State returns the state of the execution.
+func (ex *Execution) State() ExecutionState {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+
+	return ex.copyState()
+}
+
+// Run works the execution until it completes, is cancelled or fails, or the
+// context is done. A working child order is left working when the context is
+// done, the execution picking it up when it's run again. Errors fetching,
+// submitting or saving leave the execution working so it can be run again,
+// rejected child orders fail it. Child orders are saved before they're
+// submitted, a child order whose submission failed is looked up by its client
+// order ID before it's submitted again.
+func (ex *Execution) Run(ctx context.Context) (ExecutionState, error) {
+	ticker := time.NewTicker(ex.state.Config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		done, err := ex.step()
+		if done || err != nil {
+			return ex.State(), err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ex.State(), ctx.Err()
+		case <-ticker.C:
+		case <-ex.wake:
+		}
+	}
+}
+
+// Pause cancels the working child order and stops submitting new ones until
+// the execution is resumed. The unfilled quantity of a cancelled TWAP slice
+// rolls into the next slice.
+func (ex *Execution) Pause() error {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+
+	if ex.state.Status != ExecutionWorking {
+		return fmt.Errorf("can't pause %s execution %s", ex.state.Status, ex.state.ID)
+	}
+
+	ex.state.Status = ExecutionPaused
+	ex.state.PausedAt = ex.now()
+	_, err := ex.cancelWorking()
+	ex.notify()
+
+	return errors.Join(err, ex.save())
+}
+
+// Resume continues a paused execution, pushing back the TWAP schedule by the
+// time it was paused.
+func (ex *Execution) Resume() error {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+
+	if ex.state.Status != ExecutionPaused {
+		return fmt.Errorf("can't resume %s execution %s", ex.state.Status, ex.state.ID)
+	}
+
+	ex.state.Status = ExecutionWorking
+	ex.state.PausedFor += ex.now().Sub(ex.state.PausedAt)
+	ex.state.PausedAt = time.Time{}
+	ex.notify()
+
+	return ex.save()
+}
+
+// Cancel cancels the working child order and ends the execution, leaving the
+// remaining quantity unfilled.
+func (ex *Execution) Cancel() error {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+
+	if ex.state.IsFinished() {
+		return fmt.Errorf("can't cancel %s execution %s", ex.state.Status, ex.state.ID)
+	}
+
+	if _, err := ex.cancelWorking(); err != nil {
+		return err
+	}
+
+	ex.state.Status = ExecutionCancelled
+	ex.notify()
+
+	return ex.save()
+}
+
+// step refreshes the working child order and submits the next one when due,
+// returning whether or not the execution is finished.
+func (ex *Execution) step() (bool, error) {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+
+	if ex.state.IsFinished() {
+		return true, nil
+	}
+
+	err := ex.refresh()
+	if err == nil {
+		now := ex.now()
+		switch {
+		case ex.state.Status == ExecutionPaused:
+		case !ex.state.Remaining().IsPositive():
+			ex.state.Status = ExecutionCompleted
+		case ex.ending(now):
+			err = ex.finish()
+		case ex.state.Config.Algorithm == TWAP:
+			err = ex.nextSlice(now)
+		default:
+			err = ex.nextDisplay()
+		}
+	}
+
+	if saveErr := ex.save(); saveErr != nil {
+		return ex.state.IsFinished(), errors.Join(err, saveErr)
+	}
+
+	return ex.state.IsFinished(), err
+}
+
+// nextSlice submits the TWAP slice when due, cancelling the working child
+// order and adding its unfilled quantity to the slice.
+func (ex *Execution) nextSlice(now time.Time) error {
+	config := ex.state.Config
+	interval := config.Duration / time.Duration(config.Slices)
+	slice := int(now.Sub(ex.state.StartedAt.Add(ex.state.PausedFor))/interval) + 1
+	if slice > config.Slices {
+		slice = config.Slices
+	}
+	if slice <= ex.state.Slice {
+		return nil
+	}
+
+	if idle, err := ex.cancelWorking(); !idle {
+		return err
+	}
+
+	total := ex.state.Order.Legs[0].Quantity
+	scheduled := total.Mul(decimal.NewFromInt(int64(slice))).Div(decimal.NewFromInt(int64(config.Slices))).Floor()
+	ex.state.Slice = slice
+
+	quantity := scheduled.Sub(ex.state.Filled)
+	if !quantity.IsPositive() {
+		return nil
+	}
+
+	return ex.submit(quantity, false)
+}
+
+// nextDisplay submits the next iceberg slice once the working one is done.
+func (ex *Execution) nextDisplay() error {
+	if _, ok := ex.working(); ok {
+		return nil
+	}
+
+	return ex.submit(decimal.Min(ex.state.Config.DisplayQuantity, ex.state.Remaining()), false)
+}
+
+// finish cancels the working child order once the execution's time is up and
+// handles the remaining quantity, completing the execution once no child
+// order is working.
+func (ex *Execution) finish() error {
+	idle, err := ex.cancelWorking()
+	if !idle {
+		return err
+	}
+
+	remaining := ex.state.Remaining()
+	if ex.state.Config.Remainder == MarketRemainder && remaining.IsPositive() && !ex.submittedRemainder() {
+		return ex.submit(remaining, true)
+	}
+
+	ex.state.Status = ExecutionCompleted
+
+	return nil
+}
+
+// submit saves a pending child order for the quantity, a Day market order for
+// the remainder, and then sends it.
+func (ex *Execution) submit(quantity decimal.Decimal, remainder bool) error {
+	ex.state.Children = append(ex.state.Children, ChildOrder{
+		ClientOrderID: fmt.Sprintf("%s-%d", ex.state.ID, len(ex.state.Children)+1),
+		Quantity:      quantity,
+		Filled:        decimal.Zero,
+		SubmittedAt:   ex.now(),
+		Remainder:     remainder,
+		Pending:       true,
+	})
+
+	if err := ex.save(); err != nil {
+		ex.state.Children = ex.state.Children[:len(ex.state.Children)-1]
+		return err
+	}
+
+	return ex.send(len(ex.state.Children) - 1)
+}
+
+// send submits the pending child order. Errors sending it leave it pending
+// for the next step to reconcile.
+func (ex *Execution) send(i int) error {
+	child := &ex.state.Children[i]
+
+	order := ex.state.Order
+	order.ExtClientOrderID = child.ClientOrderID
+
+	leg := order.Legs[0]
+	leg.Quantity = child.Quantity
+	order.Legs = []NewOrderLeg{leg}
+
+	if child.Remainder {
+		order.TimeInForce = Day
+		order.GtcDate = ""
+		order.OrderType = Market
+		order.Price = decimal.Zero
+		order.PriceEffect = ""
+	}
+
+	resp, orderErr, _, err := ex.executor.service.SubmitOrder(ex.state.AccountNumber, order)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case orderErr != nil:
+		ex.state.Error = orderErr.Message
+	case resp.Order.Status == Rejected:
+		ex.state.Error = resp.Order.RejectReason
+	default:
+		child.OrderID = resp.Order.ID
+		child.Status = resp.Order.Status
+		child.Pending = false
+		return nil
+	}
+
+	child.Status = Rejected
+	child.Pending = false
+	ex.state.Status = ExecutionFailed
+
+	return fmt.Errorf("child order rejected: %s", ex.state.Error)
+}
+
+// refresh fetches the working child order and totals the fills. A pending
+// child order is searched for by its client order ID and sent again when it
+// isn't found while the execution is working.
+func (ex *Execution) refresh() error {
+	i, ok := ex.working()
+	if !ok {
+		return nil
+	}
+
+	if ex.state.Children[i].Pending {
+		found, err := ex.reconcile(i)
+		if err != nil || found || ex.state.Status != ExecutionWorking {
+			return err
+		}
+		return ex.send(i)
+	}
+
+	order, _, err := ex.executor.client.GetOrder(ex.state.AccountNumber, ex.state.Children[i].OrderID)
+	if err != nil {
+		return err
+	}
+	ex.update(i, order)
+
+	return nil
+}
+
+// reconcile searches the account's orders for the pending child order by its
+// client order ID, returning whether or not it was found.
+func (ex *Execution) reconcile(i int) (bool, error) {
+	child := ex.state.Children[i]
+
+	order, found, err := ex.executor.client.searchOrders(ex.state.AccountNumber, child.SubmittedAt.Add(-defaultMatchWindow),
+		func(orders []Order) (Order, bool) {
+			for _, order := range orders {
+				if order.ExtClientOrderID == child.ClientOrderID {
+					return order, true
+				}
+			}
+			return Order{}, false
+		})
+	if err != nil || !found {
+		return false, err
+	}
+
+	ex.state.Children[i].OrderID = order.ID
+	ex.state.Children[i].Pending = false
+	ex.update(i, order)
+
+	return true, nil
+}
+
+// cancelWorking cancels the working child order, returning whether or not no
+// child order is working afterwards. A failed cancel is resolved by fetching
+// the order, which may have filled. A pending child order that isn't found is
+// never sent again.
+func (ex *Execution) cancelWorking() (bool, error) {
+	i, ok := ex.working()
+	if !ok {
+		return true, nil
+	}
+
+	if ex.state.Children[i].Pending {
+		found, err := ex.reconcile(i)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			ex.state.Children[i].Pending = false
+			ex.state.Children[i].Status = Cancelled
+			return true, nil
+		}
+		if i, ok = ex.working(); !ok {
+			return true, nil
+		}
+	}
+
+	order, _, err := ex.executor.client.CancelOrder(ex.state.AccountNumber, ex.state.Children[i].OrderID)
+	if err != nil {
+		var refreshErr error
+		if order, _, refreshErr = ex.executor.client.GetOrder(ex.state.AccountNumber, ex.state.Children[i].OrderID); refreshErr != nil {
+			return false, errors.Join(err, refreshErr)
+		}
+		err = nil
+	}
+	ex.update(i, order)
+
+	_, working := ex.working()
+
+	return !working, err
+}
+
+// update records the latest snapshot of the child order.
+func (ex *Execution) update(i int, order Order) {
+	child := &ex.state.Children[i]
+	child.Status = order.Status
+	if len(order.Legs) > 0 {
+		child.Filled = order.Legs[0].FilledQuantity()
+	}
+
+	ex.state.Filled = decimal.Zero
+	for _, child := range ex.state.Children {
+		ex.state.Filled = ex.state.Filled.Add(child.Filled)
+	}
+}
+
+// working returns the index of the working child order, pending ones
+// included, only the last child order ever working.
+func (ex *Execution) working() (int, bool) {
+	i := len(ex.state.Children) - 1
+	if i < 0 || ex.state.Children[i].Status.IsTerminal() {
+		return 0, false
+	}
+
+	return i, true
+}
+
+func (ex *Execution) submittedRemainder() bool {
+	for _, child := range ex.state.Children {
+		if child.Remainder {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ending returns whether or not the execution's time is up.
+func (ex *Execution) ending(now time.Time) bool {
+	if ex.state.Config.Duration <= 0 {
+		return false
+	}
+
+	return !now.Before(ex.state.StartedAt.Add(ex.state.PausedFor + ex.state.Config.Duration))
+}
+
+func (ex *Execution) save() error {
+	if err := ex.executor.store.Save(ex.copyState()); err != nil {
+		return fmt.Errorf("save execution %s: %w", ex.state.ID, err)
+	}
+
+	return nil
+}
+
+func (ex *Execution) copyState() ExecutionState {
+	state := ex.state
+	state.Children = append([]ChildOrder(nil), ex.state.Children...)
+
+	return state
+}
+
+// notify wakes Run to act on a change without waiting for the next poll.
+func (ex *Execution) notify() {
+	select {
+	case ex.wake <- struct{}{}:
+	default:
+	}
+}
+
+// validateExecution checks the parent order and config, returning the config
+// with its defaults.
+func validateExecution(order NewOrder, config ExecutionConfig) (ExecutionConfig, error) {
+	if len(order.Legs) != 1 {
+		return config, fmt.Errorf("%w: executions work single leg orders", ErrInvalidOrder)
+	}
+	quantity := order.Legs[0].Quantity
+	if !quantity.IsPositive() || !quantity.IsInteger() {
+		return config, fmt.Errorf("%w: executions require a positive whole quantity", ErrInvalidOrder)
+	}
+	if order.OrderType != Limit && order.OrderType != Market {
+		return config, fmt.Errorf("%w: executions work Limit or Market orders", ErrInvalidOrder)
+	}
+
+	switch config.Algorithm {
+	case TWAP:
+		if config.Duration <= 0 {
+			return config, errors.New("TWAP execution requires a duration")
+		}
+		if config.Slices <= 0 {
+			config.Slices = defaultExecutionSlices
+		}
+		if quantity.LessThan(decimal.NewFromInt(int64(config.Slices))) {
+			config.Slices = int(quantity.IntPart())
+		}
+	case Iceberg:
+		if !config.DisplayQuantity.IsPositive() || !config.DisplayQuantity.IsInteger() {
+			return config, errors.New("iceberg execution requires a positive whole display quantity")
+		}
+	default:
+		return config, fmt.Errorf("unknown execution algorithm %q", config.Algorithm)
+	}
+
+	switch config.Remainder {
+	case "":
+		config.Remainder = CancelRemainder
+	case CancelRemainder, MarketRemainder:
+	default:
+		return config, fmt.Errorf("unknown remainder policy %q", config.Remainder)
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultExecutionPollInterval
+	}
+
+	return config, nil
+}
+
+func sortExecutions(states []ExecutionState) {
+	sort.SliceStable(states, func(i, j int) bool {
+		if states[i].StartedAt.Equal(states[j].StartedAt) {
+			return states[i].ID < states[j].ID
+		}
+		return states[i].StartedAt.Before(states[j].StartedAt)
+	})
+}
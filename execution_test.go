@@ -0,0 +1,476 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+// executionOrders is an order server filling orders on demand.
+type executionOrders struct {
+	mu       sync.Mutex
+	orders   map[int]*Order
+	sent     []NewOrder
+	autoFill bool
+	reject   string
+	// submissions failing with a 503, after creating the order when lost
+	dropped int
+	lost    int
+}
+
+func newExecutionOrders(t *testing.T) *executionOrders {
+	t.Helper()
+
+	eo := &executionOrders{orders: map[int]*Order{}}
+
+	mux.HandleFunc("/accounts/5YZ55555/orders", func(writer http.ResponseWriter, request *http.Request) {
+		eo.mu.Lock()
+		defer eo.mu.Unlock()
+
+		if request.Method == http.MethodGet {
+			fmt.Fprintf(writer, `{"data":{"items":%s},"pagination":{"total-pages":1}}`, eo.listLocked(false))
+			return
+		}
+
+		var order NewOrder
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&order))
+
+		if eo.reject != "" {
+			fmt.Fprintf(writer, `{"data":{},"error":{"code":"preflight_check_failure","message":%q}}`, eo.reject)
+			return
+		}
+		if eo.dropped > 0 {
+			eo.dropped--
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		eo.sent = append(eo.sent, order)
+		leg := order.Legs[0]
+		created := &Order{
+			ID:               len(eo.sent),
+			Status:           Live,
+			OrderType:        order.OrderType,
+			Price:            order.Price,
+			PriceEffect:      order.PriceEffect,
+			ExtClientOrderID: order.ExtClientOrderID,
+			Legs:             []OrderLeg{{InstrumentType: leg.InstrumentType, Symbol: leg.Symbol, Quantity: leg.Quantity, Action: leg.Action}},
+		}
+		eo.orders[created.ID] = created
+
+		if eo.lost > 0 {
+			eo.lost--
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		b, err := json.Marshal(created)
+		require.NoError(t, err)
+		fmt.Fprintf(writer, `{"data":{"order":%s}}`, b)
+	})
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		eo.mu.Lock()
+		defer eo.mu.Unlock()
+
+		fmt.Fprintf(writer, `{"data":{"items":%s}}`, eo.listLocked(true))
+	})
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/", func(writer http.ResponseWriter, request *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(request.URL.Path, "/accounts/5YZ55555/orders/"))
+		require.NoError(t, err)
+
+		eo.mu.Lock()
+		defer eo.mu.Unlock()
+
+		order := eo.orders[id]
+		switch request.Method {
+		case http.MethodGet:
+			if eo.autoFill && !order.Status.IsTerminal() {
+				eo.fillLocked(id, order.Legs[0].UnfilledQuantity())
+			}
+		case http.MethodDelete:
+			if order.Status.IsTerminal() {
+				writer.WriteHeader(422)
+				fmt.Fprint(writer, `{"error":{"code":"order_not_cancellable","message":"Order is not cancellable"}}`)
+				return
+			}
+			order.Status = Cancelled
+		}
+
+		b, err := json.Marshal(order)
+		require.NoError(t, err)
+		fmt.Fprintf(writer, `{"data":%s}`, b)
+	})
+
+	return eo
+}
+
+func (eo *executionOrders) fill(id int, quantity int64) {
+	eo.mu.Lock()
+	defer eo.mu.Unlock()
+
+	eo.fillLocked(id, decimal.NewFromInt(quantity))
+}
+
+func (eo *executionOrders) fillLocked(id int, quantity decimal.Decimal) {
+	order := eo.orders[id]
+	order.Legs[0].Fills = append(order.Legs[0].Fills, OrderFill{Quantity: quantity, FillPrice: order.Price})
+	if order.Legs[0].IsFilled() {
+		order.Status = Filled
+	}
+}
+
+func (eo *executionOrders) listLocked(working bool) []byte {
+	list := []*Order{}
+	for id := 1; id <= len(eo.sent); id++ {
+		if order := eo.orders[id]; !working || !order.Status.IsTerminal() {
+			list = append(list, order)
+		}
+	}
+
+	b, _ := json.Marshal(list)
+
+	return b
+}
+
+func (eo *executionOrders) quantities() []string {
+	eo.mu.Lock()
+	defer eo.mu.Unlock()
+
+	quantities := make([]string, 0, len(eo.sent))
+	for _, order := range eo.sent {
+		quantities = append(quantities, fmt.Sprintf("%s %s", order.OrderType, order.Legs[0].Quantity))
+	}
+
+	return quantities
+}
+
+func TestTWAPExecution(t *testing.T) {
+	setup()
+	defer teardown()
+
+	orders := newExecutionOrders(t)
+	store := &MemoryExecutionStore{}
+	executor := client.NewExecutor(store)
+
+	order := buyAAPL(100, "171.25")
+	order.ExtClientOrderID = "twap-1"
+	order.TimeInForce = GTC
+	ex, err := executor.Start("5YZ55555", order, ExecutionConfig{
+		Algorithm: TWAP,
+		Duration:  10 * time.Minute,
+		Slices:    4,
+		Remainder: MarketRemainder,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "twap-1", ex.ID())
+
+	start := time.Date(2023, 9, 1, 14, 0, 0, 0, time.UTC)
+	now := start
+	ex.now = func() time.Time { return now }
+	ex.state.StartedAt = start
+
+	at := func(elapsed time.Duration) {
+		t.Helper()
+
+		now = start.Add(elapsed)
+		done, err := ex.step()
+		require.NoError(t, err)
+		require.False(t, done)
+	}
+
+	// the first slice is submitted right away, 10 of 25 fill
+	at(0)
+	orders.fill(1, 10)
+	at(2 * time.Minute)
+	require.Equal(t, "10", ex.State().Filled.String())
+
+	// the second slice cancels the first and catches up
+	at(150 * time.Second)
+	orders.fill(2, 40)
+	at(3 * time.Minute)
+	require.NoError(t, ex.Pause())
+	at(4 * time.Minute)
+	require.Equal(t, ExecutionPaused, ex.State().Status)
+	require.Equal(t, "50", ex.State().Filled.String())
+
+	// two minutes paused push back the schedule
+	require.ErrorContains(t, ex.Pause(), "can't pause Paused execution twap-1")
+	now = start.Add(5 * time.Minute)
+	require.NoError(t, ex.Resume())
+	at(6 * time.Minute)
+	require.Len(t, orders.quantities(), 2)
+	at(450 * time.Second)
+	at(10 * time.Minute)
+	orders.fill(4, 20)
+
+	// the rest goes out at market when the time is up
+	at(12 * time.Minute)
+	require.Equal(t, []string{"Limit 25", "Limit 40", "Limit 25", "Limit 50", "Market 30"}, orders.quantities())
+	require.Equal(t, GTC, orders.sent[3].TimeInForce)
+	require.Equal(t, Day, orders.sent[4].TimeInForce)
+	orders.fill(5, 30)
+
+	now = start.Add(12*time.Minute + time.Second)
+	done, err := ex.step()
+	require.NoError(t, err)
+	require.True(t, done)
+
+	state := ex.State()
+	require.Equal(t, ExecutionCompleted, state.Status)
+	require.Equal(t, "100", state.Filled.String())
+	require.Equal(t, 4, state.Slice)
+	require.Equal(t, 2*time.Minute, state.PausedFor)
+	require.Len(t, state.Children, 5)
+	require.Equal(t, "twap-1-5", state.Children[4].ClientOrderID)
+	require.True(t, state.Children[4].Remainder)
+	require.Equal(t, Cancelled, state.Children[2].Status)
+
+	saved, err := store.Load("twap-1")
+	require.NoError(t, err)
+	require.Equal(t, state, saved)
+
+	unfinished, err := executor.Unfinished()
+	require.NoError(t, err)
+	require.Empty(t, unfinished)
+
+	require.ErrorContains(t, ex.Cancel(), "can't cancel Completed execution twap-1")
+}
+
+func TestIcebergExecution(t *testing.T) {
+	setup()
+	defer teardown()
+
+	orders := newExecutionOrders(t)
+	orders.autoFill = true
+
+	ex, err := client.NewExecutor(&MemoryExecutionStore{}).Start("5YZ55555", buyAAPL(25, "171.25"), ExecutionConfig{
+		Algorithm:       Iceberg,
+		DisplayQuantity: decimal.NewFromInt(10),
+		PollInterval:    time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.Len(t, ex.ID(), 32)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state, err := ex.Run(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ExecutionCompleted, state.Status)
+	require.Equal(t, "25", state.Filled.String())
+	require.Equal(t, []string{"Limit 10", "Limit 10", "Limit 5"}, orders.quantities())
+}
+
+func TestExecutionRestart(t *testing.T) {
+	setup()
+	defer teardown()
+
+	orders := newExecutionOrders(t)
+	dir := t.TempDir()
+
+	store, err := OpenFileExecutionStore(dir)
+	require.NoError(t, err)
+
+	order := buyAAPL(30, "171.25")
+	order.ExtClientOrderID = "iceberg-1"
+	ex, err := client.NewExecutor(store).Start("5YZ55555", order, ExecutionConfig{
+		Algorithm:       Iceberg,
+		DisplayQuantity: decimal.NewFromInt(10),
+		Duration:        time.Hour,
+	})
+	require.NoError(t, err)
+
+	_, err = client.NewExecutor(store).Start("5YZ55555", order, ExecutionConfig{Algorithm: Iceberg, DisplayQuantity: decimal.NewFromInt(10)})
+	require.EqualError(t, err, "execution iceberg-1 already exists")
+
+	done, err := ex.step()
+	require.NoError(t, err)
+	require.False(t, done)
+	orders.fill(1, 4)
+
+	// a new process picks up the working child order
+	reopened, err := OpenFileExecutionStore(dir)
+	require.NoError(t, err)
+	executor := client.NewExecutor(reopened)
+
+	unfinished, err := executor.Unfinished()
+	require.NoError(t, err)
+	require.Len(t, unfinished, 1)
+
+	resumed := unfinished[0]
+	require.Equal(t, "iceberg-1", resumed.ID())
+	require.Len(t, resumed.State().Children, 1)
+	require.Equal(t, time.Hour, resumed.State().Config.Duration)
+
+	_, err = resumed.step()
+	require.NoError(t, err)
+	require.Equal(t, "4", resumed.State().Filled.String())
+	require.Len(t, orders.quantities(), 1)
+
+	// the time is up, the working child is cancelled and the rest left
+	resumed.now = func() time.Time { return time.Now().Add(time.Hour) }
+	done, err = resumed.step()
+	require.NoError(t, err)
+	require.True(t, done)
+
+	loaded, err := executor.Load("iceberg-1")
+	require.NoError(t, err)
+	require.Equal(t, ExecutionCompleted, loaded.State().Status)
+	require.Equal(t, "26", loaded.State().Remaining().String())
+	require.Equal(t, Cancelled, loaded.State().Children[0].Status)
+
+	_, err = executor.Load("missing")
+	require.ErrorIs(t, err, ErrExecutionNotFound)
+
+	_, err = executor.Load("../escape")
+	require.EqualError(t, err, `invalid execution ID "../escape"`)
+}
+
+func TestExecutionSubmitError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	orders := newExecutionOrders(t)
+	orders.lost = 1
+	store := &MemoryExecutionStore{}
+
+	order := buyAAPL(20, "171.25")
+	order.ExtClientOrderID = "iceberg-2"
+	ex, err := client.NewExecutor(store).Start("5YZ55555", order, ExecutionConfig{
+		Algorithm:       Iceberg,
+		DisplayQuantity: decimal.NewFromInt(10),
+	})
+	require.NoError(t, err)
+
+	// the order is accepted but its response lost, the child is saved pending
+	_, err = ex.step()
+	require.Error(t, err)
+
+	saved, err := store.Load("iceberg-2")
+	require.NoError(t, err)
+	require.Len(t, saved.Children, 1)
+	require.True(t, saved.Children[0].Pending)
+	require.Equal(t, "iceberg-2-1", saved.Children[0].ClientOrderID)
+
+	// a restart finds the order by its client order ID instead of resubmitting
+	resumed, err := client.NewExecutor(store).Load("iceberg-2")
+	require.NoError(t, err)
+	_, err = resumed.step()
+	require.NoError(t, err)
+	require.Len(t, orders.quantities(), 1)
+
+	state := resumed.State()
+	require.False(t, state.Children[0].Pending)
+	require.Equal(t, 1, state.Children[0].OrderID)
+	require.Equal(t, Live, state.Children[0].Status)
+
+	// a submission that never arrived is sent again with the same client order ID
+	orders.fill(1, 10)
+	orders.dropped = 1
+	_, err = resumed.step()
+	require.Error(t, err)
+	require.Len(t, orders.quantities(), 1)
+
+	_, err = resumed.step()
+	require.NoError(t, err)
+	require.Len(t, orders.quantities(), 2)
+	require.Equal(t, "iceberg-2-2", orders.sent[1].ExtClientOrderID)
+	require.Len(t, resumed.State().Children, 2)
+	require.Equal(t, 2, resumed.State().Children[1].OrderID)
+}
+
+func TestExecutionCancel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	orders := newExecutionOrders(t)
+	ex, err := client.NewExecutor(&MemoryExecutionStore{}).Start("5YZ55555", buyAAPL(50, "171.25"), ExecutionConfig{
+		Algorithm: TWAP,
+		Duration:  time.Hour,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 10, ex.State().Config.Slices)
+
+	_, err = ex.step()
+	require.NoError(t, err)
+	orders.fill(1, 2)
+
+	require.NoError(t, ex.Cancel())
+
+	state, err := ex.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ExecutionCancelled, state.Status)
+	require.Equal(t, "2", state.Filled.String())
+	require.Equal(t, Cancelled, state.Children[0].Status)
+	require.ErrorContains(t, ex.Resume(), "can't resume Cancelled execution")
+}
+
+func TestExecutionRejected(t *testing.T) {
+	setup()
+	defer teardown()
+
+	orders := newExecutionOrders(t)
+	orders.reject = "Insufficient buying power"
+
+	ex, err := client.NewExecutor(&MemoryExecutionStore{}).Start("5YZ55555", buyAAPL(50, "171.25"), ExecutionConfig{
+		Algorithm:       Iceberg,
+		DisplayQuantity: decimal.NewFromInt(10),
+	})
+	require.NoError(t, err)
+
+	state, err := ex.Run(context.Background())
+	require.EqualError(t, err, "child order rejected: Insufficient buying power")
+	require.Equal(t, ExecutionFailed, state.Status)
+	require.Equal(t, "Insufficient buying power", state.Error)
+}
+
+func TestExecutionValidation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	executor := client.NewExecutor(&MemoryExecutionStore{})
+
+	twap := ExecutionConfig{Algorithm: TWAP, Duration: time.Hour}
+
+	order := buyAAPL(5, "171.25")
+	ex, err := executor.Start("5YZ55555", order, twap)
+	require.NoError(t, err)
+	require.Equal(t, 5, ex.State().Config.Slices)
+	require.Equal(t, CancelRemainder, ex.State().Config.Remainder)
+	require.Equal(t, 5*time.Second, ex.State().Config.PollInterval)
+
+	order.Legs = append(order.Legs, order.Legs[0])
+	_, err = executor.Start("5YZ55555", order, twap)
+	require.EqualError(t, err, "invalid order: executions work single leg orders")
+
+	order = buyAAPL(5, "171.25")
+	order.Legs[0].Quantity = decimal.RequireFromString("2.5")
+	_, err = executor.Start("5YZ55555", order, twap)
+	require.ErrorIs(t, err, ErrInvalidOrder)
+
+	order = buyAAPL(5, "171.25")
+	order.OrderType = Stop
+	_, err = executor.Start("5YZ55555", order, twap)
+	require.EqualError(t, err, "invalid order: executions work Limit or Market orders")
+
+	for config, expected := range map[*ExecutionConfig]string{
+		{Algorithm: TWAP}:    "TWAP execution requires a duration",
+		{Algorithm: Iceberg}: "iceberg execution requires a positive whole display quantity",
+		{Algorithm: "VWAP"}:  `unknown execution algorithm "VWAP"`,
+		{Algorithm: TWAP, Duration: time.Hour, Remainder: "Leave"}: `unknown remainder policy "Leave"`,
+	} {
+		_, err = executor.Start("5YZ55555", buyAAPL(5, "171.25"), *config)
+		require.EqualError(t, err, expected)
+	}
+}
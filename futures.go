@@ -81,6 +81,13 @@ func (c *Client) GetFutureOptionProduct(exchange, rootSymbol string) (FutureOpti
 	return instrumentRes.FutureOptionProduct, resp, nil
 }
 
+// futureOptionsParams adds the strike price to the query parameters of a
+// FutureOptionsQuery.
+type futureOptionsParams struct {
+	FutureOptionsQuery
+	StrikePrice queryDecimal `url:"strike-price"`
+}
+
 // Returns a set of future option(s) given an array of one or more symbols.
 // Uses TW symbology: [./ESZ9 EW4U9 190927P2975].
 func (c *Client) GetFutureOptions(query FutureOptionsQuery) ([]FutureOption, *http.Response, error) {
@@ -94,7 +101,7 @@ func (c *Client) GetFutureOptions(query FutureOptionsQuery) ([]FutureOption, *ht
 
 	instrumentRes := new(instrumentResponse)
 
-	resp, err := c.request(http.MethodGet, path, query, nil, instrumentRes)
+	resp, err := c.request(http.MethodGet, path, futureOptionsParams{query, queryDecimal(query.StrikePrice)}, nil, instrumentRes)
 	if err != nil {
 		return []FutureOption{}, resp, err
 	}
@@ -395,17 +395,19 @@ func TestGetFutureOptions(t *testing.T) {
 		OptionContractCode: "EW4U9",
 		FutureContractCode: future.Build(),
 		OptionType:         Put,
-		Strike:             2975,
+		Strike:             decimal.NewFromInt(2975),
 		Expiration:         expiry,
 	}
 
 	symbol := fcc.Build()
 
 	query := FutureOptionsQuery{
-		Symbols: []string{symbol},
+		Symbols:     []string{symbol},
+		StrikePrice: decimal.RequireFromString("2975.5"),
 	}
 
 	mux.HandleFunc("/instruments/future-options", func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, "2975.5", request.URL.Query().Get("strike-price"))
 		fmt.Fprint(writer, futureOptionsResp)
 	})
 
@@ -479,7 +481,7 @@ func TestGetFutureOptionsError(t *testing.T) {
 		OptionContractCode: "EW4U9",
 		FutureContractCode: future.Build(),
 		OptionType:         Put,
-		Strike:             2975,
+		Strike:             decimal.NewFromInt(2975),
 		Expiration:         expiry,
 	}
 
@@ -490,6 +492,8 @@ func TestGetFutureOptionsError(t *testing.T) {
 	}
 
 	mux.HandleFunc("/instruments/future-options", func(writer http.ResponseWriter, request *http.Request) {
+		// a zero strike price is omitted
+		require.False(t, request.URL.Query().Has("strike-price"))
 		writer.WriteHeader(401)
 		fmt.Fprint(writer, tastyUnauthorizedError)
 	})
@@ -510,7 +514,7 @@ func TestGetFutureOption(t *testing.T) {
 		OptionContractCode: "EW4U9",
 		FutureContractCode: future.Build(),
 		OptionType:         Put,
-		Strike:             2975,
+		Strike:             decimal.NewFromInt(2975),
 		Expiration:         expiry,
 	}
 
@@ -375,6 +375,6 @@ type FutureOptionsQuery struct {
 	ExpirationDate time.Time `layout:"2006-01-02" url:"expiration-date,omitempty"`
 	// P(ut) or C(all)
 	OptionType OptionType `url:"option-type,omitempty"`
-	// Strike price using display factor
-	StrikePrice float32 `url:"strike-price,omitempty"`
+	// Strike price using display factor, encoded by GetFutureOptions
+	StrikePrice decimal.Decimal `url:"-"`
 }
@@ -0,0 +1,320 @@
+package tasty
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// LiveChainRow is a single option in a LiveChain with its latest market data.
+type LiveChainRow struct {
+	// OCC symbol i.e. AAPL  230616C00060000
+	Symbol string
+	// Streamer symbol i.e. .AAPL230616C60
+	StreamerSymbol string
+	ExpirationDate string
+	// Calendar days from today in US eastern time to the expiration date
+	DaysToExpiration int
+	OptionType       OptionType
+	Strike           decimal.Decimal
+	Bid              decimal.Decimal
+	Ask              decimal.Decimal
+	Mid              decimal.Decimal
+	// Implied volatility
+	IV           decimal.Decimal
+	Delta        decimal.Decimal
+	Gamma        decimal.Decimal
+	Theta        decimal.Decimal
+	Vega         decimal.Decimal
+	OpenInterest decimal.Decimal
+	// When market data for the row was last applied
+	UpdatedAt time.Time
+}
+
+// LiveChain is an in memory option chain kept up to date with streaming
+// quotes, greeks and open interest. It is safe for concurrent use.
+type LiveChain struct {
+	feed       MarketDataFeed
+	underlying string
+	mu         sync.RWMutex
+	rows       map[string]*LiveChainRow
+	occ        map[string]string
+	loc        *time.Location
+	now        func() time.Time
+}
+
+// ExpirationsWithin selects expirations between minDTE and maxDTE days to expiration inclusive.
+func ExpirationsWithin(minDTE, maxDTE int) func(Expiration) bool {
+	return func(exp Expiration) bool {
+		return exp.DaysToExpiration >= minDTE && exp.DaysToExpiration <= maxDTE
+	}
+}
+
+// LoadLiveChain loads the nested equity option chain for the underlying,
+// keeps the expirations selected by include (all when nil) and subscribes
+// them on the feed. The standard chain is used when the underlying has
+// adjusted chains.
+func (c *Client) LoadLiveChain(feed MarketDataFeed, underlying string, include func(Expiration) bool) (*LiveChain, error) {
+	chains, _, err := c.GetNestedEquityOptionChains(underlying)
+	if err != nil {
+		return nil, err
+	}
+
+	var expirations []Expiration
+	if chain, ok := standardChain(chains); ok {
+		for _, exp := range chain.Expirations {
+			if include == nil || include(exp) {
+				expirations = append(expirations, exp)
+			}
+		}
+	}
+
+	return NewLiveChain(feed, underlying, expirations)
+}
+
+// NewLiveChain creates a chain for the expirations and subscribes Quote,
+// Greeks and Summary events for every strike on the feed. The feed may be nil
+// when events are supplied through Apply. It fails when the market time zone
+// can't be loaded.
+func NewLiveChain(feed MarketDataFeed, underlying string, expirations []Expiration) (*LiveChain, error) {
+	loc, err := marketLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	lc := &LiveChain{
+		feed:       feed,
+		underlying: underlying,
+		rows:       map[string]*LiveChainRow{},
+		occ:        map[string]string{},
+		loc:        loc,
+		now:        time.Now,
+	}
+
+	for _, exp := range expirations {
+		for _, strike := range exp.Strikes {
+			lc.addRow(exp, strike, Call, strike.Call, strike.CallStreamerSymbol)
+			lc.addRow(exp, strike, Put, strike.Put, strike.PutStreamerSymbol)
+		}
+	}
+
+	if feed == nil {
+		return lc, nil
+	}
+
+	return lc, feed.Subscribe(lc.subscriptions()...)
+}
+
+// Underlying symbol of the chain.
+func (lc *LiveChain) Underlying() string {
+	return lc.underlying
+}
+
+// Run applies events from the feed until its event channel is closed.
+func (lc *LiveChain) Run() error {
+	if lc.feed == nil {
+		return errNoFeed
+	}
+
+	for event := range lc.feed.Events() {
+		lc.Apply(event)
+	}
+
+	return nil
+}
+
+// Close unsubscribes the chain from the feed.
+func (lc *LiveChain) Close() error {
+	if lc.feed == nil {
+		return nil
+	}
+
+	return lc.feed.Unsubscribe(lc.subscriptions()...)
+}
+
+// Apply updates the chain with a Quote, Greeks or Summary event.
+// Events for symbols outside of the chain are ignored.
+func (lc *LiveChain) Apply(event MarketEvent) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	row, ok := lc.rows[event.Symbol()]
+	if !ok {
+		return
+	}
+
+	switch e := event.(type) {
+	case Quote:
+		row.Bid = e.BidPrice
+		row.Ask = e.AskPrice
+		row.Mid = e.BidPrice.Add(e.AskPrice).Div(decimal.NewFromInt(2))
+	case Greeks:
+		row.IV = e.Volatility
+		row.Delta = e.Delta
+		row.Gamma = e.Gamma
+		row.Theta = e.Theta
+		row.Vega = e.Vega
+	case Summary:
+		row.OpenInterest = e.OpenInterest
+	default:
+		return
+	}
+
+	row.UpdatedAt = lc.now()
+}
+
+// Row returns the row for an OCC or streamer symbol.
+func (lc *LiveChain) Row(symbol string) (LiveChainRow, bool) {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	if streamerSymbol, ok := lc.occ[symbol]; ok {
+		symbol = streamerSymbol
+	}
+
+	row, ok := lc.rows[symbol]
+	if !ok {
+		return LiveChainRow{}, false
+	}
+
+	return lc.snapshot(row), true
+}
+
+// Rows returns a snapshot of every row ordered by expiration, strike and option type.
+func (lc *LiveChain) Rows() []LiveChainRow {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	rows := make([]LiveChainRow, 0, len(lc.rows))
+	for _, row := range lc.rows {
+		rows = append(rows, lc.snapshot(row))
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].ExpirationDate != rows[j].ExpirationDate {
+			return rows[i].ExpirationDate < rows[j].ExpirationDate
+		}
+		if !rows[i].Strike.Equal(rows[j].Strike) {
+			return rows[i].Strike.LessThan(rows[j].Strike)
+		}
+		return rows[i].OptionType < rows[j].OptionType
+	})
+
+	return rows
+}
+
+// Expiration returns the rows of the expiration closest to the target days to expiration.
+// Ties go to the earlier expiration.
+func (lc *LiveChain) Expiration(targetDTE int) []LiveChainRow {
+	rows := lc.Rows()
+
+	closest := -1
+	for _, row := range rows {
+		if closest == -1 || absInt(row.DaysToExpiration-targetDTE) < absInt(closest-targetDTE) {
+			closest = row.DaysToExpiration
+		}
+	}
+
+	var expiration []LiveChainRow
+	for _, row := range rows {
+		if row.DaysToExpiration == closest {
+			expiration = append(expiration, row)
+		}
+	}
+
+	return expiration
+}
+
+// ClosestDelta returns the option of the given type whose delta is closest to
+// the target in the expiration closest to the target days to expiration, i.e.
+// the 16 delta put closest to 45 DTE is ClosestDelta(Put, decimal.RequireFromString("0.16"), 45).
+// The target is compared by absolute value so put deltas may be given as
+// positive numbers. Rows without greeks are skipped.
+func (lc *LiveChain) ClosestDelta(optionType OptionType, delta decimal.Decimal, targetDTE int) (LiveChainRow, bool) {
+	var best LiveChainRow
+	found := false
+
+	for _, row := range lc.Expiration(targetDTE) {
+		if row.OptionType != optionType || row.Delta.IsZero() {
+			continue
+		}
+
+		diff := row.Delta.Abs().Sub(delta.Abs()).Abs()
+		if !found || diff.LessThan(best.Delta.Abs().Sub(delta.Abs()).Abs()) {
+			best = row
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// ClosestStrike returns the option of the given type whose strike is closest to
+// the target in the expiration closest to the target days to expiration.
+func (lc *LiveChain) ClosestStrike(optionType OptionType, strike decimal.Decimal, targetDTE int) (LiveChainRow, bool) {
+	var best LiveChainRow
+	found := false
+
+	for _, row := range lc.Expiration(targetDTE) {
+		if row.OptionType != optionType {
+			continue
+		}
+
+		if !found || row.Strike.Sub(strike).Abs().LessThan(best.Strike.Sub(strike).Abs()) {
+			best = row
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+func (lc *LiveChain) addRow(exp Expiration, strike Strike, optionType OptionType, symbol, streamerSymbol string) {
+	if streamerSymbol == "" {
+		return
+	}
+
+	lc.rows[streamerSymbol] = &LiveChainRow{
+		Symbol:           symbol,
+		StreamerSymbol:   streamerSymbol,
+		ExpirationDate:   exp.ExpirationDate,
+		DaysToExpiration: exp.DaysToExpiration,
+		OptionType:       optionType,
+		Strike:           strike.StrikePrice,
+	}
+	lc.occ[symbol] = streamerSymbol
+}
+
+// snapshot returns a copy of the row with its days to expiration as of now.
+func (lc *LiveChain) snapshot(row *LiveChainRow) LiveChainRow {
+	snapshot := *row
+
+	if expiration, err := time.Parse("2006-01-02", row.ExpirationDate); err == nil {
+		year, month, day := lc.now().In(lc.loc).Date()
+		today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		snapshot.DaysToExpiration = int(expiration.Sub(today).Hours() / 24)
+	}
+
+	return snapshot
+}
+
+func (lc *LiveChain) subscriptions() []FeedSubscription {
+	subs := make([]FeedSubscription, 0, len(lc.rows)*3)
+	for symbol := range lc.rows {
+		subs = append(subs,
+			FeedSubscription{Type: QuoteEvent, Symbol: symbol},
+			FeedSubscription{Type: GreeksEvent, Symbol: symbol},
+			FeedSubscription{Type: SummaryEvent, Symbol: symbol})
+	}
+
+	return subs
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,162 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func testExpirations() []Expiration {
+	return []Expiration{
+		{
+			ExpirationDate:   "2023-09-15",
+			DaysToExpiration: 32,
+			Strikes: []Strike{
+				{StrikePrice: decimal.NewFromInt(440), Call: "SPY   230915C00440000", CallStreamerSymbol: ".SPY230915C440",
+					Put: "SPY   230915P00440000", PutStreamerSymbol: ".SPY230915P440"},
+			},
+		},
+		{
+			ExpirationDate:   "2023-09-29",
+			DaysToExpiration: 46,
+			Strikes: []Strike{
+				{StrikePrice: decimal.NewFromInt(420), Call: "SPY   230929C00420000", CallStreamerSymbol: ".SPY230929C420",
+					Put: "SPY   230929P00420000", PutStreamerSymbol: ".SPY230929P420"},
+				{StrikePrice: decimal.NewFromInt(430), Call: "SPY   230929C00430000", CallStreamerSymbol: ".SPY230929C430",
+					Put: "SPY   230929P00430000", PutStreamerSymbol: ".SPY230929P430"},
+			},
+		},
+	}
+}
+
+func TestLiveChain(t *testing.T) {
+	feed := newFakeFeed()
+
+	chain, err := NewLiveChain(feed, "SPY", testExpirations())
+	require.NoError(t, err)
+	chain.now = func() time.Time { return time.Date(2023, 8, 14, 14, 30, 0, 0, time.UTC) }
+	require.Equal(t, "SPY", chain.Underlying())
+	require.Len(t, feed.subscribed, 18)
+	require.Contains(t, feed.subscribed, FeedSubscription{Type: GreeksEvent, Symbol: ".SPY230929P420"})
+
+	chain.Apply(Quote{EventSymbol: ".SPY230929P420", BidPrice: decimal.RequireFromString("3.10"), AskPrice: decimal.RequireFromString("3.20")})
+	chain.Apply(Greeks{EventSymbol: ".SPY230929P420", Volatility: decimal.RequireFromString("0.18"),
+		Delta: decimal.RequireFromString("-0.15"), Gamma: decimal.RequireFromString("0.01"),
+		Theta: decimal.RequireFromString("-0.05"), Vega: decimal.RequireFromString("0.4")})
+	chain.Apply(Greeks{EventSymbol: ".SPY230929P430", Delta: decimal.RequireFromString("-0.22")})
+	chain.Apply(Greeks{EventSymbol: ".SPY230915P440", Delta: decimal.RequireFromString("-0.16")})
+	chain.Apply(Summary{EventSymbol: ".SPY230929P420", OpenInterest: decimal.NewFromInt(1200)})
+	// ignored
+	chain.Apply(Quote{EventSymbol: "SPY"})
+	chain.Apply(Trade{EventSymbol: ".SPY230929P420"})
+
+	row, ok := chain.Row("SPY   230929P00420000")
+	require.True(t, ok)
+	require.Equal(t, ".SPY230929P420", row.StreamerSymbol)
+	require.Equal(t, "3.15", row.Mid.String())
+	require.Equal(t, "0.18", row.IV.String())
+	require.Equal(t, "1200", row.OpenInterest.String())
+	require.Equal(t, time.Date(2023, 8, 14, 14, 30, 0, 0, time.UTC), row.UpdatedAt)
+
+	_, ok = chain.Row("QQQ")
+	require.False(t, ok)
+
+	// 16 delta put in the expiration closest to 45 DTE
+	row, ok = chain.ClosestDelta(Put, decimal.RequireFromString("0.16"), 45)
+	require.True(t, ok)
+	require.Equal(t, "SPY   230929P00420000", row.Symbol)
+
+	_, ok = chain.ClosestDelta(Call, decimal.RequireFromString("0.16"), 45)
+	require.False(t, ok)
+
+	row, ok = chain.ClosestStrike(Call, decimal.NewFromInt(428), 30)
+	require.True(t, ok)
+	require.Equal(t, "SPY   230915C00440000", row.Symbol)
+
+	require.Len(t, chain.Expiration(39), 2)
+	require.Len(t, chain.Expiration(100), 4)
+
+	// days to expiration follow the calendar, not the load time
+	chain.now = func() time.Time { return time.Date(2023, 9, 2, 1, 0, 0, 0, time.UTC) }
+	row, ok = chain.Row(".SPY230915C440")
+	require.True(t, ok)
+	require.Equal(t, 14, row.DaysToExpiration)
+	require.Len(t, chain.Expiration(27), 4)
+
+	rows := chain.Rows()
+	require.Len(t, rows, 6)
+	require.Equal(t, ".SPY230915C440", rows[0].StreamerSymbol)
+	require.Equal(t, ".SPY230929C420", rows[2].StreamerSymbol)
+	require.Equal(t, ".SPY230929P430", rows[5].StreamerSymbol)
+
+	require.NoError(t, chain.Close())
+	require.Len(t, feed.unsubscribed, 18)
+
+	close(feed.events)
+	require.NoError(t, chain.Run())
+}
+
+func TestLiveChainWithoutFeed(t *testing.T) {
+	chain, err := NewLiveChain(nil, "SPY", testExpirations())
+	require.NoError(t, err)
+	require.ErrorIs(t, chain.Run(), errNoFeed)
+	require.NoError(t, chain.Close())
+}
+
+func TestLoadLiveChain(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/option-chains/AAPL/nested", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, equityOptionChainsNestedResp)
+	})
+
+	feed := newFakeFeed()
+
+	chain, err := client.LoadLiveChain(feed, "AAPL", ExpirationsWithin(0, 7))
+	require.NoError(t, err)
+	require.Len(t, chain.Rows(), 4)
+
+	chain, err = client.LoadLiveChain(feed, "AAPL", ExpirationsWithin(30, 60))
+	require.NoError(t, err)
+	require.Empty(t, chain.Rows())
+}
+
+func TestLoadLiveChainAdjusted(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/option-chains/AAPL/nested", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"items":[
+			{"underlying-symbol":"AAPL","root-symbol":"AAPL1","option-chain-type":"Non-standard","expirations":[
+				{"expiration-date":"2023-06-16","days-to-expiration":4,"strikes":[
+					{"strike-price":"60.0","call":"AAPL1 230616C00060000","call-streamer-symbol":".AAPL1230616C60"}]}]},
+			{"underlying-symbol":"AAPL","root-symbol":"AAPL","option-chain-type":"Standard","expirations":[
+				{"expiration-date":"2023-06-16","days-to-expiration":4,"strikes":[
+					{"strike-price":"60.0","call":"AAPL  230616C00060000","call-streamer-symbol":".AAPL230616C60"}]}]}]}}`)
+	})
+
+	chain, err := client.LoadLiveChain(nil, "AAPL", nil)
+	require.NoError(t, err)
+
+	rows := chain.Rows()
+	require.Len(t, rows, 1)
+	require.Equal(t, ".AAPL230616C60", rows[0].StreamerSymbol)
+}
+
+func TestLoadLiveChainError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/option-chains/AAPL/nested", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.LoadLiveChain(nil, "AAPL", nil)
+	expectedUnauthorized(t, err)
+}
@@ -6,9 +6,9 @@ type PositionEntry struct {
 	InstrumentSymbol    string          `json:"instrument-symbol"`
 	InstrumentType      InstrumentType  `json:"instrument-type"`
 	Quantity            decimal.Decimal `json:"quantity"`
-	AverageOpenPrice    StringToFloat32 `json:"average-open-price"`
+	AverageOpenPrice    StringToDecimal `json:"average-open-price"`
 	ClosePrice          decimal.Decimal `json:"close-price"`
-	FixingPrice         StringToFloat32 `json:"fixing-price"`
+	FixingPrice         StringToDecimal `json:"fixing-price"`
 	StrikePrice         decimal.Decimal `json:"strike-price,omitempty"`
 	OptionType          OptionType      `json:"option-type,omitempty"`
 	DeliverableQuantity decimal.Decimal `json:"deliverable-quantity,omitempty"`
@@ -0,0 +1,144 @@
+package tasty
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// LegQuote is the market of an order leg.
+type LegQuote struct {
+	Bid decimal.Decimal
+	Ask decimal.Decimal
+	// Defaults to the mid of the bid and ask
+	Mark decimal.Decimal
+}
+
+// NetPrice is the net price of one unit of an order, the legs reduced to
+// their lowest ratio, positive for debits and negative for credits.
+type NetPrice struct {
+	// Buying at the ask and selling at the bid
+	Natural decimal.Decimal
+	Mid     decimal.Decimal
+	Mark    decimal.Decimal
+	// Units of the order, the greatest common divisor of the leg quantities
+	Size decimal.Decimal
+}
+
+// NewLegQuote returns the leg quote of a streamed quote.
+func NewLegQuote(quote Quote) LegQuote {
+	return LegQuote{Bid: quote.BidPrice, Ask: quote.AskPrice}
+}
+
+// ReduceLegs returns the legs with their quantities reduced to the lowest
+// ratio and the order size multiplying them back, i.e. legs of 10, 20 and 10
+// reduce to 1, 2 and 1 with a size of 10. Legs without whole quantities are
+// returned as is with a size of 1.
+func ReduceLegs(legs []NewOrderLeg) ([]NewOrderLeg, decimal.Decimal) {
+	size := legsGCD(legs)
+	if size <= 1 {
+		return append([]NewOrderLeg(nil), legs...), decimal.NewFromInt(1)
+	}
+
+	divisor := decimal.NewFromInt(int64(size))
+	reduced := make([]NewOrderLeg, 0, len(legs))
+	for _, leg := range legs {
+		leg.Quantity = leg.Quantity.Div(divisor)
+		reduced = append(reduced, leg)
+	}
+
+	return reduced, divisor
+}
+
+// NetPrices returns the natural, mid and mark net prices of one unit of the
+// legs from their quotes keyed by leg symbol. Legs quoted per contract and per
+// share can't be netted, orders mixing option legs with other legs are
+// rejected.
+func NetPrices(legs []NewOrderLeg, quotes map[string]LegQuote) (NetPrice, error) {
+	if len(legs) == 0 {
+		return NetPrice{}, fmt.Errorf("%w: order requires legs", ErrInvalidOrder)
+	}
+
+	for _, leg := range legs {
+		if isOption(leg.InstrumentType) != isOption(legs[0].InstrumentType) {
+			return NetPrice{}, fmt.Errorf("%w: can't net option legs with %s legs", ErrInvalidOrder, nonOptionType(legs))
+		}
+	}
+
+	reduced, size := ReduceLegs(legs)
+	net := NetPrice{Size: size}
+	two := decimal.NewFromInt(2)
+
+	for _, leg := range reduced {
+		if !leg.Quantity.IsPositive() {
+			return NetPrice{}, fmt.Errorf("%w: leg %s requires a quantity", ErrInvalidOrder, leg.Symbol)
+		}
+
+		quote, ok := quotes[leg.Symbol]
+		if !ok {
+			return NetPrice{}, fmt.Errorf("no quote for leg %s", leg.Symbol)
+		}
+
+		mid := quote.Bid.Add(quote.Ask).Div(two)
+		mark := quote.Mark
+		if mark.IsZero() {
+			mark = mid
+		}
+
+		natural := quote.Ask
+		if isSellAction(leg.Action) {
+			natural = quote.Bid
+		}
+
+		sign := legQuantity(leg)
+		net.Natural = net.Natural.Add(natural.Mul(sign))
+		net.Mid = net.Mid.Add(mid.Mul(sign))
+		net.Mark = net.Mark.Add(mark.Mul(sign))
+	}
+
+	return net, nil
+}
+
+// PriceEffect returns the effect of the mid price, Debit when paying and
+// Credit when receiving. An even mid falls back to the natural price and None
+// is returned when both are even.
+func (np NetPrice) PriceEffect() PriceEffect {
+	for _, price := range []decimal.Decimal{np.Mid, np.Natural} {
+		switch price.Sign() {
+		case 1:
+			return Debit
+		case -1:
+			return Credit
+		}
+	}
+
+	return None
+}
+
+// PricedAt returns the order priced at the net price, its price effect
+// inferred from the sign of the price. An even price keeps the order's price
+// effect.
+func (o NewOrder) PricedAt(net decimal.Decimal) NewOrder {
+	o.Price = net.Abs()
+
+	switch net.Sign() {
+	case 1:
+		o.PriceEffect = Debit
+	case -1:
+		o.PriceEffect = Credit
+	}
+
+	return o
+}
+
+// nonOptionType returns the instrument type of the first leg that isn't an
+// option.
+func nonOptionType(legs []NewOrderLeg) InstrumentType {
+	for _, leg := range legs {
+		if !isOption(leg.InstrumentType) {
+			return leg.InstrumentType
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,114 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func legQuote(bid, ask string) LegQuote {
+	return LegQuote{Bid: decimal.RequireFromString(bid), Ask: decimal.RequireFromString(ask)}
+}
+
+func TestNetPricesIronCondor(t *testing.T) {
+	legs := []NewOrderLeg{
+		describeOption("SPY   230915P00400000", 5, BTO),
+		describeOption("SPY   230915P00410000", 5, STO),
+		describeOption("SPY   230915C00450000", 5, STO),
+		describeOption("SPY   230915C00460000", 5, BTO),
+	}
+	quotes := map[string]LegQuote{
+		"SPY   230915P00400000": legQuote("0.80", "0.90"),
+		"SPY   230915P00410000": legQuote("1.40", "1.50"),
+		"SPY   230915C00450000": legQuote("1.10", "1.20"),
+		"SPY   230915C00460000": legQuote("0.45", "0.55"),
+	}
+
+	net, err := NetPrices(legs, quotes)
+	require.NoError(t, err)
+	require.Equal(t, "-1.25", net.Mid.String())
+	require.Equal(t, "-1.05", net.Natural.String())
+	require.Equal(t, "-1.25", net.Mark.String())
+	require.Equal(t, "5", net.Size.String())
+	require.Equal(t, Credit, net.PriceEffect())
+
+	order := NewOrder{TimeInForce: Day, OrderType: Limit, Legs: legs}.PricedAt(net.Mid)
+	require.Equal(t, "1.25", order.Price.String())
+	require.Equal(t, Credit, order.PriceEffect)
+	require.Equal(t, "STO 5 SPY Sep15'23 400/410/450/460 Iron Condor @ 1.25 Credit Day", order.String())
+
+	// marks replace the mid when known
+	quotes["SPY   230915P00410000"] = LegQuote{Bid: decimal.RequireFromString("1.40"), Ask: decimal.RequireFromString("1.50"), Mark: decimal.RequireFromString("1.42")}
+	net, err = NetPrices(legs, quotes)
+	require.NoError(t, err)
+	require.Equal(t, "-1.22", net.Mark.String())
+	require.Equal(t, "-1.25", net.Mid.String())
+}
+
+func TestNetPricesRatio(t *testing.T) {
+	butterfly := []NewOrderLeg{
+		describeOption("SPY   230915C00410000", 10, BTO),
+		describeOption("SPY   230915C00420000", 20, STO),
+		describeOption("SPY   230915C00430000", 10, BTO),
+	}
+
+	reduced, size := ReduceLegs(butterfly)
+	require.Equal(t, "10", size.String())
+	require.Equal(t, "1", reduced[0].Quantity.String())
+	require.Equal(t, "2", reduced[1].Quantity.String())
+	require.Equal(t, "1", reduced[2].Quantity.String())
+	require.Equal(t, "10", butterfly[0].Quantity.String())
+
+	net, err := NetPrices(butterfly, map[string]LegQuote{
+		"SPY   230915C00410000": legQuote("12.10", "12.30"),
+		"SPY   230915C00420000": legQuote("5.60", "5.70"),
+		"SPY   230915C00430000": legQuote("1.50", "1.60"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "2.45", net.Mid.String())
+	require.Equal(t, "2.7", net.Natural.String())
+	require.Equal(t, Debit, net.PriceEffect())
+
+	crypto := []NewOrderLeg{describeLeg(Crypto, "BTC/USD", 0, Buy)}
+	crypto[0].Quantity = decimal.RequireFromString("0.5")
+	reduced, size = ReduceLegs(crypto)
+	require.Equal(t, "1", size.String())
+	require.Equal(t, "0.5", reduced[0].Quantity.String())
+}
+
+func TestNetPriceEffect(t *testing.T) {
+	require.Equal(t, Debit, NetPrice{Natural: decimal.RequireFromString("0.05")}.PriceEffect())
+	require.Equal(t, Credit, NetPrice{Mid: decimal.RequireFromString("-0.01"), Natural: decimal.RequireFromString("0.05")}.PriceEffect())
+	require.Equal(t, None, NetPrice{}.PriceEffect())
+
+	order := NewOrder{PriceEffect: Credit, Price: decimal.NewFromInt(1)}.PricedAt(decimal.RequireFromString("0.35"))
+	require.Equal(t, Debit, order.PriceEffect)
+	require.Equal(t, "0.35", order.Price.String())
+
+	order = order.PricedAt(decimal.Zero)
+	require.Equal(t, Debit, order.PriceEffect)
+	require.True(t, order.Price.IsZero())
+}
+
+func TestNetPricesErrors(t *testing.T) {
+	_, err := NetPrices(nil, nil)
+	require.EqualError(t, err, "invalid order: order requires legs")
+
+	legs := []NewOrderLeg{describeOption("SPY   230915C00410000", 1, BTO)}
+	_, err = NetPrices(legs, map[string]LegQuote{})
+	require.EqualError(t, err, "no quote for leg SPY   230915C00410000")
+
+	legs[0].Quantity = decimal.Zero
+	_, err = NetPrices(legs, map[string]LegQuote{"SPY   230915C00410000": legQuote("1", "1.1")})
+	require.ErrorIs(t, err, ErrInvalidOrder)
+
+	// a covered call is quoted per share and per contract
+	coveredCall := []NewOrderLeg{describeLeg(EquityIT, "SPY", 100, Buy), describeOption("SPY   230915C00450000", 1, STO)}
+	_, err = NetPrices(coveredCall, map[string]LegQuote{"SPY": legQuote("440", "440.1"), "SPY   230915C00450000": legQuote("1.1", "1.2")})
+	require.ErrorIs(t, err, ErrInvalidOrder)
+	require.EqualError(t, err, "invalid order: can't net option legs with Equity legs")
+
+	require.Equal(t, LegQuote{Bid: decimal.NewFromInt(1), Ask: decimal.NewFromInt(2)},
+		NewLegQuote(Quote{EventSymbol: "SPY", BidPrice: decimal.NewFromInt(1), AskPrice: decimal.NewFromInt(2)}))
+}
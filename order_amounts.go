@@ -0,0 +1,101 @@
+package tasty
+
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
+// DecimalFromFloat32 converts a float32 price or quantity of callers not yet
+// migrated to decimal using the shortest decimal that represents it, so 1.15
+// stays 1.15 rather than 1.149999976.
+//
+// Deprecated: build prices and quantities with decimal.New,
+// decimal.NewFromInt or decimal.RequireFromString instead.
+func DecimalFromFloat32(f float32) decimal.Decimal {
+	return decimal.NewFromFloat32(f)
+}
+
+// MarshalJSON sends the prices as exact JSON numbers and omits unset ones.
+func (o NewOrder) MarshalJSON() ([]byte, error) {
+	type order NewOrder
+
+	return json.Marshal(struct {
+		order
+		StopTrigger *json.Number `json:"stop-trigger,omitempty"`
+		Price       *json.Number `json:"price,omitempty"`
+		Value       *json.Number `json:"value,omitempty"`
+	}{
+		order:       order(o),
+		StopTrigger: jsonAmount(o.StopTrigger),
+		Price:       jsonAmount(o.Price),
+		Value:       jsonAmount(o.Value),
+	})
+}
+
+// MarshalJSON sends the prices as exact JSON numbers and omits unset ones.
+func (o NewOrderECR) MarshalJSON() ([]byte, error) {
+	type order NewOrderECR
+
+	return json.Marshal(struct {
+		order
+		StopTrigger *json.Number `json:"stop-trigger,omitempty"`
+		Price       *json.Number `json:"price,omitempty"`
+		Value       *json.Number `json:"value,omitempty"`
+	}{
+		order:       order(o),
+		StopTrigger: jsonAmount(o.StopTrigger),
+		Price:       jsonAmount(o.Price),
+		Value:       jsonAmount(o.Value),
+	})
+}
+
+// MarshalJSON sends the quantity as an exact JSON number and omits it when
+// unset i.e. for notional market orders.
+func (l NewOrderLeg) MarshalJSON() ([]byte, error) {
+	type leg NewOrderLeg
+
+	return json.Marshal(struct {
+		leg
+		Quantity *json.Number `json:"quantity,omitempty"`
+	}{
+		leg:      leg(l),
+		Quantity: jsonAmount(l.Quantity),
+	})
+}
+
+// MarshalJSON sends the threshold as an exact JSON number like order prices.
+func (c NewOrderCondition) MarshalJSON() ([]byte, error) {
+	type condition NewOrderCondition
+
+	return json.Marshal(struct {
+		condition
+		Threshold json.Number `json:"threshold"`
+	}{
+		condition: condition(c),
+		Threshold: json.Number(c.Threshold.String()),
+	})
+}
+
+// MarshalJSON sends the quantity as an exact JSON number like leg quantities.
+func (pc NewOrderPriceComponent) MarshalJSON() ([]byte, error) {
+	type component NewOrderPriceComponent
+
+	return json.Marshal(struct {
+		component
+		Quantity json.Number `json:"quantity"`
+	}{
+		component: component(pc),
+		Quantity:  json.Number(pc.Quantity.String()),
+	})
+}
+
+// jsonAmount formats the decimal as a JSON number, nil when zero.
+func jsonAmount(d decimal.Decimal) *json.Number {
+	if d.IsZero() {
+		return nil
+	}
+
+	n := json.Number(d.String())
+	return &n
+}
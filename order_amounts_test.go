@@ -0,0 +1,99 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOrderMarshalJSON(t *testing.T) {
+	order := NewOrder{
+		TimeInForce: Day,
+		OrderType:   Limit,
+		Price:       decimal.RequireFromString("1.15"),
+		PriceEffect: Debit,
+		Legs: []NewOrderLeg{
+			{InstrumentType: EquityOptionIT, Symbol: "AAPL  230818C00185000", Quantity: decimal.NewFromInt(2), Action: BTO},
+		},
+	}
+
+	b, err := json.Marshal(order)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"time-in-force": "Day",
+		"gtc-date": "",
+		"order-type": "Limit",
+		"price": 1.15,
+		"price-effect": "Debit",
+		"legs": [{"instrument-type": "Equity Option", "symbol": "AAPL  230818C00185000", "quantity": 2, "action": "Buy to Open"}],
+		"rules": {}
+	}`, string(b))
+
+	var decoded NewOrder
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, "1.15", decoded.Price.String())
+	require.Equal(t, "2", decoded.Legs[0].Quantity.String())
+
+	notional := NewOrder{
+		TimeInForce: Day,
+		OrderType:   NotionalMarket,
+		Value:       decimal.RequireFromString("10.00"),
+		ValueEffect: Debit,
+		Legs:        []NewOrderLeg{{InstrumentType: EquityIT, Symbol: "AAPL", Action: Buy}},
+	}
+
+	b, err = json.Marshal(notional)
+	require.NoError(t, err)
+
+	var sent map[string]any
+	require.NoError(t, json.Unmarshal(b, &sent))
+	require.NotContains(t, sent, "price")
+	require.NotContains(t, sent, "stop-trigger")
+	require.Equal(t, 10.0, sent["value"])
+	require.NotContains(t, sent["legs"].([]any)[0], "quantity")
+}
+
+func TestNewOrderECRMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(NewOrderECR{
+		TimeInForce: GTC,
+		OrderType:   StopLimit,
+		StopTrigger: decimal.RequireFromString("180.05"),
+		Price:       decimal.RequireFromString("179.95"),
+		PriceEffect: Debit,
+	})
+	require.NoError(t, err)
+
+	var sent map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(b, &sent))
+	require.Equal(t, "180.05", string(sent["stop-trigger"]))
+	require.Equal(t, "179.95", string(sent["price"]))
+	require.NotContains(t, sent, "value")
+	require.NotContains(t, sent, "legs")
+}
+
+func TestNewOrderConditionMarshalJSON(t *testing.T) {
+	rules, err := NewRuleBuilder().RouteWhen("SPY", GTE, decimal.RequireFromString("450.25")).Build()
+	require.NoError(t, err)
+
+	b, err := json.Marshal(rules.Conditions[0])
+	require.NoError(t, err)
+
+	var sent map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(b, &sent))
+	require.Equal(t, "450.25", string(sent["threshold"]))
+	require.JSONEq(t, `[{"symbol":"SPY","instrument-type":"Equity","quantity":1,"quantity-direction":"Long"}]`,
+		string(sent["price-components"]))
+
+	var decoded NewOrderCondition
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, "450.25", decoded.Threshold.String())
+	require.Equal(t, "1", decoded.PriceComponents[0].Quantity.String())
+}
+
+func TestDecimalFromFloat32(t *testing.T) {
+	require.Equal(t, "1.15", DecimalFromFloat32(1.15).String())
+	require.Equal(t, "124.55", DecimalFromFloat32(124.55).String())
+	require.Equal(t, "0.5", DecimalFromFloat32(0.5).String())
+}
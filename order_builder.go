@@ -0,0 +1,381 @@
+package tasty
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Maximum number of legs in a single order.
+const maxOrderLegs = 4
+
+// ErrInvalidOrder is returned by ValidateOrder and OrderBuilder.Build when the
+// order fails local validation.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// OrderBuilder builds a NewOrder with chained calls and validates it before
+// it is submitted i.e.
+//
+//	order, err := tasty.NewOrderBuilder().
+//		SellToOpen("AAPL  230818P00170000", decimal.NewFromInt(1)).
+//		Limit(decimal.RequireFromString("1.25")).
+//		Credit().
+//		GTC().
+//		Build()
+//
+// Orders default to Day Market orders.
+type OrderBuilder struct {
+	order  NewOrder
+	effect PriceEffect
+	gtd    time.Time
+	ticks  *Ticks
+}
+
+// NewOrderBuilder creates a builder for a Day Market order.
+func NewOrderBuilder() *OrderBuilder {
+	return &OrderBuilder{
+		order: NewOrder{TimeInForce: Day, OrderType: Market},
+	}
+}
+
+// BuyToOpen adds a Buy to Open leg for an option symbol.
+func (b *OrderBuilder) BuyToOpen(symbol string, quantity decimal.Decimal) *OrderBuilder {
+	return b.Leg(InstrumentTypeOf(symbol), symbol, quantity, BTO)
+}
+
+// SellToOpen adds a Sell to Open leg for an option symbol.
+func (b *OrderBuilder) SellToOpen(symbol string, quantity decimal.Decimal) *OrderBuilder {
+	return b.Leg(InstrumentTypeOf(symbol), symbol, quantity, STO)
+}
+
+// BuyToClose adds a Buy to Close leg for an option symbol.
+func (b *OrderBuilder) BuyToClose(symbol string, quantity decimal.Decimal) *OrderBuilder {
+	return b.Leg(InstrumentTypeOf(symbol), symbol, quantity, BTC)
+}
+
+// SellToClose adds a Sell to Close leg for an option symbol.
+func (b *OrderBuilder) SellToClose(symbol string, quantity decimal.Decimal) *OrderBuilder {
+	return b.Leg(InstrumentTypeOf(symbol), symbol, quantity, STC)
+}
+
+// Buy adds a Buy leg for an equity, future or cryptocurrency symbol.
+func (b *OrderBuilder) Buy(symbol string, quantity decimal.Decimal) *OrderBuilder {
+	return b.Leg(InstrumentTypeOf(symbol), symbol, quantity, Buy)
+}
+
+// Sell adds a Sell leg for an equity, future or cryptocurrency symbol.
+func (b *OrderBuilder) Sell(symbol string, quantity decimal.Decimal) *OrderBuilder {
+	return b.Leg(InstrumentTypeOf(symbol), symbol, quantity, Sell)
+}
+
+// Leg adds a leg with an explicit instrument type.
+func (b *OrderBuilder) Leg(instrumentType InstrumentType, symbol string, quantity decimal.Decimal, action OrderAction) *OrderBuilder {
+	b.order.Legs = append(b.order.Legs, NewOrderLeg{
+		InstrumentType: instrumentType,
+		Symbol:         symbol,
+		Quantity:       quantity,
+		Action:         action,
+	})
+
+	return b
+}
+
+// Market makes the order a Market order.
+func (b *OrderBuilder) Market() *OrderBuilder {
+	b.order.OrderType = Market
+	b.order.Price = decimal.Zero
+	b.order.StopTrigger = decimal.Zero
+
+	return b
+}
+
+// Limit makes the order a Limit order at the price.
+func (b *OrderBuilder) Limit(price decimal.Decimal) *OrderBuilder {
+	b.order.OrderType = Limit
+	b.order.Price = price
+
+	return b
+}
+
+// MarketableLimit makes the order a Marketable Limit order at the price.
+func (b *OrderBuilder) MarketableLimit(price decimal.Decimal) *OrderBuilder {
+	b.order.OrderType = MarketableLimit
+	b.order.Price = price
+
+	return b
+}
+
+// Stop makes the order a Stop order triggered at the stop price.
+func (b *OrderBuilder) Stop(trigger decimal.Decimal) *OrderBuilder {
+	b.order.OrderType = Stop
+	b.order.StopTrigger = trigger
+
+	return b
+}
+
+// StopLimit makes the order a Stop Limit order triggered at the stop price
+// with a limit at the price.
+func (b *OrderBuilder) StopLimit(trigger, price decimal.Decimal) *OrderBuilder {
+	b.order.OrderType = StopLimit
+	b.order.StopTrigger = trigger
+	b.order.Price = price
+
+	return b
+}
+
+// NotionalMarket makes the order a Notional Market order for the dollar value.
+func (b *OrderBuilder) NotionalMarket(value decimal.Decimal) *OrderBuilder {
+	b.order.OrderType = NotionalMarket
+	b.order.Value = value
+
+	return b
+}
+
+// Credit receives payment for the order.
+func (b *OrderBuilder) Credit() *OrderBuilder {
+	b.effect = Credit
+	return b
+}
+
+// Debit pays for the order.
+func (b *OrderBuilder) Debit() *OrderBuilder {
+	b.effect = Debit
+	return b
+}
+
+// Day makes the order good for the day.
+func (b *OrderBuilder) Day() *OrderBuilder {
+	return b.timeInForce(Day)
+}
+
+// GTC makes the order good till cancelled.
+func (b *OrderBuilder) GTC() *OrderBuilder {
+	return b.timeInForce(GTC)
+}
+
+// GTD makes the order good till the date.
+func (b *OrderBuilder) GTD(date time.Time) *OrderBuilder {
+	b.timeInForce(GTD)
+	b.gtd = date
+
+	return b
+}
+
+// Ext makes the order good for the day including extended hours.
+func (b *OrderBuilder) Ext() *OrderBuilder {
+	return b.timeInForce(Ext)
+}
+
+// GTCExt makes the order good till cancelled including extended hours.
+func (b *OrderBuilder) GTCExt() *OrderBuilder {
+	return b.timeInForce(GTCExt)
+}
+
+// IOC makes the order immediate or cancel.
+func (b *OrderBuilder) IOC() *OrderBuilder {
+	return b.timeInForce(IOC)
+}
+
+// Rules sets the routing and cancellation rules of the order.
+func (b *OrderBuilder) Rules(rules NewOrderRules) *OrderBuilder {
+	b.order.Rules = rules
+	return b
+}
+
+// Source sets the source of the order.
+func (b *OrderBuilder) Source(source string) *OrderBuilder {
+	b.order.Source = source
+	return b
+}
+
+// PartitionKey sets the partition key of the order.
+func (b *OrderBuilder) PartitionKey(key string) *OrderBuilder {
+	b.order.PartitionKey = key
+	return b
+}
+
+// Ticks rejects prices and stop triggers off the tick schedule when the
+// order is built.
+func (b *OrderBuilder) Ticks(ticks Ticks) *OrderBuilder {
+	b.ticks = &ticks
+	return b
+}
+
+// Build validates and returns the order. Every validation failure is
+// returned joined together, each wrapping ErrInvalidOrder.
+func (b *OrderBuilder) Build() (NewOrder, error) {
+	order := b.order
+	order.Legs = append([]NewOrderLeg(nil), b.order.Legs...)
+
+	if order.OrderType == NotionalMarket {
+		order.ValueEffect = b.effect
+	} else {
+		order.PriceEffect = b.effect
+	}
+
+	if order.TimeInForce == GTD && !b.gtd.IsZero() {
+		order.GtcDate = b.gtd.Format("2006-01-02")
+	}
+
+	err := ValidateOrder(order)
+	if b.ticks != nil {
+		if tickErr := b.ticks.ValidateOrder(order); tickErr != nil {
+			err = errors.Join(err, fmt.Errorf("%w: %w", ErrInvalidOrder, tickErr))
+		}
+	}
+
+	if err != nil {
+		return NewOrder{}, err
+	}
+
+	return order, nil
+}
+
+func (b *OrderBuilder) timeInForce(tif TimeInForce) *OrderBuilder {
+	b.order.TimeInForce = tif
+	b.gtd = time.Time{}
+	b.order.GtcDate = ""
+
+	return b
+}
+
+// ValidateOrder checks the order for mistakes the API would reject in
+// preflight: the number of legs, the actions allowed per instrument type,
+// the price fields required by the order type, the GTD date and the rules.
+func ValidateOrder(order NewOrder) error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidOrder}, args...)...))
+	}
+
+	switch {
+	case len(order.Legs) == 0:
+		invalid("at least one leg is required")
+	case len(order.Legs) > maxOrderLegs:
+		invalid("%d legs exceeds the maximum of %d", len(order.Legs), maxOrderLegs)
+	}
+
+	seen := map[string]bool{}
+	crypto := false
+	for _, leg := range order.Legs {
+		if leg.Symbol == "" {
+			invalid("leg symbol is required")
+		}
+		if seen[leg.Symbol] {
+			invalid("duplicate leg %s", leg.Symbol)
+		}
+		seen[leg.Symbol] = true
+
+		if !leg.Quantity.IsPositive() {
+			invalid("leg %s quantity must be positive", leg.Symbol)
+		}
+
+		if !actionAllowed(leg.InstrumentType, leg.Action) {
+			invalid("%s is not allowed for %s leg %s", leg.Action, leg.InstrumentType, leg.Symbol)
+		}
+
+		crypto = crypto || leg.InstrumentType == Crypto
+	}
+
+	if crypto && len(order.Legs) > 1 {
+		invalid("cryptocurrency orders must have a single leg")
+	}
+
+	switch order.OrderType {
+	case Limit, MarketableLimit:
+		if !order.Price.IsPositive() {
+			invalid("%s orders require a price", order.OrderType)
+		}
+	case StopLimit:
+		if !order.Price.IsPositive() {
+			invalid("%s orders require a price", order.OrderType)
+		}
+		if !order.StopTrigger.IsPositive() {
+			invalid("%s orders require a stop trigger", order.OrderType)
+		}
+	case Stop:
+		if !order.StopTrigger.IsPositive() {
+			invalid("%s orders require a stop trigger", order.OrderType)
+		}
+	case NotionalMarket:
+		if !order.Value.IsPositive() {
+			invalid("%s orders require a value", order.OrderType)
+		}
+		if order.ValueEffect != Credit && order.ValueEffect != Debit {
+			invalid("%s orders require a value effect", order.OrderType)
+		}
+		if len(order.Legs) > 1 {
+			invalid("%s orders must have a single leg", order.OrderType)
+		}
+		for _, leg := range order.Legs {
+			if leg.InstrumentType != EquityIT && leg.InstrumentType != Crypto {
+				invalid("%s orders are not allowed for %s", order.OrderType, leg.InstrumentType)
+			}
+		}
+	case Market:
+	default:
+		invalid("unknown order type %q", order.OrderType)
+	}
+
+	if order.OrderType != NotionalMarket && order.Price.IsPositive() && order.PriceEffect != Credit && order.PriceEffect != Debit {
+		invalid("priced orders require a Credit or Debit price effect")
+	}
+
+	if err := order.Rules.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %w", ErrInvalidOrder, err))
+	}
+
+	switch order.TimeInForce {
+	case GTD:
+		if order.GtcDate == "" {
+			invalid("GTD orders require a date")
+		} else if _, err := time.Parse("2006-01-02", order.GtcDate); err != nil {
+			invalid("GTD date %q must be formatted as 2006-01-02", order.GtcDate)
+		}
+	case Day, GTC, Ext, GTCExt, IOC:
+		if order.GtcDate != "" {
+			invalid("a date is only allowed for GTD orders")
+		}
+	default:
+		invalid("unknown time in force %q", order.TimeInForce)
+	}
+
+	return errors.Join(errs...)
+}
+
+// InstrumentTypeOf infers the instrument type of a symbol from its format:
+// ./ESZ3 EW4U3 230927P4340 is a Future Option, /ESZ3 a Future, an OCC symbol
+// an Equity Option, BTC/USD a Cryptocurrency and anything else an Equity.
+func InstrumentTypeOf(symbol string) InstrumentType {
+	switch {
+	case strings.HasPrefix(symbol, "./"):
+		return FutureOptionIT
+	case strings.HasPrefix(symbol, "/"):
+		return FutureIT
+	case strings.HasSuffix(symbol, "/USD"):
+		return Crypto
+	}
+
+	if _, err := NewOCCFromString(symbol); err == nil {
+		return EquityOptionIT
+	}
+
+	return EquityIT
+}
+
+// actionAllowed returns whether or not the action can be used for the instrument type.
+// Options are opened and closed while equities, futures and cryptocurrencies
+// are bought and sold.
+func actionAllowed(instrumentType InstrumentType, action OrderAction) bool {
+	switch instrumentType {
+	case EquityOptionIT, FutureOptionIT:
+		return action == BTO || action == STO || action == BTC || action == STC
+	case EquityIT, FutureIT, Crypto:
+		return action == Buy || action == Sell
+	default:
+		return action != ""
+	}
+}
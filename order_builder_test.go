@@ -0,0 +1,121 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderBuilder(t *testing.T) {
+	order, err := NewOrderBuilder().
+		SellToOpen("AAPL  230818P00170000", decimal.NewFromInt(1)).
+		BuyToOpen("AAPL  230818P00165000", decimal.NewFromInt(1)).
+		Limit(decimal.RequireFromString("1.25")).
+		Credit().
+		GTD(time.Date(2023, 8, 18, 0, 0, 0, 0, time.UTC)).
+		Source("builder").
+		Build()
+	require.NoError(t, err)
+
+	require.Equal(t, NewOrder{
+		TimeInForce: GTD,
+		GtcDate:     "2023-08-18",
+		OrderType:   Limit,
+		Price:       decimal.RequireFromString("1.25"),
+		PriceEffect: Credit,
+		Source:      "builder",
+		Legs: []NewOrderLeg{
+			{InstrumentType: EquityOptionIT, Symbol: "AAPL  230818P00170000", Quantity: decimal.NewFromInt(1), Action: STO},
+			{InstrumentType: EquityOptionIT, Symbol: "AAPL  230818P00165000", Quantity: decimal.NewFromInt(1), Action: BTO},
+		},
+	}, order)
+}
+
+func TestOrderBuilderDefaults(t *testing.T) {
+	order, err := NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(10)).Build()
+	require.NoError(t, err)
+	require.Equal(t, Day, order.TimeInForce)
+	require.Equal(t, Market, order.OrderType)
+	require.Empty(t, order.PriceEffect)
+
+	// switching time in force drops the GTD date
+	order, err = NewOrderBuilder().Buy("/ESZ3", decimal.NewFromInt(1)).GTD(time.Now()).GTC().Build()
+	require.NoError(t, err)
+	require.Equal(t, GTC, order.TimeInForce)
+	require.Empty(t, order.GtcDate)
+	require.Equal(t, FutureIT, order.Legs[0].InstrumentType)
+}
+
+func TestOrderBuilderNotionalMarket(t *testing.T) {
+	order, err := NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(1)).NotionalMarket(decimal.NewFromInt(100)).Debit().Build()
+	require.NoError(t, err)
+	require.Equal(t, NotionalMarket, order.OrderType)
+	require.Equal(t, "100", order.Value.String())
+	require.Equal(t, Debit, order.ValueEffect)
+	require.Empty(t, order.PriceEffect)
+
+	_, err = NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(1)).NotionalMarket(decimal.NewFromInt(100)).Build()
+	require.EqualError(t, err, "invalid order: Notional Market orders require a value effect")
+
+	_, err = NewOrderBuilder().BuyToOpen("AAPL  230818P00170000", decimal.NewFromInt(1)).NotionalMarket(decimal.NewFromInt(0)).Debit().Build()
+	require.EqualError(t, err, "invalid order: Notional Market orders require a value\n"+
+		"invalid order: Notional Market orders are not allowed for Equity Option")
+}
+
+func TestOrderBuilderValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *OrderBuilder
+		err     string
+	}{
+		{"no legs", NewOrderBuilder(), "at least one leg is required"},
+		{"too many legs", NewOrderBuilder().
+			BuyToOpen("SPY   230818P00400000", decimal.NewFromInt(1)).SellToOpen("SPY   230818P00410000", decimal.NewFromInt(1)).
+			SellToOpen("SPY   230818C00450000", decimal.NewFromInt(1)).BuyToOpen("SPY   230818C00460000", decimal.NewFromInt(1)).
+			BuyToOpen("SPY   230818C00470000", decimal.NewFromInt(1)).Limit(decimal.NewFromInt(1)).Credit(),
+			"5 legs exceeds the maximum of 4"},
+		{"duplicate leg", NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(1)).Sell("AAPL", decimal.NewFromInt(1)), "duplicate leg AAPL"},
+		{"quantity", NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(0)), "leg AAPL quantity must be positive"},
+		{"equity open", NewOrderBuilder().BuyToOpen("AAPL", decimal.NewFromInt(1)), "Buy to Open is not allowed for Equity leg AAPL"},
+		{"option buy", NewOrderBuilder().Buy("AAPL  230818P00170000", decimal.NewFromInt(1)),
+			"Buy is not allowed for Equity Option leg AAPL  230818P00170000"},
+		{"crypto legs", NewOrderBuilder().Buy("BTC/USD", decimal.NewFromInt(1)).Sell("ETH/USD", decimal.NewFromInt(1)),
+			"cryptocurrency orders must have a single leg"},
+		{"limit price", NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(1)).Limit(decimal.NewFromInt(0)).Debit(), "Limit orders require a price"},
+		{"limit effect", NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(1)).Limit(decimal.NewFromInt(150)), "priced orders require a Credit or Debit price effect"},
+		{"stop limit", NewOrderBuilder().Sell("AAPL", decimal.NewFromInt(1)).StopLimit(decimal.Zero, decimal.Zero),
+			"Stop Limit orders require a price\ninvalid order: Stop Limit orders require a stop trigger"},
+		{"stop", NewOrderBuilder().Sell("AAPL", decimal.NewFromInt(1)).Stop(decimal.NewFromInt(0)), "Stop orders require a stop trigger"},
+		{"gtd date", NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(1)).GTD(time.Time{}), "GTD orders require a date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			require.ErrorIs(t, err, ErrInvalidOrder)
+			require.EqualError(t, err, "invalid order: "+tt.err)
+		})
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	err := ValidateOrder(NewOrder{TimeInForce: GTD, GtcDate: "08/18/2023", OrderType: Market,
+		Legs: []NewOrderLeg{{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Action: Buy}}})
+	require.EqualError(t, err, `invalid order: GTD date "08/18/2023" must be formatted as 2006-01-02`)
+
+	err = ValidateOrder(NewOrder{TimeInForce: GTC, GtcDate: "2023-08-18", OrderType: "Bracket",
+		Legs: []NewOrderLeg{{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Action: Buy}}})
+	require.True(t, errors.Is(err, ErrInvalidOrder))
+	require.EqualError(t, err, "invalid order: unknown order type \"Bracket\"\ninvalid order: a date is only allowed for GTD orders")
+}
+
+func TestInstrumentTypeOf(t *testing.T) {
+	require.Equal(t, EquityIT, InstrumentTypeOf("AAPL"))
+	require.Equal(t, EquityOptionIT, InstrumentTypeOf("AAPL  230818C00185000"))
+	require.Equal(t, FutureIT, InstrumentTypeOf("/ESZ3"))
+	require.Equal(t, FutureOptionIT, InstrumentTypeOf("./ESZ3 EW4U3 230927P4340"))
+	require.Equal(t, Crypto, InstrumentTypeOf("BTC/USD"))
+}
@@ -0,0 +1,517 @@
+package tasty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const defaultChaseInterval = 30 * time.Second
+
+// ChaseConfig configures an OrderChaser.
+type ChaseConfig struct {
+	// Worst price the order may be walked to, a debit never above it and a
+	// credit never below it. Required.
+	WorstPrice decimal.Decimal
+	// How often the price is walked toward the natural price.
+	// Defaults to 30 seconds.
+	Interval time.Duration
+	// Ticks moved each step. Defaults to 1.
+	StepTicks int
+	// How long to chase before giving up, 0 to chase until the context is done.
+	Timeout time.Duration
+	// Leave the order working when the chase gives up instead of cancelling it.
+	LeaveWorking bool
+	// Tick schedule of the order, fetched with GetOrderTicks when empty.
+	Ticks Ticks
+	// Streamer symbols of the legs keyed by leg symbol, derived for equities
+	// and equity options when missing.
+	StreamerSymbols map[string]string
+}
+
+// ChaseResult is the outcome of a chase.
+type ChaseResult struct {
+	// The order working when the chase stopped
+	Order Order
+	// Every order of the chase, replacements after the orders they replaced
+	Orders []Order
+	// Prices the order was worked at in order
+	Prices []decimal.Decimal
+	Filled bool
+}
+
+// OrderChaser works a limit order toward its fill, starting at the mid by
+// default and walking the price toward the natural price of its legs on a
+// schedule, in tick size increments and within a worst price.
+type OrderChaser struct {
+	client        *Client
+	feed          MarketDataFeed
+	accountNumber string
+	order         NewOrder
+	config        ChaseConfig
+
+	mu       sync.RWMutex
+	quotes   map[string]Quote
+	streamer map[string]string
+	result   ChaseResult
+}
+
+// NewOrderChaser creates a chaser for the limit order, quoting its legs from
+// the feed. When the order has no price it starts at the mid of its legs.
+// The feed may be nil when quotes are supplied through Apply.
+func (c *Client) NewOrderChaser(feed MarketDataFeed, accountNumber string, order NewOrder, config ChaseConfig) (*OrderChaser, error) {
+	switch {
+	case order.OrderType != Limit:
+		return nil, fmt.Errorf("%w: chased orders must be Limit orders", ErrInvalidOrder)
+	case order.PriceEffect != Debit && order.PriceEffect != Credit:
+		return nil, fmt.Errorf("%w: chased orders require a Debit or Credit price effect", ErrInvalidOrder)
+	case len(order.Legs) == 0:
+		return nil, fmt.Errorf("%w: order requires legs", ErrInvalidOrder)
+	case !config.WorstPrice.IsPositive():
+		return nil, errors.New("chase requires a positive worst price")
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = defaultChaseInterval
+	}
+	if config.StepTicks <= 0 {
+		config.StepTicks = 1
+	}
+
+	if len(config.Ticks.Schedule) == 0 {
+		ticks, err := c.GetOrderTicks(order)
+		if err != nil {
+			return nil, err
+		}
+		config.Ticks = ticks
+	}
+
+	ch := &OrderChaser{
+		client:        c,
+		feed:          feed,
+		accountNumber: accountNumber,
+		order:         order,
+		config:        config,
+		quotes:        map[string]Quote{},
+		streamer:      map[string]string{},
+	}
+
+	for _, leg := range order.Legs {
+		symbol := config.StreamerSymbols[leg.Symbol]
+		if symbol == "" {
+			symbol = streamerSymbol(leg.InstrumentType, leg.Symbol)
+		}
+		if symbol == "" {
+			return nil, fmt.Errorf("no streamer symbol for %s leg %s", leg.InstrumentType, leg.Symbol)
+		}
+		ch.streamer[symbol] = leg.Symbol
+	}
+
+	return ch, nil
+}
+
+// Apply updates the quote of the leg quoted by the event.
+func (ch *OrderChaser) Apply(event MarketEvent) {
+	quote, ok := event.(Quote)
+	if !ok || quote.BidPrice.IsZero() || quote.AskPrice.IsZero() {
+		return
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if symbol, ok := ch.streamer[quote.EventSymbol]; ok {
+		ch.quotes[symbol] = quote
+	}
+}
+
+// Mid returns the mid price of the order from its legs' quotes. False is
+// returned until every leg is quoted.
+func (ch *OrderChaser) Mid() (decimal.Decimal, bool) {
+	mid, _, ok := ch.prices()
+	return mid, ok
+}
+
+// Natural returns the price the order fills at immediately, buying at the
+// ask and selling at the bid. False is returned until every leg is quoted.
+func (ch *OrderChaser) Natural() (decimal.Decimal, bool) {
+	_, natural, ok := ch.prices()
+	return natural, ok
+}
+
+// Result returns the state of the chase so far.
+func (ch *OrderChaser) Result() ChaseResult {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	result := ch.result
+	result.Orders = append([]Order(nil), ch.result.Orders...)
+	result.Prices = append([]decimal.Decimal(nil), ch.result.Prices...)
+
+	return result
+}
+
+// Run submits the order and chases it until it fills, the timeout passes or
+// the context is done, cancelling the order when the chase gives up unless
+// configured to leave it working. Giving up on time isn't an error, the
+// result isn't Filled. The order is polled each step, order notifications
+// from the client's account streamer are applied in between when it is set.
+func (ch *OrderChaser) Run(ctx context.Context) (ChaseResult, error) {
+	var events <-chan MarketEvent
+	if ch.feed != nil {
+		subs := make([]FeedSubscription, 0, len(ch.streamer))
+		for symbol := range ch.streamer {
+			subs = append(subs, FeedSubscription{Type: QuoteEvent, Symbol: symbol})
+		}
+		if err := ch.feed.Subscribe(subs...); err != nil {
+			return ChaseResult{}, err
+		}
+		defer func() { _ = ch.feed.Unsubscribe(subs...) }()
+		events = ch.feed.Events()
+	}
+
+	var deadline <-chan time.Time
+	if ch.config.Timeout > 0 {
+		timer := time.NewTimer(ch.config.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	price := ch.order.Price
+	for price.IsZero() {
+		if mid, ok := ch.Mid(); ok {
+			price = mid
+			break
+		}
+		if events == nil {
+			return ChaseResult{}, errors.New("chase without a price requires quotes for the mid")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ChaseResult{}, ctx.Err()
+		case <-deadline:
+			return ChaseResult{}, nil
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			ch.Apply(event)
+		}
+	}
+
+	if err := ch.submit(ch.startPrice(price)); err != nil {
+		return ch.Result(), err
+	}
+
+	var accountEvents <-chan AccountEvent
+	if streamer := ch.client.accountStreamer.Load(); streamer != nil {
+		var stop func()
+		accountEvents, stop = streamer.Listen(defaultEventBuffer)
+		defer stop()
+	}
+
+	ticker := time.NewTicker(ch.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ch.giveUp(ctx.Err())
+		case <-deadline:
+			return ch.giveUp(nil)
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			ch.Apply(event)
+		case event, ok := <-accountEvents:
+			if !ok {
+				accountEvents = nil
+				continue
+			}
+			if event.Type != OrderNotification {
+				continue
+			}
+			order, err := event.Order()
+			if err != nil || order.ID != ch.current().ID {
+				continue
+			}
+			ch.update(order)
+		case <-ticker.C:
+			if err := ch.refresh(); err != nil {
+				return ch.Result(), err
+			}
+			if done, err := ch.finished(); done || err != nil {
+				return ch.Result(), err
+			}
+			if err := ch.step(); err != nil {
+				return ch.Result(), err
+			}
+		}
+
+		if done, err := ch.finished(); done || err != nil {
+			return ch.Result(), err
+		}
+	}
+}
+
+// submit sends the order at the price.
+func (ch *OrderChaser) submit(price decimal.Decimal) error {
+	order := ch.order
+	order.Price = price
+
+	resp, orderErr, _, err := ch.client.SubmitOrder(ch.accountNumber, order)
+	if err != nil {
+		return err
+	}
+	if orderErr != nil {
+		return fmt.Errorf("order rejected: %s", orderErr.Message)
+	}
+
+	ch.mu.Lock()
+	ch.result.Order = resp.Order
+	ch.result.Orders = append(ch.result.Orders, resp.Order)
+	ch.result.Prices = append(ch.result.Prices, price)
+	ch.mu.Unlock()
+
+	return nil
+}
+
+// step replaces the order one step closer to the natural price. Replacements
+// aborted by a fill of the working order, or failing while it changes, are
+// resolved by fetching the working order and retried next step.
+func (ch *OrderChaser) step() error {
+	current := ch.current()
+	if !ch.canReplace(current.Status) {
+		return nil
+	}
+
+	price, ok := ch.nextPrice(ch.lastPrice())
+	if !ok {
+		return nil
+	}
+
+	replacement, _, err := ch.client.ReplaceOrder(ch.accountNumber, current.ID, NewOrderECR{
+		TimeInForce: ch.order.TimeInForce,
+		GtcDate:     ch.order.GtcDate,
+		OrderType:   Limit,
+		Price:       price,
+		PriceEffect: ch.order.PriceEffect,
+	})
+	if err != nil || replacement.ID == 0 || (replacement.Status.IsTerminal() && replacement.Status != Filled) {
+		if refreshErr := ch.refresh(); refreshErr != nil {
+			return errors.Join(err, refreshErr)
+		}
+		return nil
+	}
+
+	ch.mu.Lock()
+	ch.result.Order = replacement
+	ch.result.Orders = append(ch.result.Orders, replacement)
+	ch.result.Prices = append(ch.result.Prices, price)
+	ch.mu.Unlock()
+
+	return nil
+}
+
+// refresh fetches the working order, following it to its replacement when
+// it was replaced outside of the chase.
+func (ch *OrderChaser) refresh() error {
+	order, _, err := ch.client.GetOrder(ch.accountNumber, ch.current().ID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status.IsTerminal() && order.Status != Filled && order.ReplacingOrderID != "" {
+		id, err := strconv.Atoi(order.ReplacingOrderID)
+		if err != nil {
+			return fmt.Errorf("order %d replaced by %q: %w", order.ID, order.ReplacingOrderID, err)
+		}
+
+		ch.update(order)
+		if order, _, err = ch.client.GetOrder(ch.accountNumber, id); err != nil {
+			return err
+		}
+
+		ch.mu.Lock()
+		ch.result.Orders = append(ch.result.Orders, order)
+		ch.result.Prices = append(ch.result.Prices, order.Price)
+		ch.mu.Unlock()
+	}
+
+	ch.update(order)
+	return nil
+}
+
+// update records the latest snapshot of the working order.
+func (ch *OrderChaser) update(order Order) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.result.Order = order
+	for i := range ch.result.Orders {
+		if ch.result.Orders[i].ID == order.ID {
+			ch.result.Orders[i] = order
+		}
+	}
+}
+
+// finished returns whether or not the chase is done, the order having filled
+// or finished without filling.
+func (ch *OrderChaser) finished() (bool, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	order := ch.result.Order
+	switch {
+	case order.Status == Filled:
+		ch.result.Filled = true
+		return true, nil
+	case order.Status.IsTerminal():
+		return true, fmt.Errorf("%w: order %d is %s", ErrOrderFinished, order.ID, order.Status)
+	default:
+		return false, nil
+	}
+}
+
+// giveUp cancels the working order unless configured to leave it working.
+func (ch *OrderChaser) giveUp(err error) (ChaseResult, error) {
+	if ch.config.LeaveWorking || !ch.current().Status.CanCancel() {
+		return ch.Result(), err
+	}
+
+	cancelled, _, cancelErr := ch.client.CancelOrder(ch.accountNumber, ch.current().ID)
+	if cancelErr != nil {
+		// the order may have filled while giving up
+		if refreshErr := ch.refresh(); refreshErr == nil {
+			_, _ = ch.finished()
+		}
+		return ch.Result(), errors.Join(err, cancelErr)
+	}
+
+	ch.update(cancelled)
+	return ch.Result(), err
+}
+
+// canReplace returns whether or not the order can be replaced, orders with
+// a pending cancel or replace being skipped until it resolves.
+func (ch *OrderChaser) canReplace(status OrderStatus) bool {
+	return status == Received || status == Routed || status == Live
+}
+
+func (ch *OrderChaser) current() Order {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.result.Order
+}
+
+func (ch *OrderChaser) lastPrice() decimal.Decimal {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.result.Prices[len(ch.result.Prices)-1]
+}
+
+// startPrice rounds the price onto the schedule in the order's favor, within
+// the worst price.
+func (ch *OrderChaser) startPrice(price decimal.Decimal) decimal.Decimal {
+	rounding := RoundDown
+	if ch.order.PriceEffect == Credit {
+		rounding = RoundUp
+	}
+
+	if rounded, err := ch.config.Ticks.Round(price, rounding); err == nil {
+		price = rounded
+	}
+
+	return ch.limit(price)
+}
+
+// nextPrice returns the price a step toward the natural price, limited by the
+// natural and worst prices. False is returned when the price can't move.
+func (ch *OrderChaser) nextPrice(price decimal.Decimal) (decimal.Decimal, bool) {
+	next := price
+	for i := 0; i < ch.config.StepTicks; i++ {
+		var err error
+		if next, err = ch.tickToward(next); err != nil {
+			return price, false
+		}
+	}
+
+	if natural, ok := ch.Natural(); ok {
+		if ch.order.PriceEffect == Credit {
+			natural, _ = ch.config.Ticks.Round(natural, RoundDown)
+			next = decimal.Max(next, natural)
+		} else {
+			natural, _ = ch.config.Ticks.Round(natural, RoundUp)
+			next = decimal.Min(next, natural)
+		}
+	}
+
+	next = ch.limit(next)
+	if ch.order.PriceEffect == Credit {
+		return next, next.LessThan(price)
+	}
+
+	return next, next.GreaterThan(price)
+}
+
+// tickToward moves the price a tick toward paying more, down for credits,
+// using the finer tick when the move crosses a threshold.
+func (ch *OrderChaser) tickToward(price decimal.Decimal) (decimal.Decimal, error) {
+	tick, err := ch.config.Ticks.Tick(price)
+	if err != nil {
+		return price, err
+	}
+
+	if ch.order.PriceEffect != Credit {
+		return ch.config.Ticks.Round(price.Add(tick), RoundUp)
+	}
+
+	next := price.Sub(tick)
+	if finer, err := ch.config.Ticks.Tick(next); err == nil && finer.LessThan(tick) {
+		next = price.Sub(finer)
+	}
+
+	return ch.config.Ticks.Round(next, RoundDown)
+}
+
+// limit keeps the price within the worst price.
+func (ch *OrderChaser) limit(price decimal.Decimal) decimal.Decimal {
+	if ch.order.PriceEffect == Credit {
+		return decimal.Max(price, ch.config.WorstPrice)
+	}
+
+	return decimal.Min(price, ch.config.WorstPrice)
+}
+
+// prices returns the mid and natural price per unit of the order as a debit
+// or credit like the order.
+func (ch *OrderChaser) prices() (mid, natural decimal.Decimal, ok bool) {
+	ch.mu.RLock()
+	quotes := make(map[string]LegQuote, len(ch.quotes))
+	for symbol, quote := range ch.quotes {
+		quotes[symbol] = NewLegQuote(quote)
+	}
+	ch.mu.RUnlock()
+
+	net, err := NetPrices(ch.order.Legs, quotes)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	if ch.order.PriceEffect == Credit {
+		return net.Mid.Neg(), net.Natural.Neg(), true
+	}
+
+	return net.Mid, net.Natural, true
+}
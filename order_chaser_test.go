@@ -0,0 +1,314 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chaseLongCall  = "AAPL  230616C00185000"
+	chaseShortCall = "AAPL  230616C00190000"
+)
+
+// chaseServer fakes the order endpoints, replacing orders with the next id.
+type chaseServer struct {
+	mu       sync.Mutex
+	statuses map[int]OrderStatus
+	prices   map[int]string
+	nextID   int
+	replaces int
+	// fills the working order once it has been replaced this many times
+	fillAfter int
+	// aborts replacements by filling the original
+	raceFill bool
+	cancels  int
+}
+
+func newChaseServer(t *testing.T) *chaseServer {
+	t.Helper()
+
+	cs := &chaseServer{statuses: map[int]OrderStatus{}, prices: map[int]string{}, nextID: 1, fillAfter: -1}
+
+	mux.HandleFunc("/accounts/5YZ55555/orders", func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, http.MethodPost, request.Method)
+
+		var order NewOrder
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&order))
+
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		id := cs.nextID
+		cs.nextID++
+		cs.statuses[id] = Live
+		cs.prices[id] = order.Price.String()
+
+		fmt.Fprintf(writer, `{"data":{"order":{"id":%d,"status":"Live","price":%q}}}`, id, order.Price)
+	})
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/", func(writer http.ResponseWriter, request *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(request.URL.Path, "/accounts/5YZ55555/orders/"))
+		require.NoError(t, err)
+
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		switch request.Method {
+		case http.MethodPut:
+			if cs.raceFill {
+				cs.statuses[id] = Filled
+				writer.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(writer, `{"error":{"code":"order_not_editable","message":"Order has been filled"}}`)
+				return
+			}
+
+			var ecr NewOrderECR
+			require.NoError(t, json.NewDecoder(request.Body).Decode(&ecr))
+
+			cs.statuses[id] = Cancelled
+			replacement := cs.nextID
+			cs.nextID++
+			cs.replaces++
+			cs.statuses[replacement] = Live
+			cs.prices[replacement] = ecr.Price.String()
+			if cs.replaces == cs.fillAfter {
+				cs.statuses[replacement] = Filled
+			}
+			id = replacement
+		case http.MethodDelete:
+			cs.cancels++
+			cs.statuses[id] = Cancelled
+		}
+
+		fmt.Fprintf(writer, `{"data":{"id":%d,"status":%q,"price":%q}}`, id, cs.statuses[id], cs.prices[id])
+	})
+
+	return cs
+}
+
+func chaseQuotes(feed *fakeFeed) {
+	for symbol, prices := range map[string][2]string{chaseLongCall: {"2.00", "2.20"}, chaseShortCall: {"0.80", "0.90"}} {
+		occ, _ := NewOCCFromString(symbol)
+		feed.events <- Quote{
+			EventSymbol: occ.StreamerSymbol(),
+			BidPrice:    decimal.RequireFromString(prices[0]),
+			AskPrice:    decimal.RequireFromString(prices[1]),
+		}
+	}
+}
+
+func chaseSpread(effect PriceEffect) NewOrder {
+	long, short := BTO, STO
+	if effect == Credit {
+		long, short = STO, BTO
+	}
+
+	return NewOrder{
+		TimeInForce: Day,
+		OrderType:   Limit,
+		PriceEffect: effect,
+		Legs: []NewOrderLeg{
+			{InstrumentType: EquityOptionIT, Symbol: chaseLongCall, Quantity: decimal.NewFromInt(2), Action: long},
+			{InstrumentType: EquityOptionIT, Symbol: chaseShortCall, Quantity: decimal.NewFromInt(2), Action: short},
+		},
+	}
+}
+
+func chasePrices(prices []decimal.Decimal) []string {
+	s := make([]string, 0, len(prices))
+	for _, p := range prices {
+		s = append(s, p.String())
+	}
+
+	return s
+}
+
+func TestOrderChaserFills(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cs := newChaseServer(t)
+	cs.fillAfter = 3
+
+	feed := newFakeFeed()
+	chaseQuotes(feed)
+
+	chaser, err := client.NewOrderChaser(feed, "5YZ55555", chaseSpread(Debit), ChaseConfig{
+		WorstPrice: decimal.RequireFromString("1.35"),
+		Interval:   5 * time.Millisecond,
+		StepTicks:  2,
+		Ticks:      testEquity(t).OptionTicks(),
+	})
+	require.NoError(t, err)
+
+	result, err := chaser.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, result.Filled)
+	require.Equal(t, Filled, result.Order.Status)
+	require.Equal(t, 4, result.Order.ID)
+	require.Len(t, result.Orders, 4)
+	// mid of 1.25 walked two ticks at a time
+	require.Equal(t, []string{"1.25", "1.27", "1.29", "1.31"}, chasePrices(result.Prices))
+
+	natural, ok := chaser.Natural()
+	require.True(t, ok)
+	require.Equal(t, "1.4", natural.String())
+	require.Len(t, feed.unsubscribed, 2)
+}
+
+func TestOrderChaserTimeout(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cs := newChaseServer(t)
+
+	feed := newFakeFeed()
+	chaseQuotes(feed)
+
+	chaser, err := client.NewOrderChaser(feed, "5YZ55555", chaseSpread(Credit), ChaseConfig{
+		WorstPrice: decimal.RequireFromString("1.20"),
+		Interval:   5 * time.Millisecond,
+		Timeout:    60 * time.Millisecond,
+		StepTicks:  3,
+		Ticks:      testEquity(t).OptionTicks(),
+	})
+	require.NoError(t, err)
+
+	result, err := chaser.Run(context.Background())
+	require.NoError(t, err)
+	require.False(t, result.Filled)
+	require.Equal(t, Cancelled, result.Order.Status)
+	require.Equal(t, 1, cs.cancels)
+	// the credit is walked down to the worst price and held there
+	require.Equal(t, []string{"1.25", "1.22", "1.2"}, chasePrices(result.Prices))
+}
+
+func TestOrderChaserStreamingMissedNotification(t *testing.T) {
+	setup()
+	defer teardown()
+
+	streamer, _ := connectedAccountStreamer(t)
+	defer streamer.Close()
+	client.SetAccountStreamer(streamer)
+	defer client.SetAccountStreamer(nil)
+
+	cs := newChaseServer(t)
+
+	feed := newFakeFeed()
+	chaseQuotes(feed)
+
+	chaser, err := client.NewOrderChaser(feed, "5YZ55555", chaseSpread(Debit), ChaseConfig{
+		WorstPrice: decimal.RequireFromString("1.25"),
+		Interval:   5 * time.Millisecond,
+		Timeout:    time.Second,
+		Ticks:      testEquity(t).OptionTicks(),
+	})
+	require.NoError(t, err)
+
+	// the fill is never streamed, only polling finds it
+	go func() {
+		require.Eventually(t, func() bool {
+			cs.mu.Lock()
+			defer cs.mu.Unlock()
+			if cs.nextID < 2 {
+				return false
+			}
+			cs.statuses[1] = Filled
+			return true
+		}, time.Second, time.Millisecond)
+	}()
+
+	result, err := chaser.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, result.Filled)
+	require.Equal(t, 1, result.Order.ID)
+	require.Zero(t, cs.cancels)
+}
+
+func TestOrderChaserReplaceRace(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cs := newChaseServer(t)
+	cs.raceFill = true
+
+	order := chaseSpread(Debit)
+	order.Price = decimal.RequireFromString("1.30")
+
+	chaser, err := client.NewOrderChaser(nil, "5YZ55555", order, ChaseConfig{
+		WorstPrice: decimal.RequireFromString("1.50"),
+		Interval:   5 * time.Millisecond,
+		Ticks:      testEquity(t).OptionTicks(),
+	})
+	require.NoError(t, err)
+
+	result, err := chaser.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, result.Filled)
+	require.Equal(t, 1, result.Order.ID)
+	require.Len(t, result.Orders, 1)
+	require.Equal(t, []string{"1.3"}, chasePrices(result.Prices))
+}
+
+func TestOrderChaserNextPrice(t *testing.T) {
+	order := chaseSpread(Credit)
+
+	chaser, err := client.NewOrderChaser(nil, "5YZ55555", order, ChaseConfig{
+		WorstPrice: decimal.NewFromInt(1),
+		Ticks:      testEquity(t).OptionTicks(),
+	})
+	require.NoError(t, err)
+
+	// crossing below $3 steps onto the penny schedule
+	next, ok := chaser.nextPrice(decimal.NewFromInt(3))
+	require.True(t, ok)
+	require.Equal(t, "2.99", next.String())
+
+	next, ok = chaser.nextPrice(decimal.RequireFromString("3.10"))
+	require.True(t, ok)
+	require.Equal(t, "3.05", next.String())
+
+	_, ok = chaser.nextPrice(decimal.NewFromInt(1))
+	require.False(t, ok)
+
+	_, err = chaser.Run(context.Background())
+	require.EqualError(t, err, "chase without a price requires quotes for the mid")
+}
+
+func TestNewOrderChaserErrors(t *testing.T) {
+	config := ChaseConfig{WorstPrice: decimal.NewFromInt(1), Ticks: testEquity(t).OptionTicks()}
+
+	order := chaseSpread(Debit)
+	order.OrderType = Market
+	_, err := client.NewOrderChaser(nil, "5YZ55555", order, config)
+	require.ErrorIs(t, err, ErrInvalidOrder)
+
+	order = chaseSpread(Debit)
+	order.PriceEffect = ""
+	_, err = client.NewOrderChaser(nil, "5YZ55555", order, config)
+	require.ErrorIs(t, err, ErrInvalidOrder)
+
+	_, err = client.NewOrderChaser(nil, "5YZ55555", chaseSpread(Debit), ChaseConfig{})
+	require.EqualError(t, err, "chase requires a positive worst price")
+
+	future := NewOrder{OrderType: Limit, PriceEffect: Debit, Legs: []NewOrderLeg{{InstrumentType: FutureIT, Symbol: "/ESZ3"}}}
+	_, err = client.NewOrderChaser(nil, "5YZ55555", future, config)
+	require.EqualError(t, err, "no streamer symbol for Future leg /ESZ3")
+
+	_, err = client.NewOrderChaser(nil, "5YZ55555", future, ChaseConfig{
+		WorstPrice:      decimal.NewFromInt(1),
+		Ticks:           config.Ticks,
+		StreamerSymbols: map[string]string{"/ESZ3": "/ESZ23:XCME"},
+	})
+	require.NoError(t, err)
+}
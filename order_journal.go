@@ -0,0 +1,458 @@
+package tasty
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMatchWindow = 5 * time.Minute
+	reconcilePageSize  = 200
+)
+
+// ErrOrderUnknown is returned when a submission failed without telling
+// whether the order was accepted and reconciliation found no matching order.
+var ErrOrderUnknown = errors.New("order outcome unknown")
+
+// JournalEntry is one record of an order journal.
+type JournalEntry struct {
+	Time          time.Time        `json:"time"`
+	Type          JournalEntryType `json:"type"`
+	ClientOrderID string           `json:"client-order-id"`
+	AccountNumber string           `json:"account-number"`
+	// The order, recorded with its intent
+	Order *NewOrder `json:"order,omitempty"`
+	// The ID of the accepted or reconciled order
+	OrderID int    `json:"order-id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// OrderJournal is an append-only record of order submissions.
+type OrderJournal interface {
+	Append(entry JournalEntry) error
+	Entries() ([]JournalEntry, error)
+}
+
+// FileJournal is an OrderJournal of JSON lines appended to a file, synced to
+// disk on every append.
+type FileJournal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// MemoryJournal is an OrderJournal kept in memory.
+type MemoryJournal struct {
+	mu      sync.Mutex
+	entries []JournalEntry
+}
+
+// JournalConfig configures journaled orders.
+type JournalConfig struct {
+	// How far apart the intent and an order's received time can be for the
+	// order to be matched by its legs and price. Defaults to 5 minutes.
+	MatchWindow time.Duration
+	// Times to resubmit an order whose outcome is unknown when no matching
+	// order is found. Defaults to 0, never resubmitting.
+	Resubmits int
+}
+
+// JournaledOrders attaches a client order ID to every order and records the
+// intent and outcome of its submission in a journal. When the outcome is
+// unknown, i.e. the request timed out, the account's orders are searched for
+// the order before it is resubmitted.
+type JournaledOrders struct {
+	client  *Client
+	service OrderService
+	journal OrderJournal
+	config  JournalConfig
+	mu      sync.Mutex
+}
+
+// NewClientOrderID returns a random client order ID.
+func NewClientOrderID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// OpenFileJournal opens the journal at the path, creating it if it doesn't exist.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileJournal{file: file}, nil
+}
+
+// Append writes the entry to the end of the journal.
+func (fj *FileJournal) Append(entry JournalEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	fj.mu.Lock()
+	defer fj.mu.Unlock()
+
+	if _, err = fj.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+
+	return fj.file.Sync()
+}
+
+// Entries reads the journal. A last entry cut short by a crash is skipped.
+func (fj *FileJournal) Entries() ([]JournalEntry, error) {
+	fj.mu.Lock()
+	defer fj.mu.Unlock()
+
+	file, err := os.Open(fj.file.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+
+	decoder := json.NewDecoder(file)
+	for {
+		var entry JournalEntry
+		err = decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// Close closes the journal file.
+func (fj *FileJournal) Close() error {
+	return fj.file.Close()
+}
+
+// Append adds the entry to the journal.
+func (mj *MemoryJournal) Append(entry JournalEntry) error {
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+
+	mj.entries = append(mj.entries, entry)
+
+	return nil
+}
+
+// Entries returns a copy of the journal.
+func (mj *MemoryJournal) Entries() ([]JournalEntry, error) {
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+
+	return append([]JournalEntry(nil), mj.entries...), nil
+}
+
+// NewJournaledOrders creates journaled orders submitted through the client.
+func (c *Client) NewJournaledOrders(journal OrderJournal, config JournalConfig) *JournaledOrders {
+	return c.NewJournaledOrdersFor(c, journal, config)
+}
+
+// NewJournaledOrdersFor creates journaled orders submitted through the order
+// service, searching for orders with the client.
+func (c *Client) NewJournaledOrdersFor(service OrderService, journal OrderJournal, config JournalConfig) *JournaledOrders {
+	if config.MatchWindow <= 0 {
+		config.MatchWindow = defaultMatchWindow
+	}
+
+	return &JournaledOrders{client: c, service: service, journal: journal, config: config}
+}
+
+// SubmitOrder journals and submits the order, generating its client order ID
+// when it has none. The order isn't submitted if its intent can't be
+// journaled. When the outcome is unknown the order is reconciled, returning
+// the matching order, resubmitting it or returning ErrOrderUnknown.
+func (jo *JournaledOrders) SubmitOrder(accountNumber string, order NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	if order.ExtClientOrderID == "" {
+		order.ExtClientOrderID = NewClientOrderID()
+	}
+
+	intent := JournalEntry{
+		Time:          time.Now(),
+		Type:          JournalIntent,
+		ClientOrderID: order.ExtClientOrderID,
+		AccountNumber: accountNumber,
+		Order:         &order,
+	}
+	if err := jo.journal.Append(intent); err != nil {
+		return OrderResponse{}, nil, nil, fmt.Errorf("journal order intent: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, orderErr, httpResp, err := jo.service.SubmitOrder(accountNumber, order)
+		if !unknownOutcome(resp, orderErr, httpResp, err) {
+			return resp, orderErr, httpResp, jo.record(intent, resp, orderErr, err)
+		}
+
+		// the intent is already journaled, reconciling matters more
+		_ = jo.appendOutcome(intent, JournalUnknown, 0, err)
+
+		found, ok, rerr := jo.Reconcile(intent)
+		if rerr != nil {
+			return OrderResponse{}, nil, httpResp, errors.Join(fmt.Errorf("%w: %w", ErrOrderUnknown, err), rerr)
+		}
+		if ok {
+			return OrderResponse{Order: found}, nil, httpResp, jo.appendOutcome(intent, JournalReconciled, found.ID, nil)
+		}
+
+		if attempt >= jo.config.Resubmits {
+			if err == nil {
+				return OrderResponse{}, nil, httpResp, ErrOrderUnknown
+			}
+			return OrderResponse{}, nil, httpResp, fmt.Errorf("%w: %w", ErrOrderUnknown, err)
+		}
+	}
+}
+
+// SubmitOrderDryRun dry runs the order without journaling it.
+func (jo *JournaledOrders) SubmitOrderDryRun(accountNumber string, order NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	return jo.service.SubmitOrderDryRun(accountNumber, order)
+}
+
+// Pending returns the intents of the journal without a known outcome, i.e.
+// orders submitted before a crash.
+func (jo *JournaledOrders) Pending() ([]JournalEntry, error) {
+	entries, err := jo.journal.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	return pendingIntents(entries), nil
+}
+
+// Recover reconciles the pending intents of the journal, recording and
+// returning whether each order was found. Missing orders can be resubmitted.
+func (jo *JournaledOrders) Recover() ([]JournalEntry, error) {
+	pending, err := jo.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]JournalEntry, 0, len(pending))
+	for _, intent := range pending {
+		found, ok, err := jo.Reconcile(intent)
+		if err != nil {
+			return outcomes, err
+		}
+
+		outcome := JournalEntry{
+			Time:          time.Now(),
+			Type:          JournalMissing,
+			ClientOrderID: intent.ClientOrderID,
+			AccountNumber: intent.AccountNumber,
+		}
+		if ok {
+			outcome.Type = JournalReconciled
+			outcome.OrderID = found.ID
+		}
+
+		if err = jo.journal.Append(outcome); err != nil {
+			return outcomes, err
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	return outcomes, nil
+}
+
+// Reconcile searches the account's orders for the order of the intent,
+// matching its client order ID or else its legs, price and received time.
+// The order history is paged back to the start of the match window. Orders
+// already matched to another intent of the journal are skipped.
+func (jo *JournaledOrders) Reconcile(intent JournalEntry) (Order, bool, error) {
+	jo.mu.Lock()
+	defer jo.mu.Unlock()
+
+	entries, err := jo.journal.Entries()
+	if err != nil {
+		return Order{}, false, err
+	}
+
+	claimed := map[int]bool{}
+	for _, entry := range entries {
+		if entry.OrderID != 0 && entry.ClientOrderID != intent.ClientOrderID {
+			claimed[entry.OrderID] = true
+		}
+	}
+
+	return jo.client.searchOrders(intent.AccountNumber, intent.Time.Add(-jo.config.MatchWindow), func(orders []Order) (Order, bool) {
+		return jo.match(intent, orders, claimed)
+	})
+}
+
+// searchOrders searches the account's live orders and then its order history,
+// paged back to the start, for the order the match finds.
+func (c *Client) searchOrders(accountNumber string, start time.Time, match func([]Order) (Order, bool)) (Order, bool, error) {
+	live, _, err := c.GetAccountLiveOrders(accountNumber)
+	if err != nil {
+		return Order{}, false, err
+	}
+	if order, ok := match(live); ok {
+		return order, true, nil
+	}
+
+	query := OrdersQuery{StartDate: start, PerPage: reconcilePageSize, Sort: Desc}
+	for {
+		orders, pagination, _, err := c.GetAccountOrders(accountNumber, query)
+		if err != nil {
+			return Order{}, false, err
+		}
+		if order, ok := match(orders); ok {
+			return order, true, nil
+		}
+
+		// newest first, the remaining pages were received before the start
+		if len(orders) == 0 || orders[len(orders)-1].ReceivedAt.Before(start) ||
+			query.PageOffset+1 >= pagination.TotalPages {
+			return Order{}, false, nil
+		}
+		query.PageOffset++
+	}
+}
+
+// match finds the order with the client order ID of the intent, or else the
+// order without a client order ID matching its legs, price and time.
+func (jo *JournaledOrders) match(intent JournalEntry, orders []Order, claimed map[int]bool) (Order, bool) {
+	for _, order := range orders {
+		if order.ExtClientOrderID == intent.ClientOrderID && !claimed[order.ID] {
+			return order, true
+		}
+	}
+
+	if intent.Order == nil {
+		return Order{}, false
+	}
+
+	for _, order := range orders {
+		if order.ExtClientOrderID != "" || claimed[order.ID] {
+			continue
+		}
+		if order.ReceivedAt.Before(intent.Time.Add(-jo.config.MatchWindow)) ||
+			order.ReceivedAt.After(intent.Time.Add(jo.config.MatchWindow)) {
+			continue
+		}
+		if sameOrder(*intent.Order, order) {
+			return order, true
+		}
+	}
+
+	return Order{}, false
+}
+
+// record journals the outcome of a submission whose outcome is known.
+func (jo *JournaledOrders) record(intent JournalEntry, resp OrderResponse, orderErr *OrderErrorResponse, err error) error {
+	switch {
+	case err != nil:
+		return errors.Join(err, jo.appendOutcome(intent, JournalRejected, 0, err))
+	case orderErr != nil:
+		return jo.appendOutcome(intent, JournalRejected, 0, errors.New(orderErr.Message))
+	default:
+		return jo.appendOutcome(intent, JournalAccepted, resp.Order.ID, nil)
+	}
+}
+
+func (jo *JournaledOrders) appendOutcome(intent JournalEntry, entryType JournalEntryType, orderID int, err error) error {
+	entry := JournalEntry{
+		Time:          time.Now(),
+		Type:          entryType,
+		ClientOrderID: intent.ClientOrderID,
+		AccountNumber: intent.AccountNumber,
+		OrderID:       orderID,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if jerr := jo.journal.Append(entry); jerr != nil {
+		return fmt.Errorf("journal order %s: %w", entryType, jerr)
+	}
+
+	return nil
+}
+
+// unknownOutcome returns whether or not a submission failed without telling
+// whether the order was accepted: no response, a server error or a response
+// without an order.
+func unknownOutcome(resp OrderResponse, orderErr *OrderErrorResponse, httpResp *http.Response, err error) bool {
+	switch {
+	case err != nil && httpResp == nil:
+		return true
+	case httpResp != nil && httpResp.StatusCode >= http.StatusInternalServerError:
+		return true
+	case err == nil && orderErr == nil && resp.Order.ID == 0:
+		return true
+	default:
+		return false
+	}
+}
+
+// pendingIntents returns the intents whose latest entry has no known outcome.
+func pendingIntents(entries []JournalEntry) []JournalEntry {
+	intents := map[string]JournalEntry{}
+	latest := map[string]JournalEntryType{}
+	var order []string
+
+	for _, entry := range entries {
+		if entry.Type == JournalIntent {
+			if _, ok := intents[entry.ClientOrderID]; !ok {
+				order = append(order, entry.ClientOrderID)
+			}
+			intents[entry.ClientOrderID] = entry
+		}
+		latest[entry.ClientOrderID] = entry.Type
+	}
+
+	var pending []JournalEntry
+	for _, id := range order {
+		if t := latest[id]; t == JournalIntent || t == JournalUnknown {
+			pending = append(pending, intents[id])
+		}
+	}
+
+	return pending
+}
+
+// sameOrder returns whether or not the order has the type, price and legs of
+// the new order.
+func sameOrder(newOrder NewOrder, order Order) bool {
+	if newOrder.OrderType != order.OrderType || !newOrder.Price.Equal(order.Price) ||
+		len(newOrder.Legs) != len(order.Legs) {
+		return false
+	}
+
+	matched := make([]bool, len(order.Legs))
+	for _, leg := range newOrder.Legs {
+		found := false
+		for i, ol := range order.Legs {
+			if !matched[i] && ol.Symbol == leg.Symbol && ol.Action == leg.Action && ol.Quantity.Equal(leg.Quantity) {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
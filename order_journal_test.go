@@ -0,0 +1,317 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// journalServer fakes the order searches and order submission, answering
+// the first failures submissions with a 503.
+type journalServer struct {
+	submitted []NewOrder
+	failures  int
+	live      string
+	// pages of the order history
+	history []string
+	queries []OrdersQuery
+}
+
+func newJournalServer(t *testing.T) *journalServer {
+	t.Helper()
+
+	js := &journalServer{live: `[]`}
+
+	mux.HandleFunc("/accounts/5YZ55555/orders", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodGet {
+			query := request.URL.Query()
+			offset, _ := strconv.Atoi(query.Get("page-offset"))
+			perPage, _ := strconv.Atoi(query.Get("per-page"))
+			js.queries = append(js.queries, OrdersQuery{PageOffset: offset, PerPage: perPage, Sort: SortOrder(query.Get("sort"))})
+
+			page := `[]`
+			if offset < len(js.history) {
+				page = js.history[offset]
+			}
+			fmt.Fprintf(writer, `{"data":{"items":%s},"pagination":{"page-offset":%d,"total-pages":%d}}`, page, offset, len(js.history))
+			return
+		}
+
+		var order NewOrder
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&order))
+		js.submitted = append(js.submitted, order)
+
+		if len(js.submitted) <= js.failures {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Fprintf(writer, `{"data":{"order":{"id":%d,"status":"Received","ext-client-order-id":%q}}}`,
+			100+len(js.submitted), order.ExtClientOrderID)
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprintf(writer, `{"data":{"items":%s}}`, js.live)
+	})
+
+	return js
+}
+
+func journalTypes(t *testing.T, journal OrderJournal) []JournalEntryType {
+	t.Helper()
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+
+	types := make([]JournalEntryType, 0, len(entries))
+	for _, entry := range entries {
+		types = append(types, entry.Type)
+	}
+
+	return types
+}
+
+func TestJournaledOrdersAccepted(t *testing.T) {
+	setup()
+	defer teardown()
+
+	js := newJournalServer(t)
+
+	journal, err := OpenFileJournal(filepath.Join(t.TempDir(), "orders.journal"))
+	require.NoError(t, err)
+	defer journal.Close()
+
+	orders := client.NewJournaledOrders(journal, JournalConfig{})
+
+	resp, orderErr, _, err := orders.SubmitOrder("5YZ55555", buyAAPL(10, "1.50"))
+	require.NoError(t, err)
+	require.Nil(t, orderErr)
+	require.Equal(t, 101, resp.Order.ID)
+	require.Len(t, js.submitted[0].ExtClientOrderID, 32)
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, JournalIntent, entries[0].Type)
+	require.Equal(t, js.submitted[0].ExtClientOrderID, entries[0].ClientOrderID)
+	require.Equal(t, "AAPL", entries[0].Order.Legs[0].Symbol)
+	require.Equal(t, JournalAccepted, entries[1].Type)
+	require.Equal(t, 101, entries[1].OrderID)
+
+	pending, err := orders.Pending()
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestJournaledOrdersRejected(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/orders", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	journal := &MemoryJournal{}
+	orders := client.NewJournaledOrders(journal, JournalConfig{Resubmits: 3})
+
+	order := buyAAPL(10, "1.50")
+	order.ExtClientOrderID = "my-order"
+
+	_, _, _, err := orders.SubmitOrder("5YZ55555", order)
+	expectedUnauthorized(t, err)
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	require.Equal(t, []JournalEntryType{JournalIntent, JournalRejected}, journalTypes(t, journal))
+	require.Equal(t, "my-order", entries[1].ClientOrderID)
+	require.Contains(t, entries[1].Error, "Unauthorized")
+}
+
+func TestJournaledOrdersReconcileByClientOrderID(t *testing.T) {
+	setup()
+	defer teardown()
+
+	js := newJournalServer(t)
+	js.failures = 1
+
+	order := buyAAPL(10, "1.50")
+	order.ExtClientOrderID = "abc123"
+	js.live = `[{"id":7,"status":"Live","ext-client-order-id":"other"},{"id":8,"status":"Live","ext-client-order-id":"abc123"}]`
+
+	journal := &MemoryJournal{}
+	orders := client.NewJournaledOrders(journal, JournalConfig{Resubmits: 1})
+
+	resp, orderErr, _, err := orders.SubmitOrder("5YZ55555", order)
+	require.NoError(t, err)
+	require.Nil(t, orderErr)
+	require.Equal(t, 8, resp.Order.ID)
+	require.Len(t, js.submitted, 1)
+	require.Equal(t, []JournalEntryType{JournalIntent, JournalUnknown, JournalReconciled}, journalTypes(t, journal))
+}
+
+func TestJournaledOrdersReconcileByLegs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	js := newJournalServer(t)
+	js.failures = 1
+
+	now := time.Now().UTC()
+	order := fmt.Sprintf(`{"id":%%d,"order-type":"Limit","price":%%q,"status":"Filled","received-at":%q,
+		"legs":[{"symbol":"AAPL","quantity":"10","action":"Buy"}]}`, now.Format(time.RFC3339))
+	stale := fmt.Sprintf(`{"id":1,"order-type":"Limit","price":"1.5","status":"Filled","received-at":%q,
+		"legs":[{"symbol":"AAPL","quantity":"10","action":"Buy"}]}`, now.Add(-time.Hour).Format(time.RFC3339))
+	js.history = []string{fmt.Sprintf(`[%s,%s,%s,%s]`, fmt.Sprintf(order, 4, "1.5"), fmt.Sprintf(order, 3, "1.5"), fmt.Sprintf(order, 2, "1.55"), stale)}
+
+	journal := &MemoryJournal{}
+	// an earlier identical order already claimed order 3
+	require.NoError(t, journal.Append(JournalEntry{Type: JournalAccepted, ClientOrderID: "earlier", OrderID: 3}))
+
+	orders := client.NewJournaledOrders(journal, JournalConfig{})
+
+	resp, _, _, err := orders.SubmitOrder("5YZ55555", buyAAPL(10, "1.50"))
+	require.NoError(t, err)
+	require.Equal(t, 4, resp.Order.ID)
+	require.Len(t, js.submitted, 1)
+}
+
+func TestJournaledOrdersReconcilePages(t *testing.T) {
+	setup()
+	defer teardown()
+
+	js := newJournalServer(t)
+	js.failures = 1
+
+	now := time.Now().UTC()
+	order := func(id int, clientOrderID string, receivedAt time.Time) string {
+		return fmt.Sprintf(`{"id":%d,"status":"Filled","ext-client-order-id":%q,"received-at":%q}`, id, clientOrderID, receivedAt.Format(time.RFC3339))
+	}
+	js.history = []string{
+		fmt.Sprintf(`[%s,%s]`, order(9, "newer", now), order(8, "newer", now)),
+		fmt.Sprintf(`[%s,%s]`, order(7, "abc123", now), order(6, "older", now.Add(-time.Hour))),
+		fmt.Sprintf(`[%s]`, order(5, "oldest", now.Add(-time.Hour))),
+	}
+
+	submitted := buyAAPL(10, "1.50")
+	submitted.ExtClientOrderID = "abc123"
+
+	orders := client.NewJournaledOrders(&MemoryJournal{}, JournalConfig{})
+
+	resp, _, _, err := orders.SubmitOrder("5YZ55555", submitted)
+	require.NoError(t, err)
+	require.Equal(t, 7, resp.Order.ID)
+	require.Len(t, js.queries, 2)
+	require.Equal(t, 1, js.queries[1].PageOffset)
+	require.Equal(t, Desc, js.queries[1].Sort)
+
+	// paging stops once the pages are older than the match window
+	js.queries = nil
+	js.history[1] = fmt.Sprintf(`[%s]`, order(6, "older", now.Add(-time.Hour)))
+	submitted.ExtClientOrderID = "missing"
+	js.failures = 2
+
+	_, _, _, err = orders.SubmitOrder("5YZ55555", submitted)
+	require.ErrorIs(t, err, ErrOrderUnknown)
+	require.Len(t, js.queries, 2)
+}
+
+func TestJournaledOrdersResubmit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	js := newJournalServer(t)
+	js.failures = 1
+
+	journal := &MemoryJournal{}
+	orders := client.NewJournaledOrders(journal, JournalConfig{Resubmits: 1})
+
+	resp, _, _, err := orders.SubmitOrder("5YZ55555", buyAAPL(10, "1.50"))
+	require.NoError(t, err)
+	require.Equal(t, 102, resp.Order.ID)
+	require.Len(t, js.submitted, 2)
+	require.Equal(t, js.submitted[0].ExtClientOrderID, js.submitted[1].ExtClientOrderID)
+	require.Equal(t, []JournalEntryType{JournalIntent, JournalUnknown, JournalAccepted}, journalTypes(t, journal))
+}
+
+func TestJournaledOrdersUnknown(t *testing.T) {
+	setup()
+	defer teardown()
+
+	js := newJournalServer(t)
+	js.failures = 2
+
+	journal := &MemoryJournal{}
+	orders := client.NewJournaledOrders(journal, JournalConfig{Resubmits: 1})
+
+	_, _, _, err := orders.SubmitOrder("5YZ55555", buyAAPL(10, "1.50"))
+	require.ErrorIs(t, err, ErrOrderUnknown)
+	require.Len(t, js.submitted, 2)
+
+	pending, err := orders.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, js.submitted[0].ExtClientOrderID, pending[0].ClientOrderID)
+
+	// the order shows up after all
+	js.live = fmt.Sprintf(`[{"id":55,"status":"Live","ext-client-order-id":%q}]`, pending[0].ClientOrderID)
+
+	outcomes, err := orders.Recover()
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	require.Equal(t, JournalReconciled, outcomes[0].Type)
+	require.Equal(t, 55, outcomes[0].OrderID)
+
+	pending, err = orders.Pending()
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestJournaledOrdersRecoverMissing(t *testing.T) {
+	setup()
+	defer teardown()
+
+	newJournalServer(t)
+
+	order := buyAAPL(10, "1.50")
+	journal := &MemoryJournal{}
+	require.NoError(t, journal.Append(JournalEntry{Time: time.Now(), Type: JournalIntent, ClientOrderID: "lost", AccountNumber: "5YZ55555", Order: &order}))
+
+	orders := client.NewJournaledOrders(journal, JournalConfig{})
+
+	outcomes, err := orders.Recover()
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	require.Equal(t, JournalMissing, outcomes[0].Type)
+	require.Equal(t, "lost", outcomes[0].ClientOrderID)
+}
+
+func TestFileJournalTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.journal")
+
+	journal, err := OpenFileJournal(path)
+	require.NoError(t, err)
+	require.NoError(t, journal.Append(JournalEntry{Type: JournalIntent, ClientOrderID: "a"}))
+	require.NoError(t, journal.Close())
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"type":"Accep`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	journal, err = OpenFileJournal(path)
+	require.NoError(t, err)
+	defer journal.Close()
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "a", entries[0].ClientOrderID)
+}
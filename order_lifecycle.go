@@ -0,0 +1,338 @@
+package tasty
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	workingOrderStatuses = []OrderStatus{Received, Routed, InFlight, Live, CancelRequested, ReplaceRequested, Contingent}
+	cancelOrderStatuses  = []OrderStatus{Received, Routed, InFlight, Live, Contingent}
+
+	// Legal transitions of a working order, terminal orders don't transition.
+	orderStatusTransitions = map[OrderStatus][]OrderStatus{
+		Received: {Routed, InFlight, Live, Contingent, CancelRequested,
+			Filled, Cancelled, Expired, Rejected, Removed},
+		Routed: {InFlight, Live, CancelRequested, ReplaceRequested,
+			Filled, Cancelled, Expired, Rejected, Removed},
+		InFlight: {Live, CancelRequested, ReplaceRequested,
+			Filled, Cancelled, Expired, Rejected, Removed},
+		Live: {CancelRequested, ReplaceRequested,
+			Filled, Cancelled, Expired, Rejected, Removed, PartiallyRemoved},
+		// a rejected cancel or replace leaves the order live
+		CancelRequested:  {Live, Filled, Cancelled, Expired, Removed, PartiallyRemoved},
+		ReplaceRequested: {Live, Filled, Cancelled, Expired, Removed, PartiallyRemoved},
+		Contingent: {Received, Routed, InFlight, Live, CancelRequested,
+			Cancelled, Rejected, Removed},
+	}
+)
+
+// IsWorking returns whether or not the order can still fill.
+func (s OrderStatus) IsWorking() bool {
+	return containsOrderStatus(workingOrderStatuses, s)
+}
+
+// IsTerminal returns whether or not the order is done: Filled, Cancelled,
+// Expired, Rejected, Removed or Partially Removed.
+func (s OrderStatus) IsTerminal() bool {
+	return containsOrderStatus(terminalOrderStatuses, s)
+}
+
+// CanCancel returns whether or not a cancel can be requested for the order.
+// Orders with a cancel or replace already requested can't be cancelled.
+func (s OrderStatus) CanCancel() bool {
+	return containsOrderStatus(cancelOrderStatuses, s)
+}
+
+// CanTransitionTo returns whether or not the order can move to the status.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	return containsOrderStatus(orderStatusTransitions[s], next)
+}
+
+// FilledQuantity returns the quantity of the leg filled so far.
+func (ol OrderLeg) FilledQuantity() decimal.Decimal {
+	filled := decimal.Zero
+	for _, fill := range ol.Fills {
+		filled = filled.Add(fill.Quantity)
+	}
+
+	return filled
+}
+
+// UnfilledQuantity returns the quantity of the leg left to fill.
+func (ol OrderLeg) UnfilledQuantity() decimal.Decimal {
+	return decimal.Max(ol.Quantity.Sub(ol.FilledQuantity()), decimal.Zero)
+}
+
+// AverageFillPrice returns the quantity weighted price the leg filled at.
+// False is returned when the leg has no fills.
+func (ol OrderLeg) AverageFillPrice() (decimal.Decimal, bool) {
+	filled := ol.FilledQuantity()
+	if !filled.IsPositive() {
+		return decimal.Zero, false
+	}
+
+	value := decimal.Zero
+	for _, fill := range ol.Fills {
+		value = value.Add(fill.FillPrice.Mul(fill.Quantity))
+	}
+
+	return value.Div(filled), true
+}
+
+// IsFilled returns whether or not the leg filled its whole quantity.
+func (ol OrderLeg) IsFilled() bool {
+	return ol.Quantity.IsPositive() && !ol.FilledQuantity().LessThan(ol.Quantity)
+}
+
+// FillRatio returns the filled portion of the order from 0 to 1, the least
+// filled leg limiting multi-leg orders.
+func (o Order) FillRatio() decimal.Decimal {
+	if len(o.Legs) == 0 {
+		return decimal.Zero
+	}
+
+	ratio := decimal.NewFromInt(1)
+	for _, leg := range o.Legs {
+		if !leg.Quantity.IsPositive() {
+			return decimal.Zero
+		}
+		ratio = decimal.Min(ratio, leg.FilledQuantity().Div(leg.Quantity))
+	}
+
+	return ratio
+}
+
+// IsFilled returns whether or not every leg of the order filled its whole quantity.
+func (o Order) IsFilled() bool {
+	for _, leg := range o.Legs {
+		if !leg.IsFilled() {
+			return false
+		}
+	}
+
+	return len(o.Legs) > 0
+}
+
+// IsPartiallyFilled returns whether or not the order has fills but isn't filled.
+func (o Order) IsPartiallyFilled() bool {
+	if o.IsFilled() {
+		return false
+	}
+
+	for _, leg := range o.Legs {
+		if len(leg.Fills) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderTracker follows orders through their lifecycle from successive
+// snapshots, polled or streamed, and emits their transitions in order.
+// Snapshots older than the last one applied to an order, by UpdatedAt, are
+// dropped and a terminal order never returns to working.
+type OrderTracker struct {
+	client        *Client
+	accountNumber string
+
+	mu     sync.RWMutex
+	orders map[int]Order
+}
+
+// NewOrderTracker creates an order tracker for the account's orders.
+func (c *Client) NewOrderTracker(accountNumber string) *OrderTracker {
+	return &OrderTracker{
+		client:        c,
+		accountNumber: accountNumber,
+		orders:        map[int]Order{},
+	}
+}
+
+// Refresh applies the account's live orders.
+func (t *OrderTracker) Refresh() ([]OrderTransition, error) {
+	orders, _, err := t.client.GetAccountLiveOrders(t.accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var transitions []OrderTransition
+	for _, order := range orders {
+		if transition, ok := t.Apply(order); ok {
+			transitions = append(transitions, transition)
+		}
+	}
+
+	return transitions, nil
+}
+
+// Run applies order notifications from the client's account streamer and
+// refreshes the live orders periodically as a safety net, polling more often
+// without a connected streamer, until the context is done. Each transition
+// is passed to onTransition.
+func (t *OrderTracker) Run(ctx context.Context, onTransition func(OrderTransition)) error {
+	var events <-chan AccountEvent
+	interval := orderPollMax
+	if streamer := t.client.accountStreamer.Load(); streamer != nil {
+		var stop func()
+		events, stop = streamer.Listen(defaultEventBuffer)
+		defer stop()
+		interval = orderPollStreaming
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var transitions []OrderTransition
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				interval = orderPollMax
+				continue
+			}
+			transition, applied, err := t.ApplyAccountEvent(event)
+			if err != nil {
+				return err
+			}
+			if applied {
+				transitions = append(transitions, transition)
+			}
+		case <-timer.C:
+			refreshed, err := t.Refresh()
+			if err != nil {
+				return err
+			}
+			transitions = refreshed
+			timer.Reset(interval)
+		}
+
+		if onTransition != nil {
+			for _, transition := range transitions {
+				onTransition(transition)
+			}
+		}
+	}
+}
+
+// ApplyAccountEvent applies an order notification of the tracked account.
+func (t *OrderTracker) ApplyAccountEvent(event AccountEvent) (OrderTransition, bool, error) {
+	if event.Type != OrderNotification {
+		return OrderTransition{}, false, nil
+	}
+
+	order, err := event.Order()
+	if err != nil {
+		return OrderTransition{}, false, err
+	}
+
+	if order.AccountNumber != t.accountNumber {
+		return OrderTransition{}, false, nil
+	}
+
+	transition, ok := t.Apply(order)
+	return transition, ok, nil
+}
+
+// Apply updates the order from the snapshot returning the transition it
+// caused, with From empty for the first snapshot of an order. Stale snapshots
+// and snapshots without a status change or new fills return false.
+func (t *OrderTracker) Apply(order Order) (OrderTransition, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, seen := t.orders[order.ID]
+	if seen && isStaleOrder(current, order) {
+		return OrderTransition{}, false
+	}
+
+	t.orders[order.ID] = order
+
+	fills := newFills(current, order)
+	if seen && current.Status == order.Status && len(fills) == 0 {
+		return OrderTransition{}, false
+	}
+
+	return OrderTransition{
+		From:  current.Status,
+		To:    order.Status,
+		Order: order,
+		Fills: fills,
+		At:    time.Now(),
+	}, true
+}
+
+// Order returns the latest snapshot of the order.
+func (t *OrderTracker) Order(id int) (Order, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	order, ok := t.orders[id]
+	return order, ok
+}
+
+// Working returns the working orders ordered by id.
+func (t *OrderTracker) Working() []Order {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var orders []Order
+	for _, order := range t.orders {
+		if order.Status.IsWorking() {
+			orders = append(orders, order)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+
+	return orders
+}
+
+// isStaleOrder returns whether or not the snapshot is older than the current
+// one. Snapshots updated at the same time only apply when the status can
+// follow the current one or fills were added.
+func isStaleOrder(current, next Order) bool {
+	if current.Status.IsTerminal() && !next.Status.IsTerminal() {
+		return true
+	}
+
+	if current.UpdatedAt == 0 || next.UpdatedAt == 0 || next.UpdatedAt > current.UpdatedAt {
+		return false
+	}
+
+	if next.UpdatedAt < current.UpdatedAt {
+		return true
+	}
+
+	return next.Status != current.Status && !current.Status.CanTransitionTo(next.Status)
+}
+
+// newFills returns the fills of the next snapshot missing from the current one.
+func newFills(current, next Order) []OrderFill {
+	seen := map[string]bool{}
+	for _, leg := range current.Legs {
+		for _, fill := range leg.Fills {
+			seen[fill.FillID] = true
+		}
+	}
+
+	var fills []OrderFill
+	for _, leg := range next.Legs {
+		for _, fill := range leg.Fills {
+			if !seen[fill.FillID] {
+				fills = append(fills, fill)
+			}
+		}
+	}
+
+	return fills
+}
@@ -0,0 +1,254 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderStatusPredicates(t *testing.T) {
+	require.True(t, Live.IsWorking())
+	require.True(t, CancelRequested.IsWorking())
+	require.False(t, Filled.IsWorking())
+
+	require.True(t, PartiallyRemoved.IsTerminal())
+	require.True(t, Rejected.IsTerminal())
+	require.False(t, Contingent.IsTerminal())
+
+	require.True(t, Live.CanCancel())
+	require.False(t, CancelRequested.CanCancel())
+	require.False(t, Cancelled.CanCancel())
+
+	require.True(t, Received.CanTransitionTo(Live))
+	require.True(t, CancelRequested.CanTransitionTo(Live))
+	require.False(t, Live.CanTransitionTo(Received))
+	require.False(t, Filled.CanTransitionTo(Live))
+
+	for _, status := range terminalOrderStatuses {
+		require.Empty(t, orderStatusTransitions[status], status)
+	}
+}
+
+func testFillOrder(id int, status OrderStatus, updatedAt int, fills ...OrderFill) Order {
+	return Order{
+		ID:            id,
+		AccountNumber: "5YZ55555",
+		Status:        status,
+		UpdatedAt:     updatedAt,
+		Legs: []OrderLeg{
+			{Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Fills: fills},
+		},
+	}
+}
+
+func TestOrderFills(t *testing.T) {
+	order := testFillOrder(1, Live, 1,
+		OrderFill{FillID: "1", Quantity: decimal.NewFromInt(4), FillPrice: decimal.RequireFromString("150.10")},
+		OrderFill{FillID: "2", Quantity: decimal.NewFromInt(1), FillPrice: decimal.RequireFromString("150.60")},
+	)
+
+	leg := order.Legs[0]
+	require.Equal(t, "5", leg.FilledQuantity().String())
+	require.Equal(t, "5", leg.UnfilledQuantity().String())
+	price, ok := leg.AverageFillPrice()
+	require.True(t, ok)
+	require.Equal(t, "150.2", price.String())
+
+	require.Equal(t, "0.5", order.FillRatio().String())
+	require.True(t, order.IsPartiallyFilled())
+	require.False(t, order.IsFilled())
+
+	order.Legs[0].Fills = append(order.Legs[0].Fills, OrderFill{FillID: "3", Quantity: decimal.NewFromInt(5)})
+	require.True(t, order.IsFilled())
+	require.False(t, order.IsPartiallyFilled())
+	require.Equal(t, "1", order.FillRatio().String())
+	require.True(t, order.Legs[0].UnfilledQuantity().IsZero())
+
+	// the least filled leg limits a spread
+	order.Legs = append(order.Legs, OrderLeg{Symbol: "AAPL  230818C00185000", Quantity: decimal.NewFromInt(10)})
+	require.True(t, order.FillRatio().IsZero())
+	require.True(t, order.IsPartiallyFilled())
+
+	_, ok = order.Legs[1].AverageFillPrice()
+	require.False(t, ok)
+	require.False(t, Order{}.IsFilled())
+}
+
+func TestOrderTrackerApply(t *testing.T) {
+	tracker := client.NewOrderTracker("5YZ55555")
+
+	tr, ok := tracker.Apply(testFillOrder(1, Received, 100))
+	require.True(t, ok)
+	require.Equal(t, OrderStatus(""), tr.From)
+	require.Equal(t, Received, tr.To)
+
+	tr, ok = tracker.Apply(testFillOrder(1, Live, 300))
+	require.True(t, ok)
+	require.Equal(t, Received, tr.From)
+	require.Equal(t, Live, tr.To)
+
+	// a late snapshot is dropped
+	_, ok = tracker.Apply(testFillOrder(1, Routed, 200))
+	require.False(t, ok)
+
+	// unchanged snapshots don't transition
+	_, ok = tracker.Apply(testFillOrder(1, Live, 300))
+	require.False(t, ok)
+
+	fill := OrderFill{FillID: "1", Quantity: decimal.NewFromInt(4), FillPrice: decimal.NewFromInt(150)}
+	tr, ok = tracker.Apply(testFillOrder(1, Live, 400, fill))
+	require.True(t, ok)
+	require.Equal(t, Live, tr.From)
+	require.Equal(t, Live, tr.To)
+	require.Equal(t, []OrderFill{fill}, tr.Fills)
+
+	// snapshots at the same time apply when the status can follow
+	_, ok = tracker.Apply(testFillOrder(1, Received, 400, fill))
+	require.False(t, ok)
+	tr, ok = tracker.Apply(testFillOrder(1, Cancelled, 400, fill))
+	require.True(t, ok)
+	require.Empty(t, tr.Fills)
+
+	// terminal orders never return to working
+	_, ok = tracker.Apply(testFillOrder(1, Live, 0, fill))
+	require.False(t, ok)
+
+	order, ok := tracker.Order(1)
+	require.True(t, ok)
+	require.Equal(t, Cancelled, order.Status)
+
+	tracker.Apply(testFillOrder(3, Live, 100))
+	tracker.Apply(testFillOrder(2, Routed, 100))
+	working := tracker.Working()
+	require.Len(t, working, 2)
+	require.Equal(t, 2, working[0].ID)
+	require.Equal(t, 3, working[1].ID)
+}
+
+func TestOrderTrackerApplyAccountEvent(t *testing.T) {
+	tracker := client.NewOrderTracker("5YZ55555")
+
+	data, err := json.Marshal(testFillOrder(1, Live, 100))
+	require.NoError(t, err)
+
+	tr, ok, err := tracker.ApplyAccountEvent(AccountEvent{Type: OrderNotification, Data: data})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Live, tr.To)
+
+	other := testFillOrder(2, Live, 100)
+	other.AccountNumber = "5WT00000"
+	data, err = json.Marshal(other)
+	require.NoError(t, err)
+
+	_, ok, err = tracker.ApplyAccountEvent(AccountEvent{Type: OrderNotification, Data: data})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, ok, err = tracker.ApplyAccountEvent(AccountEvent{Type: BalanceNotification, Data: data})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, _, err = tracker.ApplyAccountEvent(AccountEvent{Type: OrderNotification, Data: []byte(`[]`)})
+	require.Error(t, err)
+}
+
+func TestOrderTrackerRun(t *testing.T) {
+	setup()
+	defer teardown()
+	fastOrderPolling(t)
+
+	var mu sync.Mutex
+	status := Routed
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(writer, `{"data":{"items":[{"id":1,"account-number":"5YZ55555","status":%q}]}}`, status)
+	})
+
+	tracker := client.NewOrderTracker("5YZ55555")
+	transitions := make(chan OrderTransition, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- tracker.Run(ctx, func(tr OrderTransition) {
+			transitions <- tr
+		})
+	}()
+
+	require.Equal(t, Routed, (<-transitions).To)
+
+	mu.Lock()
+	status = Filled
+	mu.Unlock()
+
+	tr := <-transitions
+	require.Equal(t, Routed, tr.From)
+	require.Equal(t, Filled, tr.To)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.Empty(t, tracker.Working())
+}
+
+func TestOrderTrackerRunStreaming(t *testing.T) {
+	setup()
+	defer teardown()
+	fastOrderPolling(t)
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"items":[{"id":1,"account-number":"5YZ55555","status":"Live","updated-at":100}]}}`)
+	})
+
+	streamer, conn := connectedAccountStreamer(t)
+	defer streamer.Close()
+	client.SetAccountStreamer(streamer)
+	defer client.SetAccountStreamer(nil)
+
+	tracker := client.NewOrderTracker("5YZ55555")
+	transitions := make(chan OrderTransition, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = tracker.Run(ctx, func(tr OrderTransition) {
+			transitions <- tr
+		})
+	}()
+
+	require.Equal(t, Live, (<-transitions).To)
+
+	// the stale notification is dropped
+	conn.in <- []byte(`{"type":"Order","data":{"id":1,"account-number":"5YZ55555","status":"Routed","updated-at":50},"timestamp":1}`)
+	conn.in <- []byte(`{"type":"Order","data":{"id":1,"account-number":"5YZ55555","status":"Filled","updated-at":200},"timestamp":2}`)
+
+	select {
+	case tr := <-transitions:
+		require.Equal(t, Live, tr.From)
+		require.Equal(t, Filled, tr.To)
+	case <-time.After(time.Second):
+		t.Fatal("no transition streamed")
+	}
+}
+
+func TestOrderTrackerRefreshError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.NewOrderTracker("5YZ55555").Refresh()
+	expectedUnauthorized(t, err)
+}
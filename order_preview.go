@@ -0,0 +1,246 @@
+package tasty
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shopspring/decimal"
+)
+
+// PreviewConfig configures order previews.
+type PreviewConfig struct {
+	// Maximum number of dry runs in flight. Defaults to 8.
+	Concurrency int
+	// Multipliers of the credit by instrument type, 100 for options and 1
+	// otherwise by default.
+	Multipliers map[InstrumentType]decimal.Decimal
+}
+
+// OrderPreviewRow is the dry run of one candidate order.
+type OrderPreviewRow struct {
+	// Position of the order in the candidates
+	Index int      `json:"index"`
+	Name  string   `json:"name"`
+	Order NewOrder `json:"order"`
+	// Change in buying power, negative when the order uses buying power
+	BuyingPowerChange decimal.Decimal `json:"buying-power-change"`
+	IsolatedMargin    decimal.Decimal `json:"isolated-margin"`
+	// Total fees, negative when credited
+	Fees decimal.Decimal `json:"fees"`
+	// Credit received for credit orders with a price, zero otherwise
+	Credit decimal.Decimal `json:"credit"`
+	// Credit less fees over the buying power used i.e. 0.25 for 25%, known
+	// for credit orders using buying power
+	ReturnOnCapital decimal.NullDecimal `json:"return-on-capital"`
+	Warnings        []string            `json:"warnings"`
+	// Errors of the dry run, the request error included
+	Errors   []string      `json:"errors"`
+	Response OrderResponse `json:"-"`
+	Err      error         `json:"-"`
+}
+
+// OrderPreview compares the dry runs of candidate orders.
+type OrderPreview struct {
+	Rows []OrderPreviewRow
+}
+
+// PreviewOrders dry runs the candidate orders concurrently and compares their
+// buying power effects, fees and return on capital. Failed dry runs are
+// reported in their rows, the error is only set when the context is done
+// before every order was dry run.
+func (c *Client) PreviewOrders(ctx context.Context, accountNumber string, orders []NewOrder, config PreviewConfig) (OrderPreview, error) {
+	preview := OrderPreview{Rows: make([]OrderPreviewRow, len(orders))}
+
+	runBulk(len(orders), config.Concurrency, func(i int) {
+		if err := ctx.Err(); err != nil {
+			preview.Rows[i] = previewRow(i, orders[i], OrderResponse{}, nil, err, config)
+			return
+		}
+
+		resp, orderErr, _, err := c.SubmitOrderDryRun(accountNumber, orders[i])
+		preview.Rows[i] = previewRow(i, orders[i], resp, orderErr, err, config)
+	})
+
+	return preview, ctx.Err()
+}
+
+// Failed returns whether or not the dry run failed or reported errors.
+func (r OrderPreviewRow) Failed() bool {
+	return r.Err != nil || len(r.Errors) > 0
+}
+
+// SortBy returns the preview sorted by the column, failed dry runs last. When
+// sorting by return on capital, rows without one follow the rows with one.
+// Unknown columns sort the rows in the order of the candidates.
+func (p OrderPreview) SortBy(column PreviewColumn, descending bool) OrderPreview {
+	rows := append([]OrderPreviewRow(nil), p.Rows...)
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Failed() != b.Failed() {
+			return b.Failed()
+		}
+		if column == PreviewReturnOnCapital && a.ReturnOnCapital.Valid != b.ReturnOnCapital.Valid {
+			return a.ReturnOnCapital.Valid
+		}
+
+		cmp := comparePreviewRows(a, b, column)
+		if cmp == 0 {
+			return a.Index < b.Index
+		}
+		if descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	return OrderPreview{Rows: rows}
+}
+
+// WriteCSV writes the preview as CSV with a header row, warnings and errors
+// joined by semicolons and unknown returns on capital left empty.
+func (p OrderPreview) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		string(PreviewName), string(PreviewBuyingPowerChange), string(PreviewIsolatedMargin),
+		string(PreviewFees), string(PreviewCredit), string(PreviewReturnOnCapital), "warnings", "errors",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range p.Rows {
+		roc := ""
+		if row.ReturnOnCapital.Valid {
+			roc = row.ReturnOnCapital.Decimal.String()
+		}
+
+		record := []string{
+			row.Name, row.BuyingPowerChange.String(), row.IsolatedMargin.String(),
+			row.Fees.String(), row.Credit.String(), roc,
+			strings.Join(row.Warnings, "; "), strings.Join(row.Errors, "; "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// WriteJSON writes the rows of the preview as a JSON array.
+func (p OrderPreview) WriteJSON(w io.Writer) error {
+	rows := p.Rows
+	if rows == nil {
+		rows = []OrderPreviewRow{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(rows)
+}
+
+// String returns the preview as an aligned table.
+func (p OrderPreview) String() string {
+	var sb strings.Builder
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tOrder\tBuying Power\tMargin\tFees\tCredit\tReturn\tNotes")
+
+	for _, row := range p.Rows {
+		roc := "-"
+		if row.ReturnOnCapital.Valid {
+			roc = row.ReturnOnCapital.Decimal.Mul(decimal.NewFromInt(100)).StringFixed(2) + "%"
+		}
+
+		notes := make([]string, 0, len(row.Errors)+len(row.Warnings))
+		for _, message := range row.Errors {
+			notes = append(notes, "error: "+message)
+		}
+		notes = append(notes, row.Warnings...)
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", row.Index+1, row.Name,
+			row.BuyingPowerChange.StringFixed(2), row.IsolatedMargin.StringFixed(2), row.Fees.StringFixed(2),
+			row.Credit.StringFixed(2), roc, strings.Join(notes, "; "))
+	}
+
+	_ = tw.Flush()
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// previewRow compares the dry run of the order.
+func previewRow(i int, order NewOrder, resp OrderResponse, orderErr *OrderErrorResponse, err error, config PreviewConfig) OrderPreviewRow {
+	row := OrderPreviewRow{Index: i, Name: order.String(), Order: order, Response: resp, Err: err}
+
+	if err != nil {
+		row.Errors = append(row.Errors, err.Error())
+		return row
+	}
+
+	for _, info := range resp.Warnings {
+		row.Warnings = append(row.Warnings, info.Message)
+	}
+	for _, info := range resp.Errors {
+		row.Errors = append(row.Errors, info.Message)
+	}
+	if orderErr != nil {
+		row.Errors = append(row.Errors, orderErr.Message)
+		for _, info := range orderErr.Errors {
+			row.Errors = append(row.Errors, info.Message)
+		}
+	}
+
+	bpe := resp.BuyingPowerEffect
+	row.BuyingPowerChange = effectAmount(bpe.ChangeInBuyingPower, bpe.ChangeInBuyingPowerEffect)
+	row.IsolatedMargin = bpe.IsolatedOrderMarginRequirement.Abs()
+	row.Fees = effectAmount(resp.FeeCalculation.TotalFees, resp.FeeCalculation.TotalFeesEffect).Neg()
+
+	if order.PriceEffect == Credit && order.Price.IsPositive() {
+		row.Credit = order.Price.Mul(orderUnits(order)).Mul(orderMultiplier(order, config.Multipliers))
+
+		if used := row.BuyingPowerChange.Neg(); used.IsPositive() && !row.Failed() {
+			row.ReturnOnCapital = decimal.NewNullDecimal(row.Credit.Sub(row.Fees).Div(used).Round(4))
+		}
+	}
+
+	return row
+}
+
+// effectAmount returns the amount signed by its effect, negative for debits.
+func effectAmount(amount decimal.Decimal, effect PriceEffect) decimal.Decimal {
+	if effect == Debit {
+		return amount.Abs().Neg()
+	}
+
+	return amount.Abs()
+}
+
+func comparePreviewRows(a, b OrderPreviewRow, column PreviewColumn) int {
+	switch column {
+	case PreviewName:
+		return strings.Compare(a.Name, b.Name)
+	case PreviewBuyingPowerChange:
+		return a.BuyingPowerChange.Cmp(b.BuyingPowerChange)
+	case PreviewIsolatedMargin:
+		return a.IsolatedMargin.Cmp(b.IsolatedMargin)
+	case PreviewFees:
+		return a.Fees.Cmp(b.Fees)
+	case PreviewCredit:
+		return a.Credit.Cmp(b.Credit)
+	case PreviewReturnOnCapital:
+		return a.ReturnOnCapital.Decimal.Cmp(b.ReturnOnCapital.Decimal)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,187 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func previewCondor(width int64, price string) NewOrder {
+	w := decimal.NewFromInt(width)
+	option := func(optionType string, strike decimal.Decimal, action OrderAction) NewOrderLeg {
+		symbol := fmt.Sprintf("SPY   230915%s%08d", optionType, strike.IntPart()*1000)
+		return NewOrderLeg{InstrumentType: EquityOptionIT, Symbol: symbol, Quantity: decimal.NewFromInt(1), Action: action}
+	}
+
+	return NewOrder{
+		TimeInForce: Day,
+		OrderType:   Limit,
+		Price:       decimal.RequireFromString(price),
+		PriceEffect: Credit,
+		Legs: []NewOrderLeg{
+			option("P", decimal.NewFromInt(420).Sub(w), BTO),
+			option("P", decimal.NewFromInt(420), STO),
+			option("C", decimal.NewFromInt(440), STO),
+			option("C", decimal.NewFromInt(440).Add(w), BTO),
+		},
+	}
+}
+
+func previewServer(t *testing.T) {
+	t.Helper()
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/dry-run", func(writer http.ResponseWriter, request *http.Request) {
+		var order NewOrder
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&order))
+
+		var change string
+		switch order.Price.String() {
+		case "1":
+			change = "400"
+		case "1.5":
+			change = "850"
+		case "2":
+			change = "1300"
+		case "2.5":
+			change = "250"
+		default:
+			fmt.Fprint(writer, `{"data":{},"error":{"code":"preflight_check_failure","message":"Order is invalid",
+				"errors":[{"code":"invalid_price","message":"Price is too far from the mark"}]}}`)
+			return
+		}
+
+		fmt.Fprintf(writer, `{"data":{"order":{"status":"Received"},"warnings":[{"code":"late","message":"Market closes soon"}],
+			"buying-power-effect":{"change-in-buying-power":%q,"change-in-buying-power-effect":"Debit",
+				"isolated-order-margin-requirement":%q,"isolated-order-margin-requirement-effect":"Debit"},
+			"fee-calculation":{"total-fees":"4.52","total-fees-effect":"Debit"}}}`, change, change)
+	})
+}
+
+func previewCandidates() []NewOrder {
+	call := NewOrder{
+		TimeInForce: Day,
+		OrderType:   Limit,
+		Price:       decimal.RequireFromString("2.5"),
+		PriceEffect: Debit,
+		Legs:        []NewOrderLeg{{InstrumentType: EquityOptionIT, Symbol: "SPY   230915C00440000", Quantity: decimal.NewFromInt(1), Action: BTO}},
+	}
+
+	return []NewOrder{previewCondor(5, "1.00"), previewCondor(10, "1.50"), previewCondor(15, "2.00"), call, previewCondor(20, "9.99")}
+}
+
+func TestPreviewOrders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	previewServer(t)
+
+	preview, err := client.PreviewOrders(context.Background(), "5YZ55555", previewCandidates(), PreviewConfig{Concurrency: 2})
+	require.NoError(t, err)
+	require.Len(t, preview.Rows, 5)
+
+	narrow := preview.Rows[0]
+	require.Equal(t, 0, narrow.Index)
+	require.Equal(t, "STO 1 SPY Sep15'23 415/420/440/445 Iron Condor @ 1.00 Credit Day", narrow.Name)
+	require.Equal(t, "-400", narrow.BuyingPowerChange.String())
+	require.Equal(t, "400", narrow.IsolatedMargin.String())
+	require.Equal(t, "4.52", narrow.Fees.String())
+	require.Equal(t, "100", narrow.Credit.String())
+	require.True(t, narrow.ReturnOnCapital.Valid)
+	require.Equal(t, "0.2387", narrow.ReturnOnCapital.Decimal.String())
+	require.Equal(t, []string{"Market closes soon"}, narrow.Warnings)
+	require.False(t, narrow.Failed())
+
+	require.Equal(t, "0.1712", preview.Rows[1].ReturnOnCapital.Decimal.String())
+	require.Equal(t, "0.1504", preview.Rows[2].ReturnOnCapital.Decimal.String())
+
+	debit := preview.Rows[3]
+	require.False(t, debit.ReturnOnCapital.Valid)
+	require.True(t, debit.Credit.IsZero())
+
+	rejected := preview.Rows[4]
+	require.True(t, rejected.Failed())
+	require.Equal(t, []string{"Order is invalid", "Price is too far from the mark"}, rejected.Errors)
+	require.False(t, rejected.ReturnOnCapital.Valid)
+
+	indexes := func(p OrderPreview) []int {
+		indexes := make([]int, 0, len(p.Rows))
+		for _, row := range p.Rows {
+			indexes = append(indexes, row.Index)
+		}
+		return indexes
+	}
+
+	require.Equal(t, []int{0, 1, 2, 3, 4}, indexes(preview.SortBy(PreviewReturnOnCapital, true)))
+	require.Equal(t, []int{2, 1, 0, 3, 4}, indexes(preview.SortBy(PreviewReturnOnCapital, false)))
+	require.Equal(t, []int{3, 0, 1, 2, 4}, indexes(preview.SortBy(PreviewBuyingPowerChange, true)))
+	require.Equal(t, []int{3, 0, 1, 2, 4}, indexes(preview.SortBy(PreviewIsolatedMargin, false)))
+	require.Equal(t, []int{2, 1, 0, 3, 4}, indexes(preview.SortBy(PreviewCredit, true)))
+	require.Equal(t, []int{3, 2, 1, 0, 4}, indexes(preview.SortBy(PreviewName, false)))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, indexes(preview.SortBy(PreviewFees, false)))
+
+	// sorting doesn't change the preview
+	require.Equal(t, []int{0, 1, 2, 3, 4}, indexes(preview))
+}
+
+func TestOrderPreviewExport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	previewServer(t)
+
+	candidates := previewCandidates()
+	preview, err := client.PreviewOrders(context.Background(), "5YZ55555", []NewOrder{candidates[0], candidates[3], candidates[4]}, PreviewConfig{})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, preview.WriteCSV(&buf))
+	require.Equal(t, `name,buying-power-change,isolated-margin,fees,credit,return-on-capital,warnings,errors
+STO 1 SPY Sep15'23 415/420/440/445 Iron Condor @ 1.00 Credit Day,-400,400,4.52,100,0.2387,Market closes soon,
+BTO 1 SPY Sep15'23 440 Call @ 2.50 Debit Day,-250,250,4.52,0,,Market closes soon,
+STO 1 SPY Sep15'23 400/420/440/460 Iron Condor @ 9.99 Credit Day,0,0,0,999,,,Order is invalid; Price is too far from the mark
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, preview.WriteJSON(&buf))
+
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rows))
+	require.Len(t, rows, 3)
+	require.Equal(t, "0.2387", rows[0]["return-on-capital"])
+	require.Nil(t, rows[1]["return-on-capital"])
+	require.Equal(t, "-250", rows[1]["buying-power-change"])
+	require.Equal(t, []any{"Order is invalid", "Price is too far from the mark"}, rows[2]["errors"])
+	require.Equal(t, "Credit", rows[0]["order"].(map[string]any)["price-effect"])
+
+	buf.Reset()
+	require.NoError(t, OrderPreview{}.WriteJSON(&buf))
+	require.Equal(t, "[]\n", buf.String())
+
+	require.Equal(t, `#  Order                                                             Buying Power  Margin  Fees  Credit  Return  Notes
+1  STO 1 SPY Sep15'23 415/420/440/445 Iron Condor @ 1.00 Credit Day  -400.00       400.00  4.52  100.00  23.87%  Market closes soon
+2  BTO 1 SPY Sep15'23 440 Call @ 2.50 Debit Day                      -250.00       250.00  4.52  0.00    -       Market closes soon
+3  STO 1 SPY Sep15'23 400/420/440/460 Iron Condor @ 9.99 Credit Day  0.00          0.00    0.00  999.00  -       error: Order is invalid; error: Price is too far from the mark`,
+		preview.String())
+}
+
+func TestPreviewOrdersCancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	previewServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	preview, err := client.PreviewOrders(ctx, "5YZ55555", previewCandidates()[:2], PreviewConfig{})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, preview.Rows, 2)
+	require.ErrorIs(t, preview.Rows[0].Err, context.Canceled)
+	require.Equal(t, []string{"context canceled"}, preview.Rows[1].Errors)
+}
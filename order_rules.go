@@ -0,0 +1,316 @@
+package tasty
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Layout of the timestamps sent to the API in order rules.
+const apiTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// ErrInvalidRule is returned when order rules fail local validation.
+var ErrInvalidRule = errors.New("invalid order rule")
+
+// Eastern time used by the US markets, loaded on first use.
+var (
+	marketLocationOnce sync.Once
+	marketLoc          *time.Location
+	marketLocErr       error
+)
+
+// MarshalJSON formats the times in the API's format and omits unset ones.
+func (r NewOrderRules) MarshalJSON() ([]byte, error) {
+	type rules struct {
+		RouteAfter string              `json:"route-after,omitempty"`
+		CancelAt   string              `json:"cancel-at,omitempty"`
+		Conditions []NewOrderCondition `json:"conditions,omitempty"`
+	}
+
+	return json.Marshal(rules{
+		RouteAfter: formatAPITime(r.RouteAfter),
+		CancelAt:   formatAPITime(r.CancelAt),
+		Conditions: r.Conditions,
+	})
+}
+
+// UnmarshalJSON parses the times allowing them to be empty.
+func (r *NewOrderRules) UnmarshalJSON(data []byte) error {
+	var rules struct {
+		RouteAfter string              `json:"route-after"`
+		CancelAt   string              `json:"cancel-at"`
+		Conditions []NewOrderCondition `json:"conditions"`
+	}
+
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+
+	var err error
+	if r.RouteAfter, err = parseAPITime(rules.RouteAfter); err != nil {
+		return err
+	}
+	if r.CancelAt, err = parseAPITime(rules.CancelAt); err != nil {
+		return err
+	}
+	r.Conditions = rules.Conditions
+
+	return nil
+}
+
+// UnmarshalJSON parses the times allowing them to be empty.
+func (r *OrderRules) UnmarshalJSON(data []byte) error {
+	var rules struct {
+		RouteAfter  string           `json:"route-after"`
+		RoutedAt    string           `json:"routed-at"`
+		CancelAt    string           `json:"cancel-at"`
+		CancelledAt string           `json:"cancelled-at"`
+		Conditions  []OrderCondition `json:"conditions"`
+	}
+
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+
+	times := []struct {
+		value string
+		field *time.Time
+	}{
+		{rules.RouteAfter, &r.RouteAfter},
+		{rules.RoutedAt, &r.RoutedAt},
+		{rules.CancelAt, &r.CancelAt},
+		{rules.CancelledAt, &r.CancelledAt},
+	}
+
+	for _, t := range times {
+		parsed, err := parseAPITime(t.value)
+		if err != nil {
+			return err
+		}
+		*t.field = parsed
+	}
+	r.Conditions = rules.Conditions
+
+	return nil
+}
+
+// UnmarshalJSON parses the triggered at time allowing it to be empty.
+func (oc *OrderCondition) UnmarshalJSON(data []byte) error {
+	type condition OrderCondition
+
+	var c struct {
+		condition
+		TriggeredAt string `json:"triggered-at"`
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+
+	triggeredAt, err := parseAPITime(c.TriggeredAt)
+	if err != nil {
+		return err
+	}
+
+	*oc = OrderCondition(c.condition)
+	oc.TriggeredAt = triggeredAt
+
+	return nil
+}
+
+// Validate checks the conditions use a supported action, indicator and
+// comparator with a positive threshold and that the order cancels after it
+// routes.
+func (r NewOrderRules) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidRule}, args...)...))
+	}
+
+	if !r.RouteAfter.IsZero() && !r.CancelAt.IsZero() && !r.CancelAt.After(r.RouteAfter) {
+		invalid("cancel at %s must be after route after %s", formatAPITime(r.CancelAt), formatAPITime(r.RouteAfter))
+	}
+
+	for _, c := range r.Conditions {
+		if c.Symbol == "" {
+			invalid("condition symbol is required")
+		}
+		if c.InstrumentType == "" {
+			invalid("condition %s instrument type is required", c.Symbol)
+		}
+		if c.Action != Route && c.Action != Cancel {
+			invalid("condition %s action %q must be route or cancel", c.Symbol, c.Action)
+		}
+		if c.Indicator != Last {
+			invalid("condition %s indicator %q must be last", c.Symbol, c.Indicator)
+		}
+		if c.Comparator != GTE && c.Comparator != LTE {
+			invalid("condition %s comparator %q must be gte or lte", c.Symbol, c.Comparator)
+		}
+		if !c.Threshold.IsPositive() {
+			invalid("condition %s threshold must be positive", c.Symbol)
+		}
+	}
+
+	for i, a := range r.Conditions {
+		for _, b := range r.Conditions[i+1:] {
+			if a.Symbol != b.Symbol || a.Indicator != b.Indicator || a.Comparator != b.Comparator {
+				continue
+			}
+
+			route, cancel := a, b
+			switch {
+			case a.Action == b.Action:
+				invalid("duplicate %s condition on %s %s %s", a.Action, a.Symbol, a.Indicator, a.Comparator)
+				continue
+			case a.Action == Cancel:
+				route, cancel = b, a
+			}
+
+			if cancelsOnRoute(route, cancel) {
+				invalid("condition to %s when %s %s %s %s already holds when routing at %s",
+					cancel.Action, cancel.Symbol, cancel.Indicator, cancel.Comparator, cancel.Threshold, route.Threshold)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// cancelsOnRoute returns whether or not the cancel condition holds whenever
+// the route condition does, the order being cancelled as soon as it routes.
+func cancelsOnRoute(route, cancel NewOrderCondition) bool {
+	if route.Action != Route || cancel.Action != Cancel {
+		return false
+	}
+
+	switch route.Comparator {
+	case GTE:
+		return !cancel.Threshold.GreaterThan(route.Threshold)
+	case LTE:
+		return !cancel.Threshold.LessThan(route.Threshold)
+	default:
+		return false
+	}
+}
+
+// RuleBuilder builds validated NewOrderRules i.e. route when SPY last >= 450
+// and cancel at 15:45 ET:
+//
+//	cancelAt, err := tasty.MarketTime(time.Now(), 15, 45)
+//	...
+//	rules, err := tasty.NewRuleBuilder().
+//		RouteWhen("SPY", tasty.GTE, decimal.NewFromInt(450)).
+//		CancelAt(cancelAt).
+//		Build()
+type RuleBuilder struct {
+	rules NewOrderRules
+}
+
+// NewRuleBuilder creates an empty rule builder.
+func NewRuleBuilder() *RuleBuilder {
+	return &RuleBuilder{}
+}
+
+// RouteWhen routes the order once the last price of the symbol compares to the threshold.
+func (b *RuleBuilder) RouteWhen(symbol string, comparator Comparator, threshold decimal.Decimal) *RuleBuilder {
+	return b.Condition(Route, symbol, Last, comparator, threshold)
+}
+
+// CancelWhen cancels the order once the last price of the symbol compares to the threshold.
+func (b *RuleBuilder) CancelWhen(symbol string, comparator Comparator, threshold decimal.Decimal) *RuleBuilder {
+	return b.Condition(Cancel, symbol, Last, comparator, threshold)
+}
+
+// Condition adds a condition on the symbol priced as a single long unit.
+// The instrument type is inferred from the symbol.
+func (b *RuleBuilder) Condition(action OrderRuleAction, symbol string, indicator Indicator,
+	comparator Comparator, threshold decimal.Decimal) *RuleBuilder {
+	instrumentType := InstrumentTypeOf(symbol)
+
+	b.rules.Conditions = append(b.rules.Conditions, NewOrderCondition{
+		Action:         action,
+		Symbol:         symbol,
+		InstrumentType: instrumentType,
+		Indicator:      indicator,
+		Comparator:     comparator,
+		Threshold:      threshold,
+		PriceComponents: []NewOrderPriceComponent{{
+			Symbol:            symbol,
+			InstrumentType:    instrumentType,
+			Quantity:          decimal.NewFromInt(1),
+			QuantityDirection: Long,
+		}},
+	})
+
+	return b
+}
+
+// RouteAfter holds the order until the time.
+func (b *RuleBuilder) RouteAfter(t time.Time) *RuleBuilder {
+	b.rules.RouteAfter = t
+	return b
+}
+
+// CancelAt cancels the order at the time.
+func (b *RuleBuilder) CancelAt(t time.Time) *RuleBuilder {
+	b.rules.CancelAt = t
+	return b
+}
+
+// Build validates and returns the rules.
+func (b *RuleBuilder) Build() (NewOrderRules, error) {
+	rules := b.rules
+	rules.Conditions = append([]NewOrderCondition(nil), b.rules.Conditions...)
+
+	if err := rules.Validate(); err != nil {
+		return NewOrderRules{}, err
+	}
+
+	return rules, nil
+}
+
+// MarketTime returns the hour and minute in US eastern time on the date's
+// day i.e. 15:45 ET today is MarketTime(time.Now(), 15, 45). It fails when
+// the time zone database is unavailable, see marketLocation.
+func MarketTime(date time.Time, hour, minute int) (time.Time, error) {
+	loc, err := marketLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year, month, day := date.In(loc).Date()
+
+	return time.Date(year, month, day, hour, minute, 0, 0, loc), nil
+}
+
+// marketLocation loads eastern time from the system's time zone database once.
+// Applications running where there is none can embed it by importing time/tzdata.
+func marketLocation() (*time.Location, error) {
+	marketLocationOnce.Do(func() {
+		marketLoc, marketLocErr = time.LoadLocation("America/New_York")
+		if marketLocErr != nil {
+			marketLocErr = fmt.Errorf("loading market time zone: %w", marketLocErr)
+		}
+	})
+
+	return marketLoc, marketLocErr
+}
+
+func formatAPITime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(apiTimeLayout)
+}
+
+func parseAPITime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,150 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOrderRulesMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(NewOrderRules{})
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(b))
+
+	rules, err := NewRuleBuilder().
+		RouteWhen("SPY", GTE, decimal.NewFromInt(450)).
+		RouteAfter(time.Date(2023, 9, 1, 13, 30, 0, 0, time.UTC)).
+		CancelAt(time.Date(2023, 9, 1, 19, 45, 0, 0, time.UTC)).
+		Build()
+	require.NoError(t, err)
+
+	b, err = json.Marshal(rules)
+	require.NoError(t, err)
+
+	var sent map[string]any
+	require.NoError(t, json.Unmarshal(b, &sent))
+	require.Equal(t, "2023-09-01T13:30:00.000Z", sent["route-after"])
+	require.Equal(t, "2023-09-01T19:45:00.000Z", sent["cancel-at"])
+
+	condition := sent["conditions"].([]any)[0].(map[string]any)
+	require.Equal(t, "route", condition["action"])
+	require.Equal(t, "Equity", condition["instrument-type"])
+	require.Equal(t, 450.0, condition["threshold"])
+
+	var decoded NewOrderRules
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.True(t, rules.RouteAfter.Equal(decoded.RouteAfter))
+	require.True(t, rules.CancelAt.Equal(decoded.CancelAt))
+	require.Equal(t, "450", decoded.Conditions[0].Threshold.String())
+}
+
+func TestOrderRulesUnmarshalJSON(t *testing.T) {
+	var rules OrderRules
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"route-after": "",
+		"routed-at": "2023-09-01T13:30:00.123+00:00",
+		"cancel-at": "2023-09-01T19:45:00.000Z",
+		"conditions": [{"id": 7, "action": "route", "triggered-at": "", "is-threshold-based-on-notional": false}]
+	}`), &rules))
+
+	require.True(t, rules.RouteAfter.IsZero())
+	require.Equal(t, time.Date(2023, 9, 1, 13, 30, 0, 123000000, time.UTC), rules.RoutedAt.UTC())
+	require.Equal(t, time.Date(2023, 9, 1, 19, 45, 0, 0, time.UTC), rules.CancelAt.UTC())
+	require.True(t, rules.CancelledAt.IsZero())
+	require.Len(t, rules.Conditions, 1)
+	require.Equal(t, 7, rules.Conditions[0].ID)
+	require.True(t, rules.Conditions[0].TriggeredAt.IsZero())
+
+	require.Error(t, json.Unmarshal([]byte(`{"cancel-at": "3:45pm"}`), &rules))
+	require.Error(t, json.Unmarshal([]byte(`{"triggered-at": "yesterday"}`), &OrderCondition{}))
+}
+
+func TestNewOrderRulesValidate(t *testing.T) {
+	require.NoError(t, NewOrderRules{}.Validate())
+
+	now := time.Now()
+	err := NewOrderRules{
+		RouteAfter: now,
+		CancelAt:   now.Add(-time.Minute),
+		Conditions: []NewOrderCondition{{Action: "hold", Indicator: "bid", Comparator: "gt"}},
+	}.Validate()
+
+	require.ErrorIs(t, err, ErrInvalidRule)
+	require.Contains(t, err.Error(), "must be after route after")
+	require.Contains(t, err.Error(), "condition symbol is required")
+	require.Contains(t, err.Error(), "instrument type is required")
+	require.Contains(t, err.Error(), `action "hold" must be route or cancel`)
+	require.Contains(t, err.Error(), `indicator "bid" must be last`)
+	require.Contains(t, err.Error(), `comparator "gt" must be gte or lte`)
+	require.Contains(t, err.Error(), "threshold must be positive")
+
+	// routing at 450 or above while cancelling at 440 or above never works
+	_, err = NewRuleBuilder().
+		RouteWhen("SPY", GTE, decimal.NewFromInt(450)).
+		CancelWhen("SPY", GTE, decimal.NewFromInt(440)).
+		Build()
+	require.ErrorIs(t, err, ErrInvalidRule)
+	require.EqualError(t, err, "invalid order rule: condition to cancel when SPY last gte 440 already holds when routing at 450")
+
+	_, err = NewRuleBuilder().
+		RouteWhen("SPY", LTE, decimal.NewFromInt(420)).
+		RouteWhen("SPY", LTE, decimal.NewFromInt(410)).
+		Build()
+	require.EqualError(t, err, "invalid order rule: duplicate route condition on SPY last lte")
+
+	// cancelling once the price runs away from the route trigger is fine
+	_, err = NewRuleBuilder().
+		RouteWhen("SPY", GTE, decimal.NewFromInt(450)).
+		CancelWhen("SPY", GTE, decimal.NewFromInt(460)).
+		CancelWhen("SPY", LTE, decimal.NewFromInt(400)).
+		Build()
+	require.NoError(t, err)
+}
+
+func TestRuleBuilder(t *testing.T) {
+	rules, err := NewRuleBuilder().
+		RouteWhen("/ESZ3", GTE, decimal.NewFromInt(4500)).
+		CancelWhen("SPY", LTE, decimal.NewFromInt(420)).
+		Build()
+	require.NoError(t, err)
+	require.Len(t, rules.Conditions, 2)
+
+	route := rules.Conditions[0]
+	require.Equal(t, Route, route.Action)
+	require.Equal(t, FutureIT, route.InstrumentType)
+	require.Equal(t, []NewOrderPriceComponent{
+		{Symbol: "/ESZ3", InstrumentType: FutureIT, Quantity: decimal.NewFromInt(1), QuantityDirection: Long},
+	}, route.PriceComponents)
+
+	cancel := rules.Conditions[1]
+	require.Equal(t, Cancel, cancel.Action)
+	require.Equal(t, EquityIT, cancel.InstrumentType)
+
+	_, err = NewRuleBuilder().RouteWhen("SPY", GTE, decimal.Zero).Build()
+	require.ErrorIs(t, err, ErrInvalidRule)
+}
+
+func TestMarketTime(t *testing.T) {
+	date := time.Date(2023, 9, 1, 2, 0, 0, 0, time.UTC)
+	cancelAt, err := MarketTime(date, 15, 45)
+	require.NoError(t, err)
+
+	// 2am UTC is still the previous day in New York
+	require.Equal(t, 31, cancelAt.Day())
+	require.Equal(t, 15, cancelAt.Hour())
+	require.Equal(t, 45, cancelAt.Minute())
+	require.Equal(t, "America/New_York", cancelAt.Location().String())
+}
+
+func TestValidateOrderRules(t *testing.T) {
+	order, err := NewOrderBuilder().Buy("AAPL", decimal.NewFromInt(1)).Limit(decimal.NewFromInt(150)).Debit().Build()
+	require.NoError(t, err)
+
+	order.Rules = NewOrderRules{Conditions: []NewOrderCondition{{Symbol: "AAPL"}}}
+	err = ValidateOrder(order)
+	require.ErrorIs(t, err, ErrInvalidOrder)
+	require.ErrorIs(t, err, ErrInvalidRule)
+}
@@ -0,0 +1,549 @@
+package tasty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// OrderSpec is a declarative order staged in a JSON or YAML file. It maps
+// onto a NewOrder, or onto a NewComplexOrder when Type is set, with legs given
+// by symbol or by a symbolic option reference resolved against the chain.
+type OrderSpec struct {
+	// Describes the spec in reports
+	Name string `json:"name"`
+	// Defaults to the AccountNumber of the SpecConfig
+	AccountNumber string          `json:"account-number"`
+	TimeInForce   TimeInForce     `json:"time-in-force"`
+	GtcDate       string          `json:"gtc-date"`
+	OrderType     OrderType       `json:"order-type"`
+	StopTrigger   decimal.Decimal `json:"stop-trigger"`
+	Price         decimal.Decimal `json:"price"`
+	PriceEffect   PriceEffect     `json:"price-effect"`
+	Value         decimal.Decimal `json:"value"`
+	ValueEffect   PriceEffect     `json:"value-effect"`
+	Legs          []LegSpec       `json:"legs"`
+	Rules         *NewOrderRules  `json:"rules"`
+	// OTO, OCO or OTOCO for complex orders
+	Type         ComplexOrderType `json:"type"`
+	TriggerOrder *OrderSpec       `json:"trigger-order"`
+	Orders       []OrderSpec      `json:"orders"`
+}
+
+// LegSpec is a leg of an order spec.
+type LegSpec struct {
+	InstrumentType InstrumentType `json:"instrument-type"`
+	Symbol         string         `json:"symbol"`
+	// Symbolic reference to an option, used instead of the symbol i.e.
+	// SPY 45DTE 30-delta put, see ParseOptionReference
+	Option   string          `json:"option"`
+	Quantity decimal.Decimal `json:"quantity"`
+	Action   OrderAction     `json:"action"`
+}
+
+// OptionReference is a parsed symbolic option reference.
+type OptionReference struct {
+	// Equity symbol or futures product i.e. SPY or /ES
+	Underlying string
+	// Days to expiration of the closest expiration, when Expiration is empty
+	DTE int
+	// Expiration date i.e. 2023-09-15
+	Expiration string
+	// Strike price, when Delta is zero
+	Strike decimal.Decimal
+	// Absolute delta of the closest strike i.e. 0.30
+	Delta      decimal.Decimal
+	OptionType OptionType
+}
+
+// SpecConfig configures resolving and loading order specs.
+type SpecConfig struct {
+	// Account of the specs without an account number.
+	AccountNumber string
+	// Live chains receiving greeks by underlying, required to resolve delta
+	// references. Other references are resolved against the option chain.
+	LiveChains map[string]*LiveChain
+	// Skips the dry runs of LoadOrderSpecs.
+	SkipDryRun bool
+}
+
+// ResolvedSpec is an order spec resolved to the order it submits.
+type ResolvedSpec struct {
+	AccountNumber string
+	// Set for single orders
+	Order *NewOrder
+	// Set for complex orders
+	ComplexOrder *NewComplexOrder
+}
+
+// SpecResult is the outcome of loading one spec file.
+type SpecResult struct {
+	Path     string
+	Spec     OrderSpec
+	Resolved ResolvedSpec
+	// The dry run of the resolved order
+	DryRun     OrderResponse
+	OrderError *OrderErrorResponse
+	// Set when the spec couldn't be read, resolved, validated or dry run
+	Err error
+}
+
+// SpecReport is the outcome of loading a directory of spec files.
+type SpecReport struct {
+	Results []SpecResult
+}
+
+// ParseOrderSpecJSON parses a JSON order spec. Unknown fields are rejected.
+func ParseOrderSpecJSON(data []byte) (OrderSpec, error) {
+	var spec OrderSpec
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return OrderSpec{}, err
+	}
+
+	return spec, nil
+}
+
+// ParseOrderSpecYAML parses a YAML order spec, which uses the field names of
+// the JSON spec. Unquoted dates and times are kept as written i.e. a GTC date
+// of 2023-09-15. Unknown fields are rejected.
+func ParseOrderSpecYAML(data []byte) (OrderSpec, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return OrderSpec{}, err
+	}
+	keepTimestamps(&node)
+
+	var doc any
+	if err := node.Decode(&doc); err != nil {
+		return OrderSpec{}, err
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return OrderSpec{}, err
+	}
+
+	return ParseOrderSpecJSON(b)
+}
+
+// keepTimestamps tags the timestamps of the YAML document as strings, so they
+// aren't decoded into times and reformatted.
+func keepTimestamps(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!timestamp" {
+		node.Tag = "!!str"
+	}
+
+	for _, child := range node.Content {
+		keepTimestamps(child)
+	}
+}
+
+// ReadOrderSpec reads the JSON or YAML order spec at the path, by its
+// .json, .yaml or .yml extension.
+func ReadOrderSpec(path string) (OrderSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return OrderSpec{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseOrderSpecJSON(data)
+	case ".yaml", ".yml":
+		return ParseOrderSpecYAML(data)
+	default:
+		return OrderSpec{}, fmt.Errorf("unknown order spec format: %s", path)
+	}
+}
+
+// ParseOptionReference parses a symbolic option reference of the underlying
+// followed by an expiration, a strike and the option type in any order i.e.
+// SPY 45DTE 30-delta put or /ES 2023-09-15 4300 call. Expirations are days
+// to expiration (45DTE) or dates, strikes are prices or deltas in points from
+// 1 to 99 (30-delta for a delta of 0.30).
+func ParseOptionReference(ref string) (OptionReference, error) {
+	fields := strings.Fields(ref)
+	if len(fields) != 4 {
+		return OptionReference{}, fmt.Errorf("option reference %q needs an underlying, expiration, strike and option type", ref)
+	}
+
+	or := OptionReference{Underlying: strings.ToUpper(fields[0]), DTE: -1}
+	for _, field := range fields[1:] {
+		lower := strings.ToLower(field)
+
+		switch {
+		case lower == "put" || lower == "p":
+			or.OptionType = Put
+		case lower == "call" || lower == "c":
+			or.OptionType = Call
+		case strings.HasSuffix(lower, "dte"):
+			dte, err := strconv.Atoi(strings.TrimSuffix(lower, "dte"))
+			if err != nil || dte < 0 {
+				return OptionReference{}, fmt.Errorf("invalid days to expiration %q in option reference %q", field, ref)
+			}
+			or.DTE = dte
+		case strings.HasSuffix(lower, "-delta"):
+			delta, err := decimal.NewFromString(strings.TrimSuffix(lower, "-delta"))
+			if err != nil || delta.LessThan(decimal.NewFromInt(1)) || delta.GreaterThan(decimal.NewFromInt(99)) {
+				return OptionReference{}, fmt.Errorf("invalid delta %q in option reference %q, deltas are points from 1 to 99", field, ref)
+			}
+			or.Delta = delta.Shift(-2)
+		case strings.Count(lower, "-") == 2:
+			if _, err := time.Parse("2006-01-02", lower); err != nil {
+				return OptionReference{}, fmt.Errorf("invalid expiration %q in option reference %q", field, ref)
+			}
+			or.Expiration = lower
+		default:
+			strike, err := decimal.NewFromString(field)
+			if err != nil || !strike.IsPositive() {
+				return OptionReference{}, fmt.Errorf("unknown term %q in option reference %q", field, ref)
+			}
+			or.Strike = strike
+		}
+	}
+
+	switch {
+	case or.OptionType == "":
+		return OptionReference{}, fmt.Errorf("option reference %q has no option type", ref)
+	case or.DTE < 0 && or.Expiration == "":
+		return OptionReference{}, fmt.Errorf("option reference %q has no expiration", ref)
+	case or.Strike.IsZero() && or.Delta.IsZero():
+		return OptionReference{}, fmt.Errorf("option reference %q has no strike", ref)
+	}
+
+	return or, nil
+}
+
+// ResolveOrderSpec resolves the option references of the spec against the
+// option chains and validates the resulting order.
+func (c *Client) ResolveOrderSpec(spec OrderSpec, config SpecConfig) (ResolvedSpec, error) {
+	r := &specResolver{client: c, config: config, chains: map[string]StrategyChain{}}
+
+	return r.resolve(spec)
+}
+
+// LoadOrderSpecs reads, resolves, validates and dry runs every .json, .yaml
+// and .yml spec in the directory, in name order. Nothing is submitted; the
+// report holds the outcome of each spec, the error is only for reading the
+// directory.
+func (c *Client) LoadOrderSpecs(dir string, config SpecConfig) (SpecReport, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return SpecReport{}, err
+	}
+
+	r := &specResolver{client: c, config: config, chains: map[string]StrategyChain{}}
+
+	var report SpecReport
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		result := SpecResult{Path: filepath.Join(dir, entry.Name())}
+		report.Results = append(report.Results, result)
+		res := &report.Results[len(report.Results)-1]
+
+		if res.Spec, res.Err = ReadOrderSpec(res.Path); res.Err != nil {
+			continue
+		}
+		if res.Resolved, res.Err = r.resolve(res.Spec); res.Err != nil || config.SkipDryRun {
+			continue
+		}
+
+		if res.Resolved.ComplexOrder != nil {
+			res.DryRun, res.OrderError, _, res.Err = c.SubmitComplexOrderDryRun(res.Resolved.AccountNumber, *res.Resolved.ComplexOrder)
+		} else {
+			res.DryRun, res.OrderError, _, res.Err = c.SubmitOrderDryRun(res.Resolved.AccountNumber, *res.Resolved.Order)
+		}
+	}
+
+	return report, nil
+}
+
+// OK returns whether or not the spec resolved and passed its dry run.
+func (sr SpecResult) OK() bool {
+	return sr.Err == nil && sr.OrderError == nil && len(sr.DryRun.Errors) == 0
+}
+
+// String describes the spec's order and the outcome of its dry run.
+func (sr SpecResult) String() string {
+	name := sr.Path
+	if sr.Spec.Name != "" {
+		name = fmt.Sprintf("%s (%s)", sr.Spec.Name, sr.Path)
+	}
+
+	lines := []string{name}
+
+	switch {
+	case sr.Resolved.Order != nil:
+		lines = append(lines, "  "+sr.Resolved.Order.String())
+	case sr.Resolved.ComplexOrder != nil:
+		co := sr.Resolved.ComplexOrder
+		if co.TriggerOrder != nil {
+			lines = append(lines, fmt.Sprintf("  %s trigger: %s", co.Type, co.TriggerOrder))
+		}
+		for _, order := range co.Orders {
+			lines = append(lines, fmt.Sprintf("  %s order: %s", co.Type, order))
+		}
+	}
+
+	if sr.Err != nil {
+		lines = append(lines, "  error: "+sr.Err.Error())
+	}
+	if sr.OrderError != nil {
+		lines = append(lines, "  rejected: "+sr.OrderError.Message)
+	}
+	for _, info := range sr.DryRun.Errors {
+		lines = append(lines, "  rejected: "+info.Message)
+	}
+	for _, info := range sr.DryRun.Warnings {
+		lines = append(lines, "  warning: "+info.Message)
+	}
+
+	if bp := sr.DryRun.BuyingPowerEffect; !bp.ChangeInBuyingPower.IsZero() {
+		lines = append(lines, fmt.Sprintf("  buying power change: %s %s", bp.ChangeInBuyingPower.StringFixed(2), bp.ChangeInBuyingPowerEffect))
+	}
+
+	if sr.OK() {
+		lines = append(lines, "  OK")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// OK returns whether or not every spec resolved and passed its dry run.
+func (r SpecReport) OK() bool {
+	for _, result := range r.Results {
+		if !result.OK() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Err joins the errors and rejections of the specs.
+func (r SpecReport) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", result.Path, result.Err))
+		case result.OrderError != nil:
+			errs = append(errs, fmt.Errorf("%s: %s", result.Path, result.OrderError.Message))
+		case len(result.DryRun.Errors) > 0:
+			errs = append(errs, fmt.Errorf("%s: %s", result.Path, result.DryRun.Errors[0].Message))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// String reports every spec, for review before the orders are sent.
+func (r SpecReport) String() string {
+	results := make([]string, 0, len(r.Results))
+	for _, result := range r.Results {
+		results = append(results, result.String())
+	}
+
+	return strings.Join(results, "\n")
+}
+
+// specResolver resolves specs, loading each option chain once.
+type specResolver struct {
+	client *Client
+	config SpecConfig
+	chains map[string]StrategyChain
+}
+
+func (r *specResolver) resolve(spec OrderSpec) (ResolvedSpec, error) {
+	resolved := ResolvedSpec{AccountNumber: spec.AccountNumber}
+	if resolved.AccountNumber == "" {
+		resolved.AccountNumber = r.config.AccountNumber
+	}
+	if resolved.AccountNumber == "" {
+		return ResolvedSpec{}, errors.New("order spec has no account number")
+	}
+
+	if spec.Type == "" {
+		if spec.TriggerOrder != nil || len(spec.Orders) > 0 {
+			return ResolvedSpec{}, errors.New("order spec with trigger or orders needs a complex order type")
+		}
+
+		order, err := r.order(spec)
+		if err != nil {
+			return ResolvedSpec{}, err
+		}
+		resolved.Order = &order
+
+		return resolved, nil
+	}
+
+	if len(spec.Legs) > 0 {
+		return ResolvedSpec{}, fmt.Errorf("%s order spec has legs, put them in its orders", spec.Type)
+	}
+
+	co := NewComplexOrder{Type: spec.Type}
+	if spec.TriggerOrder != nil {
+		trigger, err := r.order(*spec.TriggerOrder)
+		if err != nil {
+			return ResolvedSpec{}, fmt.Errorf("trigger order: %w", err)
+		}
+		co.TriggerOrder = &trigger
+	}
+
+	for i, orderSpec := range spec.Orders {
+		order, err := r.order(orderSpec)
+		if err != nil {
+			return ResolvedSpec{}, fmt.Errorf("order %d: %w", i+1, err)
+		}
+		co.Orders = append(co.Orders, order)
+	}
+
+	if err := co.Validate(); err != nil {
+		return ResolvedSpec{}, err
+	}
+	resolved.ComplexOrder = &co
+
+	return resolved, nil
+}
+
+// order resolves the legs of a single order spec and validates the order.
+func (r *specResolver) order(spec OrderSpec) (NewOrder, error) {
+	if spec.Type != "" || spec.TriggerOrder != nil || len(spec.Orders) > 0 {
+		return NewOrder{}, errors.New("complex orders can't be nested")
+	}
+
+	order := NewOrder{
+		TimeInForce: spec.TimeInForce,
+		GtcDate:     spec.GtcDate,
+		OrderType:   spec.OrderType,
+		StopTrigger: spec.StopTrigger,
+		Price:       spec.Price,
+		PriceEffect: spec.PriceEffect,
+		Value:       spec.Value,
+		ValueEffect: spec.ValueEffect,
+	}
+	if spec.Rules != nil {
+		order.Rules = *spec.Rules
+	}
+
+	for i, legSpec := range spec.Legs {
+		leg, err := r.leg(legSpec)
+		if err != nil {
+			return NewOrder{}, fmt.Errorf("leg %d: %w", i+1, err)
+		}
+		order.Legs = append(order.Legs, leg)
+	}
+
+	if err := ValidateOrder(order); err != nil {
+		return NewOrder{}, err
+	}
+
+	return order, nil
+}
+
+func (r *specResolver) leg(spec LegSpec) (NewOrderLeg, error) {
+	leg := NewOrderLeg{
+		InstrumentType: spec.InstrumentType,
+		Symbol:         spec.Symbol,
+		Quantity:       spec.Quantity,
+		Action:         spec.Action,
+	}
+
+	switch {
+	case spec.Option != "" && spec.Symbol != "":
+		return NewOrderLeg{}, errors.New("leg has both a symbol and an option reference")
+	case spec.Option == "" && spec.Symbol == "":
+		return NewOrderLeg{}, errors.New("leg needs a symbol or an option reference")
+	case spec.Option == "":
+		return leg, nil
+	}
+
+	ref, err := ParseOptionReference(spec.Option)
+	if err != nil {
+		return NewOrderLeg{}, err
+	}
+
+	option, err := r.option(ref)
+	if err != nil {
+		return NewOrderLeg{}, err
+	}
+
+	if leg.InstrumentType != "" && leg.InstrumentType != option.InstrumentType {
+		return NewOrderLeg{}, fmt.Errorf("option reference %q is a %s, not a %s", spec.Option, option.InstrumentType, leg.InstrumentType)
+	}
+
+	leg.InstrumentType = option.InstrumentType
+	leg.Symbol = option.Symbol
+
+	return leg, nil
+}
+
+// option finds the option of the reference in the chain of its underlying.
+func (r *specResolver) option(ref OptionReference) (StrategyLeg, error) {
+	sc, err := r.chain(ref.Underlying)
+	if err != nil {
+		return StrategyLeg{}, err
+	}
+
+	expiration := ClosestDTE(ref.DTE)
+	if ref.Expiration != "" {
+		expiration = ExpirationOn(ref.Expiration)
+	}
+
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return StrategyLeg{}, err
+	}
+
+	strike := AtStrike(ref.Strike)
+	if !ref.Delta.IsZero() {
+		live, ok := r.config.LiveChains[ref.Underlying]
+		if !ok {
+			return StrategyLeg{}, fmt.Errorf("delta reference needs a live chain of %s", ref.Underlying)
+		}
+		strike = ByDelta(live, ref.Delta)
+	}
+
+	return sc.option(exp, ref.OptionType, strike, "", 1)
+}
+
+func (r *specResolver) chain(underlying string) (StrategyChain, error) {
+	if sc, ok := r.chains[underlying]; ok {
+		return sc, nil
+	}
+
+	var sc StrategyChain
+	var err error
+	if strings.HasPrefix(underlying, "/") {
+		sc, err = r.client.LoadFuturesStrategyChain(strings.TrimPrefix(underlying, "/"))
+	} else {
+		sc, err = r.client.LoadEquityStrategyChain(underlying)
+	}
+	if err != nil {
+		return StrategyChain{}, err
+	}
+
+	r.chains[underlying] = sc
+
+	return sc, nil
+}
@@ -0,0 +1,292 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptionReference(t *testing.T) {
+	ref, err := ParseOptionReference("spy 45DTE 30-delta put")
+	require.NoError(t, err)
+	require.Equal(t, "SPY", ref.Underlying)
+	require.Equal(t, 45, ref.DTE)
+	require.Equal(t, "0.3", ref.Delta.String())
+	require.Equal(t, Put, ref.OptionType)
+
+	ref, err = ParseOptionReference("/ES call 4300 2023-09-15")
+	require.NoError(t, err)
+	require.Equal(t, OptionReference{
+		Underlying: "/ES",
+		DTE:        -1,
+		Expiration: "2023-09-15",
+		Strike:     decimal.NewFromInt(4300),
+		OptionType: Call,
+	}, ref)
+
+	ref, err = ParseOptionReference("AAPL 0dte 16-delta C")
+	require.NoError(t, err)
+	require.Equal(t, 0, ref.DTE)
+	require.Equal(t, "0.16", ref.Delta.String())
+
+	ref, err = ParseOptionReference("AAPL 0dte 1-delta C")
+	require.NoError(t, err)
+	require.Equal(t, "0.01", ref.Delta.String())
+
+	for ref, expected := range map[string]string{
+		"SPY 45DTE put":             `option reference "SPY 45DTE put" needs an underlying, expiration, strike and option type`,
+		"SPY 45DTE 420 430":         `option reference "SPY 45DTE 420 430" has no option type`,
+		"SPY 420 put call":          `option reference "SPY 420 put call" has no expiration`,
+		"SPY 45DTE put 45DTE":       `option reference "SPY 45DTE put 45DTE" has no strike`,
+		"SPY xDTE 420 put":          `invalid days to expiration "xDTE" in option reference "SPY xDTE 420 put"`,
+		"SPY 45DTE 200-delta put":   `invalid delta "200-delta" in option reference "SPY 45DTE 200-delta put", deltas are points from 1 to 99`,
+		"SPY 45DTE 0.3-delta put":   `invalid delta "0.3-delta" in option reference "SPY 45DTE 0.3-delta put", deltas are points from 1 to 99`,
+		"SPY 2023-13-01 420 put":    `invalid expiration "2023-13-01" in option reference "SPY 2023-13-01 420 put"`,
+		"SPY 45DTE atm put":         `unknown term "atm" in option reference "SPY 45DTE atm put"`,
+		"SPY 45DTE -420 put":        `unknown term "-420" in option reference "SPY 45DTE -420 put"`,
+		"SPY 45DTE 30-delta weekly": `unknown term "weekly" in option reference "SPY 45DTE 30-delta weekly"`,
+	} {
+		_, err = ParseOptionReference(ref)
+		require.EqualError(t, err, expected)
+	}
+}
+
+func TestParseOrderSpecYAML(t *testing.T) {
+	yamlSpec, err := ParseOrderSpecYAML([]byte(`
+name: short put
+account-number: 5YZ55555
+time-in-force: Day
+order-type: Limit
+price: 1.05
+price-effect: Credit
+gtc-date: 2023-09-15
+legs:
+  - option: SPY 45DTE 420 put
+    quantity: 2
+    action: Sell to Open
+rules:
+  route-after: 2023-09-01T13:30:00Z
+`))
+	require.NoError(t, err)
+
+	jsonSpec, err := ParseOrderSpecJSON([]byte(`{
+		"name": "short put",
+		"account-number": "5YZ55555",
+		"time-in-force": "Day",
+		"order-type": "Limit",
+		"price": "1.05",
+		"price-effect": "Credit",
+		"legs": [{"option": "SPY 45DTE 420 put", "quantity": 2, "action": "Sell to Open"}],
+		"rules": {"route-after": "2023-09-01T13:30:00Z"}
+	}`))
+	require.NoError(t, err)
+
+	require.Equal(t, jsonSpec.Legs, yamlSpec.Legs)
+	require.True(t, jsonSpec.Price.Equal(yamlSpec.Price))
+	require.True(t, jsonSpec.Rules.RouteAfter.Equal(yamlSpec.Rules.RouteAfter))
+	require.Equal(t, "short put", yamlSpec.Name)
+	require.Equal(t, "2023-09-15", yamlSpec.GtcDate)
+
+	_, err = ParseOrderSpecYAML([]byte("order-type: Limit\nprise: 1.05\n"))
+	require.EqualError(t, err, `json: unknown field "prise"`)
+
+	_, err = ParseOrderSpecYAML([]byte("legs: [\n"))
+	require.Error(t, err)
+}
+
+func TestResolveOrderSpec(t *testing.T) {
+	setup()
+	defer teardown()
+
+	chainRequests := 0
+	mux.HandleFunc("/option-chains/AAPL/nested", func(writer http.ResponseWriter, request *http.Request) {
+		chainRequests++
+		fmt.Fprint(writer, equityOptionChainsNestedResp)
+	})
+
+	live, err := client.LoadLiveChain(nil, "AAPL", nil)
+	require.NoError(t, err)
+	live.Apply(Greeks{EventSymbol: ".AAPL230616P60", Delta: decimal.RequireFromString("-0.18")})
+	live.Apply(Greeks{EventSymbol: ".AAPL230616P65", Delta: decimal.RequireFromString("-0.32")})
+
+	spec := OrderSpec{
+		TimeInForce: Day,
+		OrderType:   Limit,
+		Price:       decimal.RequireFromString("0.85"),
+		PriceEffect: Credit,
+		Legs: []LegSpec{
+			{Option: "AAPL 4DTE 30-delta put", Quantity: decimal.NewFromInt(1), Action: STO},
+			{Option: "AAPL 2023-06-16 60 put", Quantity: decimal.NewFromInt(1), Action: BTO},
+		},
+	}
+
+	config := SpecConfig{AccountNumber: "5YZ55555", LiveChains: map[string]*LiveChain{"AAPL": live}}
+	resolved, err := client.ResolveOrderSpec(spec, config)
+	require.NoError(t, err)
+	require.Equal(t, "5YZ55555", resolved.AccountNumber)
+	require.Nil(t, resolved.ComplexOrder)
+	require.Equal(t, "STO 1 AAPL Jun16'23 65/60 Put Vertical @ 0.85 Credit Day", resolved.Order.String())
+	require.Equal(t, EquityOptionIT, resolved.Order.Legs[0].InstrumentType)
+	require.Equal(t, 2, chainRequests)
+
+	_, err = client.ResolveOrderSpec(spec, SpecConfig{AccountNumber: "5YZ55555"})
+	require.EqualError(t, err, "leg 1: delta reference needs a live chain of AAPL")
+
+	_, err = client.ResolveOrderSpec(spec, SpecConfig{})
+	require.EqualError(t, err, "order spec has no account number")
+
+	spec.Legs[1].Option = "AAPL 4DTE 62.5 put"
+	_, err = client.ResolveOrderSpec(spec, config)
+	require.ErrorIs(t, err, ErrStrikeNotFound)
+
+	spec.Legs[1] = LegSpec{Symbol: "AAPL  230616P00060000", Option: "AAPL 4DTE 60 put", Quantity: decimal.NewFromInt(1), Action: BTO}
+	_, err = client.ResolveOrderSpec(spec, config)
+	require.EqualError(t, err, "leg 2: leg has both a symbol and an option reference")
+
+	spec.Legs[1] = LegSpec{InstrumentType: FutureOptionIT, Option: "AAPL 4DTE 60 put", Quantity: decimal.NewFromInt(1), Action: BTO}
+	_, err = client.ResolveOrderSpec(spec, config)
+	require.EqualError(t, err, `leg 2: option reference "AAPL 4DTE 60 put" is a Equity Option, not a Future Option`)
+
+	spec.Legs[1] = LegSpec{InstrumentType: EquityOptionIT, Symbol: "AAPL  230616P00060000", Quantity: decimal.NewFromInt(1), Action: Buy}
+	_, err = client.ResolveOrderSpec(spec, config)
+	require.ErrorIs(t, err, ErrInvalidOrder)
+}
+
+func writeSpec(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestLoadOrderSpecs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/option-chains/AAPL/nested", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, equityOptionChainsNestedResp)
+	})
+
+	var dryRuns []NewOrder
+	mux.HandleFunc("/accounts/5YZ55555/orders/dry-run", func(writer http.ResponseWriter, request *http.Request) {
+		var order NewOrder
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&order))
+		dryRuns = append(dryRuns, order)
+
+		if order.Legs[0].Symbol == "AAPL  230616C00065000" {
+			fmt.Fprint(writer, `{"data":{},"error":{"code":"preflight_check_failure","message":"Insufficient buying power"}}`)
+			return
+		}
+
+		fmt.Fprint(writer, `{"data":{"order":{"status":"Received"},"warnings":[{"message":"Order is outside market hours"}],
+			"buying-power-effect":{"change-in-buying-power":"430.0","change-in-buying-power-effect":"Debit"}}}`)
+	})
+
+	var complexDryRuns []NewComplexOrder
+	mux.HandleFunc("/accounts/5YZ55555/complex-orders/dry-run", func(writer http.ResponseWriter, request *http.Request) {
+		var order NewComplexOrder
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&order))
+		complexDryRuns = append(complexDryRuns, order)
+
+		fmt.Fprint(writer, `{"data":{"complex-order":{"type":"OTOCO"}}}`)
+	})
+
+	dir := t.TempDir()
+
+	writeSpec(t, dir, "a-put.yaml", `
+name: short put
+time-in-force: Day
+order-type: Limit
+price: 0.85
+price-effect: Credit
+legs:
+  - option: AAPL 4DTE 65 put
+    quantity: 1
+    action: Sell to Open
+`)
+	writeSpec(t, dir, "b-call.json", `{
+		"time-in-force": "Day",
+		"order-type": "Limit",
+		"price": 1.2,
+		"price-effect": "Debit",
+		"legs": [{"option": "AAPL 4DTE 65 call", "quantity": 1, "action": "Buy to Open"}]
+	}`)
+	writeSpec(t, dir, "c-bracket.yml", `
+name: bracket
+type: OTOCO
+trigger-order:
+  time-in-force: Day
+  order-type: Limit
+  price: 180
+  price-effect: Debit
+  legs: [{instrument-type: Equity, symbol: AAPL, quantity: 100, action: Buy}]
+orders:
+  - time-in-force: GTC
+    order-type: Limit
+    price: 190
+    price-effect: Credit
+    legs: [{instrument-type: Equity, symbol: AAPL, quantity: 100, action: Sell}]
+  - time-in-force: GTC
+    order-type: Stop
+    stop-trigger: 175
+    legs: [{instrument-type: Equity, symbol: AAPL, quantity: 100, action: Sell}]
+`)
+	writeSpec(t, dir, "d-typo.yaml", "order-typ: Limit\n")
+	writeSpec(t, dir, "notes.txt", "not a spec")
+
+	report, err := client.LoadOrderSpecs(dir, SpecConfig{AccountNumber: "5YZ55555"})
+	require.NoError(t, err)
+	require.Len(t, report.Results, 4)
+	require.False(t, report.OK())
+
+	put := report.Results[0]
+	require.True(t, put.OK())
+	require.Equal(t, "AAPL  230616P00065000", put.Resolved.Order.Legs[0].Symbol)
+	require.Equal(t, filepath.Join(dir, "a-put.yaml"), put.Path)
+
+	call := report.Results[1]
+	require.False(t, call.OK())
+	require.Equal(t, "Insufficient buying power", call.OrderError.Message)
+
+	bracket := report.Results[2]
+	require.True(t, bracket.OK())
+	require.Equal(t, OTOCO, bracket.Resolved.ComplexOrder.Type)
+	require.Len(t, complexDryRuns, 1)
+	require.Len(t, complexDryRuns[0].Orders, 2)
+
+	require.ErrorContains(t, report.Results[3].Err, `unknown field "order-typ"`)
+	require.Len(t, dryRuns, 2)
+
+	require.Equal(t, fmt.Sprintf("%s: Insufficient buying power\n%s: json: unknown field \"order-typ\"",
+		call.Path, report.Results[3].Path), report.Err().Error())
+
+	require.Equal(t, fmt.Sprintf(`short put (%s)
+  STO 1 AAPL Jun16'23 65 Put @ 0.85 Credit Day
+  warning: Order is outside market hours
+  buying power change: 430.00 Debit
+  OK
+%s
+  BTO 1 AAPL Jun16'23 65 Call @ 1.20 Debit Day
+  rejected: Insufficient buying power
+bracket (%s)
+  OTOCO trigger: Buy 100 AAPL @ 180.00 Debit Day
+  OTOCO order: Sell 100 AAPL @ 190.00 Credit GTC
+  OTOCO order: Sell 100 AAPL Stop 175.00 GTC
+  OK
+%s
+  error: json: unknown field "order-typ"`, put.Path, call.Path, bracket.Path, report.Results[3].Path), report.String())
+
+	// specs can be checked without dry runs
+	report, err = client.LoadOrderSpecs(dir, SpecConfig{AccountNumber: "5YZ55555", SkipDryRun: true})
+	require.NoError(t, err)
+	require.True(t, report.Results[1].OK())
+	require.Len(t, dryRuns, 2)
+
+	_, err = client.LoadOrderSpecs(filepath.Join(dir, "missing"), SpecConfig{})
+	require.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package tasty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// Polling backoff used by WaitForOrder, with or without a connected account streamer.
+	orderPollInitial = 500 * time.Millisecond
+	orderPollMax     = 10 * time.Second
+	// Polling interval used by the OrderTracker as a safety net while the
+	// account streamer is connected.
+	orderPollStreaming = 30 * time.Second
+
+	// ErrOrderFinished is returned by WaitForOrder when the order reaches a
+	// terminal status other than the ones being waited for.
+	ErrOrderFinished = errors.New("order reached a terminal status")
+
+	terminalOrderStatuses = []OrderStatus{Filled, Cancelled, Expired, Rejected, Removed, PartiallyRemoved}
+)
+
+// OrderTransition is a change in an order's status observed while waiting
+// or tracking.
+type OrderTransition struct {
+	From  OrderStatus
+	To    OrderStatus
+	Order Order
+	// Fills added since the previous snapshot, only set by the OrderTracker
+	Fills []OrderFill
+	// When the transition was observed
+	At time.Time
+}
+
+// WaitForOrder blocks until the order reaches one of the statuses, defaulting
+// to Filled, Cancelled, Expired, Rejected and Removed. Order notifications from
+// the client's account streamer are used when connected and the order is
+// polled with backoff either way, so a missed notification only delays the
+// wait by the polling interval. The final order is always fetched with GetOrder so
+// it includes the legs and fills.
+func (c *Client) WaitForOrder(ctx context.Context, accountNumber string, id int, statuses ...OrderStatus) (Order, error) {
+	return c.WatchOrder(ctx, accountNumber, id, nil, statuses...)
+}
+
+// WatchOrder is WaitForOrder that also reports each status transition to
+// onTransition i.e. Routed -> In Flight -> Live.
+func (c *Client) WatchOrder(ctx context.Context, accountNumber string, id int,
+	onTransition func(OrderTransition), statuses ...OrderStatus) (Order, error) {
+	if len(statuses) == 0 {
+		statuses = terminalOrderStatuses
+	}
+
+	var events <-chan AccountEvent
+	if streamer := c.accountStreamer.Load(); streamer != nil {
+		var stop func()
+		events, stop = streamer.Listen(defaultEventBuffer)
+		defer stop()
+	}
+
+	var current Order
+	interval := orderPollInitial
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var update Order
+		poll := false
+
+		select {
+		case <-ctx.Done():
+			return current, ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				// streamer stopped, fall back to polling
+				events = nil
+				continue
+			}
+			order, err := event.Order()
+			if err != nil || order.ID != id {
+				continue
+			}
+			update = order
+		case <-timer.C:
+			order, err := c.getOrderContext(ctx, accountNumber, id)
+			if err != nil {
+				return current, err
+			}
+			update = order
+			poll = true
+		}
+
+		if update.Status != current.Status && current.Status != "" && onTransition != nil {
+			onTransition(OrderTransition{From: current.Status, To: update.Status, Order: update, At: time.Now()})
+		}
+		current = update
+
+		if containsOrderStatus(statuses, current.Status) || containsOrderStatus(terminalOrderStatuses, current.Status) {
+			return c.finishWaitForOrder(ctx, accountNumber, current, poll, statuses)
+		}
+
+		if poll {
+			timer.Reset(interval)
+			interval *= 2
+			if interval > orderPollMax {
+				interval = orderPollMax
+			}
+		}
+	}
+}
+
+func (c *Client) finishWaitForOrder(ctx context.Context, accountNumber string, order Order, polled bool, statuses []OrderStatus) (Order, error) {
+	if !polled {
+		final, err := c.getOrderContext(ctx, accountNumber, order.ID)
+		if err != nil {
+			return order, err
+		}
+		// the streamer may be ahead of the order endpoint
+		if final.Status == order.Status {
+			order = final
+		}
+	}
+
+	if !containsOrderStatus(statuses, order.Status) {
+		return order, fmt.Errorf("%w: order %d is %s", ErrOrderFinished, order.ID, order.Status)
+	}
+
+	return order, nil
+}
+
+// getOrderContext is GetOrder returning as soon as the context is done, the
+// request itself finishing in the background.
+func (c *Client) getOrderContext(ctx context.Context, accountNumber string, id int) (Order, error) {
+	type result struct {
+		order Order
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		order, _, err := c.GetOrder(accountNumber, id)
+		done <- result{order, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.order, r.err
+	case <-ctx.Done():
+		return Order{}, ctx.Err()
+	}
+}
+
+// containsOrderStatus returns whether or not the status exists in the slice.
+func containsOrderStatus(s []OrderStatus, e OrderStatus) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
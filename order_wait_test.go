@@ -0,0 +1,225 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fastOrderPolling(t *testing.T) {
+	t.Helper()
+
+	initial, maxInterval, streaming := orderPollInitial, orderPollMax, orderPollStreaming
+	orderPollInitial, orderPollMax, orderPollStreaming = time.Millisecond, 2*time.Millisecond, time.Hour
+
+	t.Cleanup(func() {
+		orderPollInitial, orderPollMax, orderPollStreaming = initial, maxInterval, streaming
+	})
+}
+
+func orderStatusResp(id int, status OrderStatus) string {
+	return fmt.Sprintf(`{"data":{"id":%d,"account-number":"5YZ55555","status":%q,"legs":[{"symbol":"AAPL","fills":[{"fill-id":"1","fill-price":"150.0"}]}]}}`,
+		id, status)
+}
+
+func TestWaitForOrderPolling(t *testing.T) {
+	setup()
+	defer teardown()
+	fastOrderPolling(t)
+
+	statuses := []OrderStatus{Received, Routed, InFlight, Live, Live, Filled}
+	var mu sync.Mutex
+	calls := 0
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/1", func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(writer, orderStatusResp(1, statuses[calls]))
+		if calls < len(statuses)-1 {
+			calls++
+		}
+	})
+
+	var transitions []OrderStatus
+	order, err := client.WatchOrder(context.Background(), "5YZ55555", 1, func(tr OrderTransition) {
+		require.NotEqual(t, tr.From, tr.To)
+		transitions = append(transitions, tr.To)
+	})
+	require.NoError(t, err)
+	require.Equal(t, Filled, order.Status)
+	require.Len(t, order.Legs[0].Fills, 1)
+	require.Equal(t, []OrderStatus{Routed, InFlight, Live, Filled}, transitions)
+}
+
+func TestWaitForOrderUnexpectedStatus(t *testing.T) {
+	setup()
+	defer teardown()
+	fastOrderPolling(t)
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/1", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, orderStatusResp(1, Rejected))
+	})
+
+	order, err := client.WaitForOrder(context.Background(), "5YZ55555", 1, Filled)
+	require.ErrorIs(t, err, ErrOrderFinished)
+	require.EqualError(t, err, "order reached a terminal status: order 1 is Rejected")
+	require.Equal(t, Rejected, order.Status)
+}
+
+func TestWaitForOrderContext(t *testing.T) {
+	setup()
+	defer teardown()
+	fastOrderPolling(t)
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/1", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, orderStatusResp(1, Live))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	order, err := client.WaitForOrder(ctx, "5YZ55555", 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, Live, order.Status)
+}
+
+func TestWaitForOrderError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/1", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.WaitForOrder(context.Background(), "5YZ55555", 1)
+	expectedUnauthorized(t, err)
+}
+
+func TestWaitForOrderStreaming(t *testing.T) {
+	setup()
+	defer teardown()
+	fastOrderPolling(t)
+
+	var mu sync.Mutex
+	status := Live
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/1", func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(writer, orderStatusResp(1, status))
+	})
+
+	streamer, conn := connectedAccountStreamer(t)
+	defer streamer.Close()
+	client.SetAccountStreamer(streamer)
+	defer client.SetAccountStreamer(nil)
+
+	transitions := make(chan OrderStatus, 10)
+	result := make(chan Order)
+
+	go func() {
+		order, err := client.WatchOrder(context.Background(), "5YZ55555", 1, func(tr OrderTransition) {
+			transitions <- tr.To
+		}, Filled)
+		require.NoError(t, err)
+		result <- order
+	}()
+
+	// wait for the initial poll before streaming updates
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	status = Filled
+	mu.Unlock()
+
+	conn.in <- []byte(`{"type":"Order","data":{"id":2,"status":"Filled"},"timestamp":1}`)
+	conn.in <- []byte(`{"type":"Order","data":{"id":1,"status":"Filled"},"timestamp":2}`)
+
+	order := <-result
+	require.Equal(t, Filled, order.Status)
+	// the final order is fetched with its fills
+	require.Len(t, order.Legs[0].Fills, 1)
+	require.Equal(t, Filled, <-transitions)
+}
+
+func TestWaitForOrderStreamingMissedNotification(t *testing.T) {
+	setup()
+	defer teardown()
+	fastOrderPolling(t)
+
+	var mu sync.Mutex
+	calls := 0
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/1", func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		status := Live
+		if calls++; calls > 2 {
+			status = Filled
+		}
+		fmt.Fprint(writer, orderStatusResp(1, status))
+	})
+
+	streamer, _ := connectedAccountStreamer(t)
+	defer streamer.Close()
+	client.SetAccountStreamer(streamer)
+	defer client.SetAccountStreamer(nil)
+
+	// no notification is streamed, polling still finds the fill
+	order, err := client.WaitForOrder(context.Background(), "5YZ55555", 1)
+	require.NoError(t, err)
+	require.Equal(t, Filled, order.Status)
+}
+
+func TestWaitForOrderSlowRequest(t *testing.T) {
+	setup()
+	defer teardown()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	mux.HandleFunc("/accounts/5YZ55555/orders/1", func(writer http.ResponseWriter, request *http.Request) {
+		<-release
+		fmt.Fprint(writer, orderStatusResp(1, Live))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.WaitForOrder(ctx, "5YZ55555", 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAccountStreamerListen(t *testing.T) {
+	streamer, conn := connectedAccountStreamer(t)
+
+	events, stop := streamer.Listen(1)
+	conn.in <- []byte(`{"type":"Order","data":{"id":1},"timestamp":1}`)
+	conn.in <- []byte(`{"type":"Order","data":{"id":2},"timestamp":2}`)
+
+	<-streamer.Events()
+	<-streamer.Events()
+
+	// the second event was dropped because the listener buffer was full
+	require.Equal(t, EpochMillis(1), (<-events).Timestamp)
+
+	stop()
+	stop()
+	_, open := <-events
+	require.False(t, open)
+
+	other, _ := streamer.Listen(1)
+	require.NoError(t, streamer.Close())
+	_, open = <-other
+	require.False(t, open)
+
+	closed, _ := streamer.Listen(1)
+	_, open = <-closed
+	require.False(t, open)
+}
@@ -50,13 +50,13 @@ type Order struct {
 }
 
 type ComplexOrder struct {
-	ID                                   int             `json:"id"`
-	AccountNumber                        string          `json:"account-number"`
-	Type                                 string          `json:"type"`
-	TerminalAt                           string          `json:"terminal-at"`
-	RatioPriceThreshold                  decimal.Decimal `json:"ratio-price-threshold"`
-	RatioPriceComparator                 string          `json:"ratio-price-comparator"`
-	RatioPriceIsThresholdBasedOnNotional bool            `json:"ratio-price-is-threshold-based-on-notional"`
+	ID                                   int              `json:"id"`
+	AccountNumber                        string           `json:"account-number"`
+	Type                                 ComplexOrderType `json:"type"`
+	TerminalAt                           string           `json:"terminal-at"`
+	RatioPriceThreshold                  decimal.Decimal  `json:"ratio-price-threshold"`
+	RatioPriceComparator                 string           `json:"ratio-price-comparator"`
+	RatioPriceIsThresholdBasedOnNotional bool             `json:"ratio-price-is-threshold-based-on-notional"`
 	// RelatedOrders Non-current orders. This includes replaced orders, unfilled orders, and terminal orders.
 	RelatedOrders []RelatedOrder `json:"related-orders"`
 	// Orders with complex-order-tag: '::order'. For example, 'OTO::order' for OTO complex orders.
@@ -81,29 +81,29 @@ type RelatedOrder struct {
 }
 
 type OrderLeg struct {
-	InstrumentType    InstrumentType `json:"instrument-type"`
-	Symbol            string         `json:"symbol"`
-	Quantity          float32        `json:"quantity"`
-	RemainingQuantity float32        `json:"remaining-quantity"`
-	Action            OrderAction    `json:"action"`
-	Fills             []OrderFill    `json:"fills"`
+	InstrumentType    InstrumentType  `json:"instrument-type"`
+	Symbol            string          `json:"symbol"`
+	Quantity          decimal.Decimal `json:"quantity"`
+	RemainingQuantity decimal.Decimal `json:"remaining-quantity"`
+	Action            OrderAction     `json:"action"`
+	Fills             []OrderFill     `json:"fills"`
 }
 
 type OrderFill struct {
 	ExtGroupFillID   string          `json:"ext-group-fill-id"`
 	ExtExecID        string          `json:"ext-exec-id"`
 	FillID           string          `json:"fill-id"`
-	Quantity         float32         `json:"quantity"`
+	Quantity         decimal.Decimal `json:"quantity"`
 	FillPrice        decimal.Decimal `json:"fill-price"`
 	FilledAt         time.Time       `json:"filled-at"`
 	DestinationVenue string          `json:"destination-venue"`
 }
 
 type OrderRules struct {
-	RouteAfter  string           `json:"route-after"`
-	RoutedAt    string           `json:"routed-at"`
-	CancelAt    string           `json:"cancel-at"`
-	CancelledAt string           `json:"cancelled-at"`
+	RouteAfter  time.Time        `json:"route-after"`
+	RoutedAt    time.Time        `json:"routed-at"`
+	CancelAt    time.Time        `json:"cancel-at"`
+	CancelledAt time.Time        `json:"cancelled-at"`
 	Conditions  []OrderCondition `json:"conditions"`
 }
 
@@ -116,16 +116,16 @@ type OrderCondition struct {
 	Comparator                 Comparator            `json:"comparator"`
 	Threshold                  decimal.Decimal       `json:"threshold"`
 	IsThresholdBasedOnNotional bool                  `json:"is-threshold-based-on-notional"`
-	TriggeredAt                string                `json:"triggered-at"`
+	TriggeredAt                time.Time             `json:"triggered-at"`
 	TriggeredValue             decimal.Decimal       `json:"triggered-value"`
 	PriceComponents            []OrderPriceComponent `json:"price-components"`
 }
 
 type OrderPriceComponent struct {
-	Symbol            string         `json:"symbol"`
-	InstrumentType    InstrumentType `json:"instrument-type"`
-	Quantity          float32        `json:"quantity"`
-	QuantityDirection Direction      `json:"quantity-direction"`
+	Symbol            string          `json:"symbol"`
+	InstrumentType    InstrumentType  `json:"instrument-type"`
+	Quantity          decimal.Decimal `json:"quantity"`
+	QuantityDirection Direction       `json:"quantity-direction"`
 }
 
 type NewOrderECR struct {
@@ -133,12 +133,12 @@ type NewOrderECR struct {
 	TimeInForce TimeInForce `json:"time-in-force"`
 	GtcDate     string      `json:"gtc-date,omitempty"`
 	// (Required) The type of order in regards to the price.
-	OrderType   OrderType `json:"order-type"`
-	StopTrigger float32   `json:"stop-trigger,omitempty"`
-	Price       float32   `json:"price,omitempty"`
+	OrderType   OrderType       `json:"order-type"`
+	StopTrigger decimal.Decimal `json:"stop-trigger,omitempty"`
+	Price       decimal.Decimal `json:"price,omitempty"`
 	// (Required) If pay or receive payment for placing the order.
-	PriceEffect PriceEffect `json:"price-effect"`
-	Value       float32     `json:"value,omitempty"`
+	PriceEffect PriceEffect     `json:"price-effect"`
+	Value       decimal.Decimal `json:"value,omitempty"`
 	// If pay or receive payment for placing the notional market order.
 	// i.e. Credit or Debit
 	ValueEffect  PriceEffect   `json:"value-effect"`
@@ -149,10 +149,10 @@ type NewOrderECR struct {
 }
 
 type NewOrderLeg struct {
-	InstrumentType InstrumentType `json:"instrument-type"`
-	Symbol         string         `json:"symbol"`
-	Quantity       float32        `json:"quantity,omitempty"`
-	Action         OrderAction    `json:"action"`
+	InstrumentType InstrumentType  `json:"instrument-type"`
+	Symbol         string          `json:"symbol"`
+	Quantity       decimal.Decimal `json:"quantity,omitempty"`
+	Action         OrderAction     `json:"action"`
 }
 
 type FeeCalculation struct {
@@ -220,26 +220,39 @@ type OrderReplacement struct {
 }
 
 type NewOrder struct {
-	TimeInForce  TimeInForce   `json:"time-in-force"`
-	GtcDate      string        `json:"gtc-date"`
-	OrderType    OrderType     `json:"order-type"`
-	StopTrigger  float32       `json:"stop-trigger,omitempty"`
-	Price        float32       `json:"price,omitempty"`
-	PriceEffect  PriceEffect   `json:"price-effect,omitempty"`
-	Value        float32       `json:"value,omitempty"`
-	ValueEffect  PriceEffect   `json:"value-effect,omitempty"`
-	Source       string        `json:"source,omitempty"`
-	PartitionKey string        `json:"partition-key,omitempty"`
-	PreflightID  string        `json:"preflight-id,omitempty"`
-	Legs         []NewOrderLeg `json:"legs"`
-	Rules        NewOrderRules `json:"rules,omitempty"`
+	TimeInForce  TimeInForce     `json:"time-in-force"`
+	GtcDate      string          `json:"gtc-date"`
+	OrderType    OrderType       `json:"order-type"`
+	StopTrigger  decimal.Decimal `json:"stop-trigger,omitempty"`
+	Price        decimal.Decimal `json:"price,omitempty"`
+	PriceEffect  PriceEffect     `json:"price-effect,omitempty"`
+	Value        decimal.Decimal `json:"value,omitempty"`
+	ValueEffect  PriceEffect     `json:"value-effect,omitempty"`
+	Source       string          `json:"source,omitempty"`
+	PartitionKey string          `json:"partition-key,omitempty"`
+	PreflightID  string          `json:"preflight-id,omitempty"`
+	// Client generated ID to find the order by when its submission fails
+	ExtClientOrderID string        `json:"ext-client-order-id,omitempty"`
+	Legs             []NewOrderLeg `json:"legs"`
+	Rules            NewOrderRules `json:"rules,omitempty"`
+}
+
+// NewComplexOrder submits linked orders together i.e. an entry with a
+// profit target and stop loss bracket as an OTOCO.
+type NewComplexOrder struct {
+	// (Required) OTO, OCO or OTOCO
+	Type ComplexOrderType `json:"type"`
+	// The order that routes the other orders once filled. Required for OTO and OTOCO.
+	TriggerOrder *NewOrder `json:"trigger-order,omitempty"`
+	// (Required) One order for OTO, two orders for OCO and OTOCO.
+	Orders []NewOrder `json:"orders"`
 }
 
 type NewOrderRules struct {
 	// RouteAfter Earliest time an order should route at
-	RouteAfter string `json:"route-after"`
+	RouteAfter time.Time `json:"route-after"`
 	// CancelAt Latest time an order should be canceled at
-	CancelAt   string              `json:"cancel-at"`
+	CancelAt   time.Time           `json:"cancel-at"`
 	Conditions []NewOrderCondition `json:"conditions"`
 }
 
@@ -249,7 +262,7 @@ type NewOrderPriceComponent struct {
 	// The instrument's type in relation to the symbol.
 	InstrumentType InstrumentType `json:"instrument-type"`
 	// The Ratio quantity in relation to the symbol
-	Quantity float32 `json:"quantity"`
+	Quantity decimal.Decimal `json:"quantity"`
 	// The quantity direction(ie Long or Short) in relation to the symbol
 	QuantityDirection Direction `json:"quantity-direction"`
 }
@@ -260,13 +273,13 @@ type NewOrderCondition struct {
 	// The symbol to apply the condition to.
 	Symbol string `json:"symbol"`
 	// The instrument's type in relation to the condition.
-	InstrumentType string `json:"instrument-type"`
+	InstrumentType InstrumentType `json:"instrument-type"`
 	// The indicator for the trigger, currently only supports last
 	Indicator Indicator `json:"indicator"`
 	// How to compare against the threshold.
 	Comparator Comparator `json:"comparator"`
 	// The price at which the condition triggers.
-	Threshold       float32                  `json:"threshold"`
+	Threshold       decimal.Decimal          `json:"threshold"`
 	PriceComponents []NewOrderPriceComponent `json:"price-components"`
 }
 
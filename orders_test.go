@@ -10,13 +10,26 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// buyAAPL returns a Day Limit order buying the shares of AAPL at the price.
+func buyAAPL(quantity int64, price string) NewOrder {
+	return NewOrder{
+		TimeInForce: Day,
+		OrderType:   Limit,
+		Price:       decimal.RequireFromString(price),
+		PriceEffect: Debit,
+		Legs: []NewOrderLeg{
+			{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: decimal.NewFromInt(quantity), Action: Buy},
+		},
+	}
+}
+
 func TestSubmitMarketOrderDryRun(t *testing.T) {
 	setup()
 	defer teardown()
 
 	accountNumber := "5YZ55555"
 	symbol := "AAPL"
-	quantity := float32(1)
+	quantity := decimal.NewFromInt(1)
 	action := BTO
 
 	mux.HandleFunc(fmt.Sprintf("/accounts/%s/orders/dry-run", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
@@ -103,7 +116,7 @@ func TestSubmitMarketOrderDryRunError(t *testing.T) {
 
 	accountNumber := "5YZ55555"
 	symbol := "AAPL"
-	quantity := float32(1)
+	quantity := decimal.NewFromInt(1)
 	action := BTO
 
 	mux.HandleFunc(fmt.Sprintf("/accounts/%s/orders/dry-run", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
@@ -154,9 +167,9 @@ func TestSubmitGTCOrderDryRun(t *testing.T) {
 
 	accountNumber := "5YZ55555"
 	symbol := "GOOGL"
-	quantity := float32(1)
+	quantity := decimal.NewFromInt(1)
 	action := STC
-	price := float32(124.55)
+	price := decimal.RequireFromString("124.55")
 
 	mux.HandleFunc(fmt.Sprintf("/accounts/%s/orders/dry-run", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
 		fmt.Fprint(writer, orderDryRunGTCResp)
@@ -190,7 +203,7 @@ func TestSubmitGTCOrderDryRun(t *testing.T) {
 	require.Equal(t, 1, o.Size)
 	require.Equal(t, symbol, o.UnderlyingSymbol)
 	require.Equal(t, EquityIT, o.UnderlyingInstrumentType)
-	require.Equal(t, decimal.NewFromFloat32(price), o.Price)
+	require.Equal(t, price, o.Price)
 	require.Equal(t, Credit, o.PriceEffect)
 	require.Equal(t, Contingent, o.Status)
 	require.Equal(t, "Pending Condition", o.ContingentStatus)
@@ -233,9 +246,9 @@ func TestSubmitGTCOrderDryRunError(t *testing.T) {
 
 	accountNumber := "5YZ55555"
 	symbol := "GOOGL"
-	quantity := float32(1)
+	quantity := decimal.NewFromInt(1)
 	action := STC
-	price := float32(124.55)
+	price := decimal.RequireFromString("124.55")
 
 	mux.HandleFunc(fmt.Sprintf("/accounts/%s/orders/dry-run", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
 		writer.WriteHeader(401)
@@ -268,7 +281,7 @@ func TestSubmitErrorOrderDryRun(t *testing.T) {
 
 	accountNumber := "5YZ55555"
 	symbol := "AAPL"
-	quantity := float32(10)
+	quantity := decimal.NewFromInt(10)
 	action := BTO
 
 	mux.HandleFunc(fmt.Sprintf("/accounts/%s/orders/dry-run", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
@@ -321,7 +334,7 @@ func TestSubmitErrorOrderDryRunError(t *testing.T) {
 
 	accountNumber := "5YZ55555"
 	symbol := "AAPL"
-	quantity := float32(10)
+	quantity := decimal.NewFromInt(10)
 	action := BTO
 
 	mux.HandleFunc(fmt.Sprintf("/accounts/%s/orders/dry-run", accountNumber), func(writer http.ResponseWriter, request *http.Request) {
@@ -358,13 +371,13 @@ func TestSubmitOrder(t *testing.T) {
 	})
 
 	symbol := "RIVN"
-	quantity := float32(1)
+	quantity := decimal.NewFromInt(1)
 	action1 := BTC
 
 	symbol1 := EquityOptionsSymbology{
 		Symbol:     symbol,
 		OptionType: Call,
-		Strike:     15,
+		Strike:     decimal.NewFromInt(15),
 		Expiration: time.Date(2023, 6, 23, 0, 0, 0, 0, time.Local),
 	}
 
@@ -372,7 +385,7 @@ func TestSubmitOrder(t *testing.T) {
 		TimeInForce: GTC,
 		OrderType:   Limit,
 		PriceEffect: Debit,
-		Price:       0.04,
+		Price:       decimal.RequireFromString("0.04"),
 		Legs: []NewOrderLeg{
 			{
 				InstrumentType: EquityOptionIT,
@@ -388,7 +401,7 @@ func TestSubmitOrder(t *testing.T) {
 				InstrumentType: "Equity",
 				Indicator:      Last,
 				Comparator:     LTE,
-				Threshold:      0.01,
+				Threshold:      decimal.RequireFromString("0.01"),
 			},
 		}},
 	}
@@ -490,13 +503,13 @@ func TestSubmitOrderError(t *testing.T) {
 	})
 
 	symbol := "RIVN"
-	quantity := float32(1)
+	quantity := decimal.NewFromInt(1)
 	action1 := BTC
 
 	symbol1 := EquityOptionsSymbology{
 		Symbol:     symbol,
 		OptionType: Call,
-		Strike:     15,
+		Strike:     decimal.NewFromInt(15),
 		Expiration: time.Date(2023, 6, 23, 0, 0, 0, 0, time.Local),
 	}
 
@@ -504,7 +517,7 @@ func TestSubmitOrderError(t *testing.T) {
 		TimeInForce: GTC,
 		OrderType:   Limit,
 		PriceEffect: Debit,
-		Price:       0.04,
+		Price:       decimal.RequireFromString("0.04"),
 		Legs: []NewOrderLeg{
 			{
 				InstrumentType: EquityOptionIT,
@@ -520,7 +533,7 @@ func TestSubmitOrderError(t *testing.T) {
 				InstrumentType: "Equity",
 				Indicator:      Last,
 				Comparator:     LTE,
-				Threshold:      0.01,
+				Threshold:      decimal.RequireFromString("0.01"),
 			},
 		}},
 	}
@@ -566,8 +579,8 @@ func TestGetAccountLiveOrders(t *testing.T) {
 
 	require.Equal(t, EquityOptionIT, ol.InstrumentType)
 	require.Equal(t, "RIVN  230623C00015000", ol.Symbol)
-	require.Equal(t, float32(1), ol.Quantity)
-	require.Equal(t, float32(1), ol.RemainingQuantity)
+	require.Equal(t, "1", ol.Quantity.String())
+	require.Equal(t, "1", ol.RemainingQuantity.String())
 	require.Equal(t, BTC, ol.Action)
 	require.Empty(t, ol.Fills)
 
@@ -586,7 +599,7 @@ func TestGetAccountLiveOrders(t *testing.T) {
 
 	require.Equal(t, "RIVN", pc.Symbol)
 	require.Equal(t, EquityIT, pc.InstrumentType)
-	require.Equal(t, float32(1), pc.Quantity)
+	require.Equal(t, "1", pc.Quantity.String())
 	require.Equal(t, Long, pc.QuantityDirection)
 }
 
@@ -654,7 +667,7 @@ func TestSubmitOrderECRDryRun(t *testing.T) {
 
 	orderECR := NewOrderECR{
 		TimeInForce: Day,
-		Price:       185.45,
+		Price:       decimal.RequireFromString("185.45"),
 		OrderType:   Limit,
 		PriceEffect: Debit,
 	}
@@ -695,7 +708,7 @@ func TestSubmitOrderECRDryRunError(t *testing.T) {
 
 	orderECR := NewOrderECR{
 		TimeInForce: Day,
-		Price:       185.45,
+		Price:       decimal.RequireFromString("185.45"),
 		OrderType:   Limit,
 		PriceEffect: Debit,
 	}
@@ -816,7 +829,7 @@ func TestReplaceOrder(t *testing.T) {
 
 	orderECR := NewOrderECR{
 		TimeInForce: Day,
-		Price:       185.45,
+		Price:       decimal.RequireFromString("185.45"),
 		OrderType:   Limit,
 		PriceEffect: Debit,
 		ValueEffect: Debit,
@@ -856,7 +869,7 @@ func TestReplaceOrderError(t *testing.T) {
 
 	orderECR := NewOrderECR{
 		TimeInForce: Day,
-		Price:       185.45,
+		Price:       decimal.RequireFromString("185.45"),
 		OrderType:   Limit,
 		PriceEffect: Debit,
 		ValueEffect: Debit,
@@ -880,7 +893,7 @@ func TestPatchOrder(t *testing.T) {
 
 	orderECR := NewOrderECR{
 		TimeInForce: Day,
-		Price:       187.45,
+		Price:       decimal.RequireFromString("187.45"),
 		OrderType:   Limit,
 		PriceEffect: Debit,
 		ValueEffect: Debit,
@@ -920,7 +933,7 @@ func TestPatchOrderError(t *testing.T) {
 
 	orderECR := NewOrderECR{
 		TimeInForce: Day,
-		Price:       187.45,
+		Price:       decimal.RequireFromString("187.45"),
 		OrderType:   Limit,
 		PriceEffect: Debit,
 		ValueEffect: Debit,
@@ -965,8 +978,8 @@ func TestGetCustomerLiveOrders(t *testing.T) {
 
 	require.Equal(t, EquityOptionIT, ol.InstrumentType)
 	require.Equal(t, "RIVN  230623C00015000", ol.Symbol)
-	require.Equal(t, float32(1), ol.Quantity)
-	require.Equal(t, float32(0), ol.RemainingQuantity)
+	require.Equal(t, "1", ol.Quantity.String())
+	require.Equal(t, "0", ol.RemainingQuantity.String())
 	require.Equal(t, BTC, ol.Action)
 
 	fi := ol.Fills[0]
@@ -974,7 +987,7 @@ func TestGetCustomerLiveOrders(t *testing.T) {
 	require.Equal(t, "2263911504", fi.ExtGroupFillID)
 	require.Equal(t, "90305", fi.ExtExecID)
 	require.Equal(t, "3_OPT850090305", fi.FillID)
-	require.Equal(t, float32(1), fi.Quantity)
+	require.Equal(t, "1", fi.Quantity.String())
 	require.Equal(t, decimal.NewFromFloat(0.01), fi.FillPrice)
 	require.Equal(t, "2023-06-23T14:12:04.214Z", fi.FilledAt.Format(time.RFC3339Nano))
 	require.Equal(t, "CITADEL_OPTIONS_A", fi.DestinationVenue)
@@ -1036,8 +1049,8 @@ func TestGetCustomerOrders(t *testing.T) {
 
 	require.Equal(t, EquityOptionIT, ol.InstrumentType)
 	require.Equal(t, "RIVN  230623C00015000", ol.Symbol)
-	require.Equal(t, float32(1), ol.Quantity)
-	require.Equal(t, float32(0), ol.RemainingQuantity)
+	require.Equal(t, "1", ol.Quantity.String())
+	require.Equal(t, "0", ol.RemainingQuantity.String())
 	require.Equal(t, BTC, ol.Action)
 
 	fi := ol.Fills[0]
@@ -1045,7 +1058,7 @@ func TestGetCustomerOrders(t *testing.T) {
 	require.Equal(t, "2263911504", fi.ExtGroupFillID)
 	require.Equal(t, "90305", fi.ExtExecID)
 	require.Equal(t, "3_OPT850090305", fi.FillID)
-	require.Equal(t, float32(1), fi.Quantity)
+	require.Equal(t, "1", fi.Quantity.String())
 	require.Equal(t, decimal.NewFromFloat(0.01), fi.FillPrice)
 	require.Equal(t, "2023-06-23T14:12:04.214Z", fi.FilledAt.Format(time.RFC3339Nano))
 	require.Equal(t, "CITADEL_OPTIONS_A", fi.DestinationVenue)
@@ -0,0 +1,321 @@
+package tasty
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PositionPnL is the live profit and loss of a single position.
+type PositionPnL struct {
+	Position AccountPosition
+	// Mid of the latest quote, or the position's mark until a quote arrives
+	Mark decimal.Decimal
+	// Profit and loss against AverageOpenPrice
+	Unrealized decimal.Decimal
+	// Profit and loss against ClosePrice
+	Day       decimal.Decimal
+	UpdatedAt time.Time
+}
+
+// PnLSummary is the aggregated profit and loss of a group of positions.
+type PnLSummary struct {
+	Positions  int
+	Unrealized decimal.Decimal
+	Day        decimal.Decimal
+}
+
+// PnLTracker keeps live profit and loss for the positions of an account
+// using streaming quotes. It is safe for concurrent use.
+type PnLTracker struct {
+	client        *Client
+	feed          MarketDataFeed
+	accountNumber string
+	mu            sync.RWMutex
+	positions     map[string]*PositionPnL
+	streamer      map[string]string
+}
+
+// Sign of the position's quantity, 1 for long and -1 for short. Falls back to
+// the CostEffect when the direction is missing, a credit meaning short.
+func (ap AccountPosition) Sign() decimal.Decimal {
+	switch {
+	case ap.QuantityDirection == Short:
+		return decimal.NewFromInt(-1)
+	case ap.QuantityDirection == Long:
+		return decimal.NewFromInt(1)
+	case ap.CostEffect == Credit:
+		return decimal.NewFromInt(-1)
+	default:
+		return decimal.NewFromInt(1)
+	}
+}
+
+// PnL returns the unrealized profit and loss against the AverageOpenPrice and
+// the day profit and loss against the ClosePrice for the position at the
+// given mark. Positions without a close price, i.e. opened today, use the
+// average open price for the day.
+func (ap AccountPosition) PnL(mark decimal.Decimal) (unrealized, day decimal.Decimal) {
+	size := decimal.NewFromInt(int64(ap.Quantity)).
+		Mul(decimal.NewFromInt(int64(ap.Multiplier))).
+		Mul(ap.Sign())
+
+	closePrice := ap.ClosePrice
+	if closePrice.IsZero() {
+		closePrice = ap.AverageOpenPrice
+	}
+
+	unrealized = mark.Sub(ap.AverageOpenPrice).Mul(size)
+	day = mark.Sub(closePrice).Mul(size)
+
+	return unrealized, day
+}
+
+// NewPnLTracker loads the account's positions and subscribes their quotes on
+// the feed. The feed may be nil when quotes are supplied through Apply.
+func (c *Client) NewPnLTracker(feed MarketDataFeed, accountNumber string) (*PnLTracker, error) {
+	t := &PnLTracker{
+		client:        c,
+		feed:          feed,
+		accountNumber: accountNumber,
+		positions:     map[string]*PositionPnL{},
+		streamer:      map[string]string{},
+	}
+
+	return t, t.Refresh()
+}
+
+// Refresh reloads every position from the API, subscribing new positions
+// and unsubscribing closed ones.
+func (t *PnLTracker) Refresh() error {
+	positions, _, err := t.client.GetAccountPositions(t.accountNumber, AccountPositionQuery{})
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	current := make(map[string]bool, len(positions))
+	var closed []AccountPosition
+	for _, p := range positions {
+		current[p.Symbol] = true
+	}
+	for symbol, p := range t.positions {
+		if !current[symbol] {
+			closed = append(closed, p.Position)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, p := range closed {
+		p.Quantity = 0
+		if err = t.UpdatePosition(p); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range positions {
+		if err = t.UpdatePosition(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdatePosition adds or replaces a position, removing it when its quantity is zero.
+func (t *PnLTracker) UpdatePosition(position AccountPosition) error {
+	streamerSymbol := positionStreamerSymbol(position)
+
+	t.mu.Lock()
+	existing, ok := t.positions[position.Symbol]
+
+	if position.Quantity == 0 {
+		delete(t.positions, position.Symbol)
+		delete(t.streamer, streamerSymbol)
+		t.mu.Unlock()
+
+		if !ok || streamerSymbol == "" || t.feed == nil {
+			return nil
+		}
+		return t.feed.Unsubscribe(FeedSubscription{Type: QuoteEvent, Symbol: streamerSymbol})
+	}
+
+	// Mark is the value of the whole position, MarkPrice the per unit price
+	mark := position.MarkPrice
+	if ok {
+		mark = existing.Mark
+	}
+
+	pnl := &PositionPnL{Position: position, Mark: mark, UpdatedAt: time.Now()}
+	pnl.Unrealized, pnl.Day = position.PnL(mark)
+
+	t.positions[position.Symbol] = pnl
+	if streamerSymbol != "" {
+		t.streamer[streamerSymbol] = position.Symbol
+	}
+	t.mu.Unlock()
+
+	if ok || streamerSymbol == "" || t.feed == nil {
+		return nil
+	}
+
+	return t.feed.Subscribe(FeedSubscription{Type: QuoteEvent, Symbol: streamerSymbol})
+}
+
+// Run applies quotes from the feed until its event channel is closed. When the
+// client has an account streamer, position notifications update the tracker.
+func (t *PnLTracker) Run() error {
+	if t.feed == nil {
+		return errNoFeed
+	}
+
+	var accountEvents <-chan AccountEvent
+	if streamer := t.client.accountStreamer.Load(); streamer != nil {
+		var stop func()
+		accountEvents, stop = streamer.Listen(defaultEventBuffer)
+		defer stop()
+	}
+
+	events := t.feed.Events()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			t.Apply(event)
+		case event, ok := <-accountEvents:
+			if !ok {
+				accountEvents = nil
+				continue
+			}
+			if err := t.ApplyAccountEvent(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Apply updates the mark of the position quoted by the event.
+func (t *PnLTracker) Apply(event MarketEvent) {
+	quote, ok := event.(Quote)
+	if !ok || quote.BidPrice.IsZero() || quote.AskPrice.IsZero() {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pnl, ok := t.positions[t.streamer[quote.EventSymbol]]
+	if !ok {
+		return
+	}
+
+	pnl.Mark = quote.BidPrice.Add(quote.AskPrice).Div(decimal.NewFromInt(2))
+	pnl.Unrealized, pnl.Day = pnl.Position.PnL(pnl.Mark)
+	pnl.UpdatedAt = time.Now()
+}
+
+// ApplyAccountEvent updates the tracker from a position notification of the tracked account.
+func (t *PnLTracker) ApplyAccountEvent(event AccountEvent) error {
+	if event.Type != PositionNotification {
+		return nil
+	}
+
+	position, err := event.Position()
+	if err != nil {
+		return err
+	}
+
+	if position.AccountNumber != t.accountNumber {
+		return nil
+	}
+
+	return t.UpdatePosition(position)
+}
+
+// Positions returns a snapshot of every position's profit and loss ordered by symbol.
+func (t *PnLTracker) Positions() []PositionPnL {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	positions := make([]PositionPnL, 0, len(t.positions))
+	for _, p := range t.positions {
+		positions = append(positions, *p)
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Position.Symbol < positions[j].Position.Symbol
+	})
+
+	return positions
+}
+
+// Underlying returns the profit and loss of every position in the underlying symbol.
+func (t *PnLTracker) Underlying(symbol string) PnLSummary {
+	return t.Underlyings()[symbol]
+}
+
+// Underlyings returns the profit and loss per underlying symbol.
+func (t *PnLTracker) Underlyings() map[string]PnLSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	summaries := map[string]PnLSummary{}
+	for _, p := range t.positions {
+		summaries[p.Position.UnderlyingSymbol] = summaries[p.Position.UnderlyingSymbol].add(p)
+	}
+
+	return summaries
+}
+
+// Account returns the profit and loss of every position in the account.
+func (t *PnLTracker) Account() PnLSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var summary PnLSummary
+	for _, p := range t.positions {
+		summary = summary.add(p)
+	}
+
+	return summary
+}
+
+func (s PnLSummary) add(p *PositionPnL) PnLSummary {
+	s.Positions++
+	s.Unrealized = s.Unrealized.Add(p.Unrealized)
+	s.Day = s.Day.Add(p.Day)
+
+	return s
+}
+
+// positionStreamerSymbol returns the position's streamer symbol, deriving it
+// for equities and equity options when the API doesn't provide one.
+func positionStreamerSymbol(position AccountPosition) string {
+	if position.StreamerSymbol != "" {
+		return position.StreamerSymbol
+	}
+
+	return streamerSymbol(position.InstrumentType, position.Symbol)
+}
+
+// streamerSymbol derives the streamer symbol of equities and equity options,
+// returning empty for other instruments.
+func streamerSymbol(instrumentType InstrumentType, symbol string) string {
+	switch instrumentType {
+	case EquityIT:
+		return symbol
+	case EquityOptionIT:
+		occ, err := NewOCCFromString(symbol)
+		if err != nil {
+			return ""
+		}
+		return occ.StreamerSymbol()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,180 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountPositionPnL(t *testing.T) {
+	long := AccountPosition{
+		Quantity:          2,
+		QuantityDirection: Long,
+		Multiplier:        100,
+		AverageOpenPrice:  decimal.RequireFromString("1.50"),
+		ClosePrice:        decimal.RequireFromString("1.80"),
+	}
+
+	unrealized, day := long.PnL(decimal.RequireFromString("2.00"))
+	require.Equal(t, "100", unrealized.String())
+	require.Equal(t, "40", day.String())
+
+	short := long
+	short.QuantityDirection = Short
+
+	unrealized, day = short.PnL(decimal.RequireFromString("2.00"))
+	require.Equal(t, "-100", unrealized.String())
+	require.Equal(t, "-40", day.String())
+
+	// opened today without a close price
+	short.ClosePrice = decimal.Zero
+	_, day = short.PnL(decimal.RequireFromString("1.00"))
+	require.Equal(t, "100", day.String())
+
+	// direction missing, cost effect decides
+	credit := AccountPosition{Quantity: 1, Multiplier: 1, CostEffect: Credit, AverageOpenPrice: decimal.NewFromInt(10)}
+	require.Equal(t, "-1", credit.Sign().String())
+	unrealized, _ = credit.PnL(decimal.NewFromInt(8))
+	require.Equal(t, "2", unrealized.String())
+}
+
+func TestPnLTracker(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, accountPositionsResp)
+	})
+
+	feed := newFakeFeed()
+
+	tracker, err := client.NewPnLTracker(feed, "5YZ55555")
+	require.NoError(t, err)
+	require.Equal(t, []FeedSubscription{{Type: QuoteEvent, Symbol: ".RIVN230609P14"}}, feed.subscribed)
+
+	tracker.Apply(Quote{EventSymbol: ".RIVN230609P14", BidPrice: decimal.RequireFromString("0.50"), AskPrice: decimal.RequireFromString("0.60")})
+	// ignored
+	tracker.Apply(Quote{EventSymbol: ".RIVN230609P14", BidPrice: decimal.Zero, AskPrice: decimal.RequireFromString("0.60")})
+	tracker.Apply(Quote{EventSymbol: "RIVN", BidPrice: decimal.NewFromInt(1), AskPrice: decimal.NewFromInt(2)})
+	tracker.Apply(Trade{EventSymbol: ".RIVN230609P14"})
+
+	positions := tracker.Positions()
+	require.Len(t, positions, 1)
+	require.Equal(t, "0.55", positions[0].Mark.String())
+	// short 40 puts opened at 0.79 closed at 0.41
+	require.Equal(t, "960", positions[0].Unrealized.String())
+	require.Equal(t, "-560", positions[0].Day.String())
+	require.False(t, positions[0].UpdatedAt.IsZero())
+
+	summary := tracker.Underlying("RIVN")
+	require.Equal(t, 1, summary.Positions)
+	require.Equal(t, "960", summary.Unrealized.String())
+	require.Equal(t, 0, tracker.Underlying("AAPL").Positions)
+
+	// a new equity position through the account streamer
+	err = tracker.ApplyAccountEvent(AccountEvent{Type: PositionNotification, Data: []byte(`{"account-number":"5YZ55555",
+		"symbol":"AAPL","instrument-type":"Equity","underlying-symbol":"AAPL","quantity":10,"quantity-direction":"Long",
+		"average-open-price":"150","close-price":"155","multiplier":1,"mark":"1560","mark-price":"156"}`)})
+	require.NoError(t, err)
+	require.Contains(t, feed.subscribed, FeedSubscription{Type: QuoteEvent, Symbol: "AAPL"})
+
+	account := tracker.Account()
+	require.Equal(t, 2, account.Positions)
+	require.Equal(t, "1020", account.Unrealized.String())
+	require.Equal(t, "-550", account.Day.String())
+
+	// other accounts and events are ignored
+	require.NoError(t, tracker.ApplyAccountEvent(AccountEvent{Type: PositionNotification, Data: []byte(`{"account-number":"5WT00000","symbol":"MSFT","quantity":1}`)}))
+	require.NoError(t, tracker.ApplyAccountEvent(AccountEvent{Type: OrderNotification, Data: []byte(`{}`)}))
+	require.Len(t, tracker.Positions(), 2)
+
+	// closing the position unsubscribes it
+	err = tracker.ApplyAccountEvent(AccountEvent{Type: PositionNotification, Data: []byte(`{"account-number":"5YZ55555",
+		"symbol":"AAPL","instrument-type":"Equity","quantity":0}`)})
+	require.NoError(t, err)
+	require.Equal(t, []FeedSubscription{{Type: QuoteEvent, Symbol: "AAPL"}}, feed.unsubscribed)
+	require.Len(t, tracker.Underlyings(), 1)
+
+	close(feed.events)
+	require.NoError(t, tracker.Run())
+}
+
+func TestPnLTrackerRefresh(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	resp := accountPositionsResp
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(writer, resp)
+	})
+
+	feed := newFakeFeed()
+
+	tracker, err := client.NewPnLTracker(feed, "5YZ55555")
+	require.NoError(t, err)
+
+	mu.Lock()
+	resp = `{"data":{"items":[]}}`
+	mu.Unlock()
+
+	require.NoError(t, tracker.Refresh())
+	require.Empty(t, tracker.Positions())
+	require.Equal(t, []FeedSubscription{{Type: QuoteEvent, Symbol: ".RIVN230609P14"}}, feed.unsubscribed)
+}
+
+func TestPnLTrackerWithoutFeed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, accountPositionsResp)
+	})
+
+	tracker, err := client.NewPnLTracker(nil, "5YZ55555")
+	require.NoError(t, err)
+	require.Len(t, tracker.Positions(), 1)
+	require.ErrorIs(t, tracker.Run(), errNoFeed)
+}
+
+func TestPnLTrackerPositionMarks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"items":[{"account-number":"5YZ55555","symbol":"RIVN  230609P00014000",
+			"instrument-type":"Equity Option","underlying-symbol":"RIVN","quantity":40,"quantity-direction":"Short",
+			"close-price":"0.41","average-open-price":"0.79","multiplier":100,"cost-effect":"Debit",
+			"mark":"1800.0","mark-price":"0.45"}]}}`)
+	})
+
+	tracker, err := client.NewPnLTracker(nil, "5YZ55555")
+	require.NoError(t, err)
+
+	positions := tracker.Positions()
+	require.Len(t, positions, 1)
+	require.Equal(t, "0.45", positions[0].Mark.String())
+	// short 40 puts opened at 0.79 marked at 0.45
+	require.Equal(t, "1360", positions[0].Unrealized.String())
+	require.Equal(t, "-160", positions[0].Day.String())
+}
+
+func TestPnLTrackerError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.NewPnLTracker(nil, "5YZ55555")
+	expectedUnauthorized(t, err)
+}
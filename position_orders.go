@@ -0,0 +1,284 @@
+package tasty
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ClosingAction returns the action closing the position, Buy to Close or
+// Sell to Close for options and Buy or Sell for equities, futures and
+// cryptocurrencies.
+func (ap AccountPosition) ClosingAction() OrderAction {
+	short := ap.Sign().IsNegative()
+
+	switch {
+	case isOption(ap.InstrumentType) && short:
+		return BTC
+	case isOption(ap.InstrumentType):
+		return STC
+	case short:
+		return Buy
+	default:
+		return Sell
+	}
+}
+
+// ClosingLeg returns the leg closing the whole position.
+func (ap AccountPosition) ClosingLeg() (NewOrderLeg, error) {
+	if ap.Quantity == 0 {
+		return NewOrderLeg{}, fmt.Errorf("position %s has no quantity to close", ap.Symbol)
+	}
+
+	return NewOrderLeg{
+		InstrumentType: ap.InstrumentType,
+		Symbol:         ap.Symbol,
+		Quantity:       decimal.NewFromInt(int64(ap.Quantity)).Abs(),
+		Action:         ap.ClosingAction(),
+	}, nil
+}
+
+// ClosingOrder returns a Day Market order closing the whole position.
+func (ap AccountPosition) ClosingOrder() (NewOrder, error) {
+	return ClosingOrder(ap)
+}
+
+// ClosingOrder returns a Day Market order closing the whole positions, one
+// leg per position. Use ClosingOrders for positions that may not fit in one
+// order.
+func ClosingOrder(positions ...AccountPosition) (NewOrder, error) {
+	if len(positions) == 0 {
+		return NewOrder{}, fmt.Errorf("%w: order requires legs", ErrInvalidOrder)
+	}
+
+	legs := make([]NewOrderLeg, 0, len(positions))
+	for _, position := range positions {
+		leg, err := position.ClosingLeg()
+		if err != nil {
+			return NewOrder{}, err
+		}
+		legs = append(legs, leg)
+	}
+
+	return NewOrder{
+		TimeInForce: Day,
+		OrderType:   Market,
+		Legs:        legs,
+	}, nil
+}
+
+// ClosingOrders returns the Day Market orders closing the whole positions,
+// one order per instrument type of at most 4 legs, or a single leg for
+// cryptocurrencies.
+func ClosingOrders(positions ...AccountPosition) ([]NewOrder, error) {
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("%w: order requires legs", ErrInvalidOrder)
+	}
+
+	groups := closingGroups(positions)
+	orders := make([]NewOrder, 0, len(groups))
+	for _, group := range groups {
+		order, err := ClosingOrder(group...)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// closingGroups splits the positions by instrument type, in the order the
+// types are first seen, into groups that fit in one order.
+func closingGroups(positions []AccountPosition) [][]AccountPosition {
+	var types []InstrumentType
+	byType := map[InstrumentType][]AccountPosition{}
+	for _, position := range positions {
+		if _, ok := byType[position.InstrumentType]; !ok {
+			types = append(types, position.InstrumentType)
+		}
+		byType[position.InstrumentType] = append(byType[position.InstrumentType], position)
+	}
+
+	var groups [][]AccountPosition
+	for _, instrumentType := range types {
+		size := maxOrderLegs
+		if instrumentType == Crypto {
+			size = 1
+		}
+
+		group := byType[instrumentType]
+		for len(group) > size {
+			groups = append(groups, group[:size:size])
+			group = group[size:]
+		}
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// SameStrike selects the strike of the option position.
+func SameStrike(position AccountPosition) StrikeSelector {
+	return func(exp Expiration, optionType OptionType) (Strike, bool) {
+		option, err := parsePositionOption(position)
+		if err != nil {
+			return Strike{}, false
+		}
+
+		return AtStrike(option.strike)(exp, optionType)
+	}
+}
+
+// SameDelta selects the strike whose delta in the live chain is closest to
+// the delta of the option position in the chain.
+func SameDelta(chain *LiveChain, position AccountPosition) StrikeSelector {
+	return func(exp Expiration, optionType OptionType) (Strike, bool) {
+		row, ok := chain.Row(position.Symbol)
+		if !ok || row.Delta.IsZero() {
+			return Strike{}, false
+		}
+
+		return ByDelta(chain, row.Delta)(exp, optionType)
+	}
+}
+
+// RollOption returns a builder for the order rolling the option position out
+// to the strike of a later expiration, closing the position and opening the
+// same quantity in the same direction as one order i.e.
+// chain.RollOption(position, ClosestDTE(45), SameStrike(position)).
+// The price and its effect are set on the builder.
+func (sc StrategyChain) RollOption(position AccountPosition, expiration ExpirationSelector, strike StrikeSelector) (*OrderBuilder, error) {
+	if !isOption(position.InstrumentType) {
+		return nil, fmt.Errorf("can't roll %s position %s as an option", position.InstrumentType, position.Symbol)
+	}
+
+	option, err := parsePositionOption(position)
+	if err != nil {
+		return nil, err
+	}
+
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	if exp.ExpirationDate <= option.expirationDate {
+		return nil, fmt.Errorf("roll expiration %s must be after %s of %s",
+			exp.ExpirationDate, option.expirationDate, position.Symbol)
+	}
+
+	closing, err := position.ClosingLeg()
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := sc.option(exp, option.optionType, strike, openingAction(position), 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOrderBuilder().
+		Leg(closing.InstrumentType, closing.Symbol, closing.Quantity, closing.Action).
+		Leg(open.InstrumentType, open.Symbol, closing.Quantity, open.Action), nil
+}
+
+// RollFuture returns a builder for the order rolling the futures position to
+// its roll target, or the next active month when it has none, closing the
+// position and opening the same quantity in the same direction as one order.
+// The price and its effect are set on the builder.
+func (c *Client) RollFuture(position AccountPosition) (*OrderBuilder, error) {
+	if position.InstrumentType != FutureIT {
+		return nil, fmt.Errorf("can't roll %s position %s as a future", position.InstrumentType, position.Symbol)
+	}
+
+	closing, err := position.ClosingLeg()
+	if err != nil {
+		return nil, err
+	}
+
+	future, _, err := c.GetFuture(strings.TrimPrefix(position.Symbol, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	target := future.RollTargetSymbol
+	if target == "" {
+		if target, err = c.nextActiveMonth(future); err != nil {
+			return nil, err
+		}
+	}
+
+	if !strings.HasPrefix(target, "/") {
+		target = "/" + target
+	}
+
+	return NewOrderBuilder().
+		Leg(FutureIT, closing.Symbol, closing.Quantity, closing.Action).
+		Leg(FutureIT, target, closing.Quantity, openingAction(position)), nil
+}
+
+// nextActiveMonth finds the next active month contract of the future's product.
+func (c *Client) nextActiveMonth(future Future) (string, error) {
+	futures, _, err := c.GetFutures(FuturesQuery{ProductCode: []string{future.ProductCode}})
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range futures {
+		if f.NextActiveMonth && f.Symbol != future.Symbol && f.ExpirationDate > future.ExpirationDate {
+			return f.Symbol, nil
+		}
+	}
+
+	return "", fmt.Errorf("no roll target for %s", future.Symbol)
+}
+
+// openingAction returns the action opening another position in the same direction.
+func openingAction(position AccountPosition) OrderAction {
+	short := position.Sign().IsNegative()
+
+	switch {
+	case isOption(position.InstrumentType) && short:
+		return STO
+	case isOption(position.InstrumentType):
+		return BTO
+	case short:
+		return Sell
+	default:
+		return Buy
+	}
+}
+
+type positionOption struct {
+	optionType     OptionType
+	strike         decimal.Decimal
+	expirationDate string
+}
+
+// parsePositionOption reads the option type, strike and expiration from the
+// symbol of an equity or future option position.
+func parsePositionOption(position AccountPosition) (positionOption, error) {
+	switch position.InstrumentType {
+	case EquityOptionIT:
+		occ, err := NewOCCFromString(position.Symbol)
+		if err != nil {
+			return positionOption{}, err
+		}
+		return positionOption{occ.OptionType, occ.Strike, occ.Expiration.Format("2006-01-02")}, nil
+	case FutureOptionIT:
+		fos, err := NewFOSFromString(position.Symbol)
+		if err != nil {
+			return positionOption{}, err
+		}
+		return positionOption{fos.OptionType, fos.Strike, fos.Expiration.Format("2006-01-02")}, nil
+	default:
+		return positionOption{}, errors.New("position is not an option")
+	}
+}
+
+func isOption(instrumentType InstrumentType) bool {
+	return instrumentType == EquityOptionIT || instrumentType == FutureOptionIT
+}
@@ -0,0 +1,190 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionClosingOrder(t *testing.T) {
+	for _, tc := range []struct {
+		instrumentType InstrumentType
+		direction      Direction
+		expected       OrderAction
+	}{
+		{EquityOptionIT, Long, STC},
+		{EquityOptionIT, Short, BTC},
+		{FutureOptionIT, Short, BTC},
+		{EquityIT, Long, Sell},
+		{EquityIT, Short, Buy},
+		{FutureIT, Short, Buy},
+		{Crypto, Long, Sell},
+	} {
+		position := AccountPosition{InstrumentType: tc.instrumentType, Symbol: "X", Quantity: 3, QuantityDirection: tc.direction}
+		require.Equal(t, tc.expected, position.ClosingAction(), "%s %s", tc.direction, tc.instrumentType)
+	}
+
+	order, err := AccountPosition{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: 100, QuantityDirection: Long}.ClosingOrder()
+	require.NoError(t, err)
+	require.Equal(t, Market, order.OrderType)
+	require.Equal(t, Day, order.TimeInForce)
+	require.Equal(t, []NewOrderLeg{
+		{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: decimal.NewFromInt(100), Action: Sell},
+	}, order.Legs)
+
+	_, err = AccountPosition{Symbol: "AAPL"}.ClosingOrder()
+	require.EqualError(t, err, "position AAPL has no quantity to close")
+
+	_, err = ClosingOrder()
+	require.ErrorIs(t, err, ErrInvalidOrder)
+}
+
+func TestClosingOrders(t *testing.T) {
+	var positions []AccountPosition
+	for i := 0; i < 5; i++ {
+		positions = append(positions, AccountPosition{InstrumentType: EquityOptionIT, Symbol: fmt.Sprintf("O%d", i), Quantity: 1, QuantityDirection: Short})
+	}
+	positions = append(positions,
+		AccountPosition{InstrumentType: Crypto, Symbol: "BTC/USD", Quantity: 1, QuantityDirection: Long},
+		AccountPosition{InstrumentType: Crypto, Symbol: "ETH/USD", Quantity: 1, QuantityDirection: Long},
+		AccountPosition{InstrumentType: EquityIT, Symbol: "AAPL", Quantity: 100, QuantityDirection: Long})
+
+	orders, err := ClosingOrders(positions...)
+	require.NoError(t, err)
+
+	var symbols [][]string
+	for _, order := range orders {
+		require.NoError(t, ValidateOrder(order))
+
+		var legs []string
+		for _, leg := range order.Legs {
+			legs = append(legs, leg.Symbol)
+		}
+		symbols = append(symbols, legs)
+	}
+	require.Equal(t, [][]string{{"O0", "O1", "O2", "O3"}, {"O4"}, {"BTC/USD"}, {"ETH/USD"}, {"AAPL"}}, symbols)
+
+	_, err = ClosingOrders(AccountPosition{Symbol: "AAPL"})
+	require.EqualError(t, err, "position AAPL has no quantity to close")
+
+	_, err = ClosingOrders()
+	require.ErrorIs(t, err, ErrInvalidOrder)
+}
+
+func TestRollOption(t *testing.T) {
+	sc := testStrategyChain()
+	position := AccountPosition{
+		InstrumentType:    EquityOptionIT,
+		Symbol:            "SPY   230915P00420000",
+		Quantity:          2,
+		QuantityDirection: Short,
+	}
+
+	builder, err := sc.RollOption(position, ClosestDTE(45), SameStrike(position))
+	require.NoError(t, err)
+
+	order, err := builder.Limit(decimal.RequireFromString("1.05")).Credit().Build()
+	require.NoError(t, err)
+	require.Equal(t, []NewOrderLeg{
+		{InstrumentType: EquityOptionIT, Symbol: "SPY   230915P00420000", Quantity: decimal.NewFromInt(2), Action: BTC},
+		{InstrumentType: EquityOptionIT, Symbol: "SPY   230929P00420000", Quantity: decimal.NewFromInt(2), Action: STO},
+	}, order.Legs)
+
+	chain, err := NewLiveChain(nil, "SPY", sc.Expirations)
+	require.NoError(t, err)
+	chain.Apply(Greeks{EventSymbol: ".SPY230915P420", Delta: decimal.RequireFromString("-0.16")})
+	chain.Apply(Greeks{EventSymbol: ".SPY230929P410", Delta: decimal.RequireFromString("-0.12")})
+	chain.Apply(Greeks{EventSymbol: ".SPY230929P430", Delta: decimal.RequireFromString("-0.17")})
+
+	builder, err = sc.RollOption(position, ClosestDTE(45), SameDelta(chain, position))
+	require.NoError(t, err)
+
+	order, err = builder.Limit(decimal.RequireFromString("0.20")).Debit().Build()
+	require.NoError(t, err)
+	require.Equal(t, "SPY   230929P00430000", order.Legs[1].Symbol)
+
+	_, err = sc.RollOption(position, ClosestDTE(30), SameStrike(position))
+	require.EqualError(t, err, "roll expiration 2023-09-15 must be after 2023-09-15 of SPY   230915P00420000")
+
+	_, err = sc.RollOption(position, ClosestDTE(45), strike(425))
+	require.ErrorIs(t, err, ErrStrikeNotFound)
+
+	_, err = sc.RollOption(AccountPosition{InstrumentType: EquityIT, Symbol: "SPY"}, ClosestDTE(45), strike(420))
+	require.EqualError(t, err, "can't roll Equity position SPY as an option")
+}
+
+func TestRollFuture(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/instruments/futures/ESM3", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, futureResp)
+	})
+
+	builder, err := client.RollFuture(AccountPosition{InstrumentType: FutureIT, Symbol: "/ESM3", Quantity: 1, QuantityDirection: Long})
+	require.NoError(t, err)
+
+	order, err := builder.Limit(decimal.RequireFromString("45.25")).Debit().Build()
+	require.NoError(t, err)
+	require.Equal(t, []NewOrderLeg{
+		{InstrumentType: FutureIT, Symbol: "/ESM3", Quantity: decimal.NewFromInt(1), Action: Sell},
+		{InstrumentType: FutureIT, Symbol: "/ESU3", Quantity: decimal.NewFromInt(1), Action: Buy},
+	}, order.Legs)
+
+	_, err = client.RollFuture(AccountPosition{InstrumentType: EquityIT, Symbol: "SPY", Quantity: 1})
+	require.EqualError(t, err, "can't roll Equity position SPY as a future")
+}
+
+func TestRollFutureNextActiveMonth(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/instruments/futures/CLQ3", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"symbol":"/CLQ3","product-code":"CL","expiration-date":"2023-07-20","active-month":true}}`)
+	})
+	mux.HandleFunc("/instruments/futures", func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, "CL", request.URL.Query().Get("product-code[]"))
+		fmt.Fprint(writer, `{"data":{"items":[
+			{"symbol":"/CLQ3","product-code":"CL","expiration-date":"2023-07-20","active-month":true},
+			{"symbol":"/CLU3","product-code":"CL","expiration-date":"2023-08-22","next-active-month":true},
+			{"symbol":"/CLV3","product-code":"CL","expiration-date":"2023-09-20"}
+		]}}`)
+	})
+
+	builder, err := client.RollFuture(AccountPosition{InstrumentType: FutureIT, Symbol: "/CLQ3", Quantity: 2, QuantityDirection: Short})
+	require.NoError(t, err)
+
+	order, err := builder.Limit(decimal.RequireFromString("0.45")).Credit().Build()
+	require.NoError(t, err)
+	require.Equal(t, Buy, order.Legs[0].Action)
+	require.Equal(t, "/CLU3", order.Legs[1].Symbol)
+	require.Equal(t, Sell, order.Legs[1].Action)
+	require.Equal(t, "2", order.Legs[1].Quantity.String())
+}
+
+func TestRollFutureError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/instruments/futures/ESM3", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.RollFuture(AccountPosition{InstrumentType: FutureIT, Symbol: "/ESM3", Quantity: 1})
+	expectedUnauthorized(t, err)
+}
+
+func TestParsePositionOptionFractionalStrike(t *testing.T) {
+	option, err := parsePositionOption(AccountPosition{InstrumentType: FutureOptionIT, Symbol: "./ZNZ3 OZNF4 231222P108.5"})
+	require.NoError(t, err)
+	require.Equal(t, "108.5", option.strike.String())
+	require.Equal(t, Put, option.optionType)
+	require.Equal(t, "2023-12-22", option.expirationDate)
+
+	_, err = parsePositionOption(AccountPosition{InstrumentType: EquityIT, Symbol: "AAPL"})
+	require.EqualError(t, err, "position is not an option")
+}
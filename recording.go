@@ -0,0 +1,395 @@
+package tasty
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	marketSource  = "market"
+	accountSource = "account"
+	// bufio.Scanner line limit, option chain greeks can be large.
+	maxRecordingLine = 1024 * 1024
+)
+
+// RecordedEvent is a single line of an NDJSON recording.
+type RecordedEvent struct {
+	ReceivedAt time.Time `json:"received-at"`
+	// market or account
+	Source string          `json:"source"`
+	Type   string          `json:"type"`
+	Event  json.RawMessage `json:"event"`
+}
+
+// Recorder writes market and account events with their receive timestamps
+// as NDJSON. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer []io.Closer
+	now    func() time.Time
+}
+
+// NewRecorder records events to the writer as uncompressed NDJSON.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, now: time.Now}
+}
+
+// CreateRecording creates the file at path and records to it,
+// gzip compressed when the path ends in .gz.
+func CreateRecording(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(path, ".gz") {
+		return &Recorder{w: f, closer: []io.Closer{f}, now: time.Now}, nil
+	}
+
+	gz := gzip.NewWriter(f)
+
+	return &Recorder{w: gz, closer: []io.Closer{gz, f}, now: time.Now}, nil
+}
+
+// RecordMarketEvent writes a market event.
+func (r *Recorder) RecordMarketEvent(event MarketEvent) error {
+	return r.record(marketSource, string(event.EventType()), event)
+}
+
+// RecordAccountEvent writes an account event.
+func (r *Recorder) RecordAccountEvent(event AccountEvent) error {
+	return r.record(accountSource, string(event.Type), event)
+}
+
+// TeeMarketEvents records every event from in and forwards it to the returned channel,
+// which is closed after in is closed. Recording errors stop recording but not forwarding.
+func (r *Recorder) TeeMarketEvents(in <-chan MarketEvent) <-chan MarketEvent {
+	out := make(chan MarketEvent, cap(in))
+
+	go func() {
+		defer close(out)
+		var err error
+		for event := range in {
+			if err == nil {
+				err = r.RecordMarketEvent(event)
+			}
+			out <- event
+		}
+	}()
+
+	return out
+}
+
+// TeeAccountEvents records every event from in and forwards it to the returned channel,
+// which is closed after in is closed. Recording errors stop recording but not forwarding.
+func (r *Recorder) TeeAccountEvents(in <-chan AccountEvent) <-chan AccountEvent {
+	out := make(chan AccountEvent, cap(in))
+
+	go func() {
+		defer close(out)
+		var err error
+		for event := range in {
+			if err == nil {
+				err = r.RecordAccountEvent(event)
+			}
+			out <- event
+		}
+	}()
+
+	return out
+}
+
+// Close flushes and closes the underlying file when created by CreateRecording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	for _, c := range r.closer {
+		if cErr := c.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+	r.closer = nil
+
+	return err
+}
+
+func (r *Recorder) record(source, eventType string, event any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	line, err := json.Marshal(RecordedEvent{
+		ReceivedAt: r.now(),
+		Source:     source,
+		Type:       eventType,
+		Event:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.w.Write(append(line, '\n'))
+
+	return err
+}
+
+// RecordingFeed is a MarketDataFeed that records every event it delivers.
+type RecordingFeed struct {
+	feed   MarketDataFeed
+	events <-chan MarketEvent
+}
+
+// NewRecordingFeed wraps the feed so that consumers reading from it are recorded.
+func NewRecordingFeed(feed MarketDataFeed, recorder *Recorder) *RecordingFeed {
+	return &RecordingFeed{feed: feed, events: recorder.TeeMarketEvents(feed.Events())}
+}
+
+// Subscribe subscribes the wrapped feed.
+func (rf *RecordingFeed) Subscribe(subs ...FeedSubscription) error {
+	return rf.feed.Subscribe(subs...)
+}
+
+// Unsubscribe unsubscribes the wrapped feed.
+func (rf *RecordingFeed) Unsubscribe(subs ...FeedSubscription) error {
+	return rf.feed.Unsubscribe(subs...)
+}
+
+// Events returns the recorded events of the wrapped feed.
+func (rf *RecordingFeed) Events() <-chan MarketEvent {
+	return rf.events
+}
+
+// ReplayConfig configures the pace of a Replayer.
+type ReplayConfig struct {
+	// Playback speed relative to the recording i.e. 1 for the original
+	// speed, 10 for 10x. Zero replays as fast as the consumers read.
+	Speed float64
+}
+
+// Replayer plays a recording back as a MarketDataFeed. Events are delivered
+// in recorded order, one at a time, so consumers see the same sequence on
+// every replay.
+type Replayer struct {
+	r             io.Reader
+	closer        []io.Closer
+	config        ReplayConfig
+	mu            sync.Mutex
+	subs          map[FeedSubscription]struct{}
+	events        chan MarketEvent
+	accountEvents chan AccountEvent
+	wantMarket    bool
+	wantAccount   bool
+	after         func(time.Duration) <-chan time.Time
+}
+
+// NewReplayer replays an NDJSON recording, gzip compressed or not, from the reader.
+func NewReplayer(r io.Reader, config ReplayConfig) *Replayer {
+	return &Replayer{
+		r:             r,
+		config:        config,
+		subs:          map[FeedSubscription]struct{}{},
+		events:        make(chan MarketEvent),
+		accountEvents: make(chan AccountEvent),
+		after:         time.After,
+	}
+}
+
+// OpenRecording opens a recording file for replay.
+func OpenRecording(path string, config ReplayConfig) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := NewReplayer(f, config)
+	rp.closer = []io.Closer{f}
+
+	return rp, nil
+}
+
+// Subscribe limits the replayed market events to the subscriptions.
+// Without any subscriptions every market event is replayed.
+func (rp *Replayer) Subscribe(subs ...FeedSubscription) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	rp.wantMarket = true
+
+	for _, sub := range subs {
+		rp.subs[sub] = struct{}{}
+	}
+
+	return nil
+}
+
+// Unsubscribe stops replaying market events for the subscriptions.
+func (rp *Replayer) Unsubscribe(subs ...FeedSubscription) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	for _, sub := range subs {
+		delete(rp.subs, sub)
+	}
+
+	return nil
+}
+
+// Events returns the replayed market events. The channel is closed when
+// playback ends. Market events are only replayed when this or Subscribe has
+// been called before Play.
+func (rp *Replayer) Events() <-chan MarketEvent {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	rp.wantMarket = true
+
+	return rp.events
+}
+
+// AccountEvents returns the replayed account events. Account events are
+// only replayed when this has been called before Play.
+func (rp *Replayer) AccountEvents() <-chan AccountEvent {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	rp.wantAccount = true
+
+	return rp.accountEvents
+}
+
+// Play replays the recording, blocking until every event has been consumed,
+// an error occurs or the context is done. Events of a channel nobody asked
+// for before Play are skipped. Both event channels are closed when it
+// returns.
+func (rp *Replayer) Play(ctx context.Context) error {
+	defer close(rp.events)
+	defer close(rp.accountEvents)
+	defer rp.close()
+
+	rp.mu.Lock()
+	wantMarket, wantAccount := rp.wantMarket, rp.wantAccount
+	rp.mu.Unlock()
+
+	reader, err := decompress(rp.r)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxRecordingLine)
+
+	var last time.Time
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var recorded RecordedEvent
+		if err = json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return fmt.Errorf("recording line %d: %w", line, err)
+		}
+
+		if rp.config.Speed > 0 && !last.IsZero() && recorded.ReceivedAt.After(last) {
+			select {
+			case <-rp.after(time.Duration(float64(recorded.ReceivedAt.Sub(last)) / rp.config.Speed)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		last = recorded.ReceivedAt
+
+		if err = rp.deliver(ctx, recorded, wantMarket, wantAccount); err != nil {
+			return fmt.Errorf("recording line %d: %w", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (rp *Replayer) deliver(ctx context.Context, recorded RecordedEvent, wantMarket, wantAccount bool) error {
+	switch recorded.Source {
+	case marketSource:
+		if !wantMarket {
+			return nil
+		}
+		event, err := unmarshalMarketEvent(MarketEventType(recorded.Type), recorded.Event)
+		if err != nil || event == nil || !rp.subscribed(event) {
+			return err
+		}
+		select {
+		case rp.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case accountSource:
+		if !wantAccount {
+			return nil
+		}
+
+		var event AccountEvent
+		if err := json.Unmarshal(recorded.Event, &event); err != nil {
+			return err
+		}
+		select {
+		case rp.accountEvents <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	default:
+		return errors.New("unknown event source: " + recorded.Source)
+	}
+
+	return nil
+}
+
+func (rp *Replayer) subscribed(event MarketEvent) bool {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if len(rp.subs) == 0 {
+		return true
+	}
+
+	_, ok := rp.subs[FeedSubscription{Type: event.EventType(), Symbol: event.Symbol()}]
+
+	return ok
+}
+
+func (rp *Replayer) close() {
+	for _, c := range rp.closer {
+		c.Close()
+	}
+}
+
+// decompress transparently handles gzip compressed recordings.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+
+	return br, nil
+}
@@ -0,0 +1,203 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	buf := new(bytes.Buffer)
+	recorder := NewRecorder(buf)
+
+	start := time.Date(2023, 8, 14, 14, 30, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return start }
+
+	quote := Quote{EventSymbol: "SPY", BidPrice: decimal.RequireFromString("449.9"), AskPrice: decimal.RequireFromString("450")}
+	require.NoError(t, recorder.RecordMarketEvent(quote))
+
+	recorder.now = func() time.Time { return start.Add(time.Second) }
+	order := AccountEvent{Type: OrderNotification, Data: json.RawMessage(`{"id":1}`), Timestamp: 1}
+	require.NoError(t, recorder.RecordAccountEvent(order))
+
+	recorder.now = func() time.Time { return start.Add(3 * time.Second) }
+	greeks := Greeks{EventSymbol: ".SPY230818C450", Delta: decimal.RequireFromString("0.5")}
+	require.NoError(t, recorder.RecordMarketEvent(greeks))
+
+	require.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	replayer := NewReplayer(bytes.NewReader(buf.Bytes()), ReplayConfig{Speed: 2})
+
+	var slept []time.Duration
+	replayer.after = func(d time.Duration) <-chan time.Time {
+		slept = append(slept, d)
+		return time.After(0)
+	}
+
+	events := replayer.Events()
+	accountEvents := replayer.AccountEvents()
+
+	errs := make(chan error)
+	go func() { errs <- replayer.Play(context.Background()) }()
+
+	replayedQuote, ok := (<-events).(Quote)
+	require.True(t, ok)
+	require.True(t, quote.BidPrice.Equal(replayedQuote.BidPrice))
+
+	replayedOrder := <-accountEvents
+	require.Equal(t, OrderNotification, replayedOrder.Type)
+	require.JSONEq(t, `{"id":1}`, string(replayedOrder.Data))
+
+	replayedGreeks, ok := (<-events).(Greeks)
+	require.True(t, ok)
+	require.Equal(t, greeks.EventSymbol, replayedGreeks.EventSymbol)
+
+	require.NoError(t, <-errs)
+	require.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, slept)
+
+	_, open := <-replayer.Events()
+	require.False(t, open)
+}
+
+func TestReplayerSubscriptions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	recorder := NewRecorder(buf)
+
+	require.NoError(t, recorder.RecordMarketEvent(Quote{EventSymbol: "SPY"}))
+	require.NoError(t, recorder.RecordAccountEvent(AccountEvent{Type: OrderNotification}))
+	require.NoError(t, recorder.RecordMarketEvent(Quote{EventSymbol: "QQQ"}))
+	require.NoError(t, recorder.RecordMarketEvent(Trade{EventSymbol: "SPY"}))
+
+	replayer := NewReplayer(buf, ReplayConfig{})
+	require.NoError(t, replayer.Subscribe(
+		FeedSubscription{Type: QuoteEvent, Symbol: "SPY"},
+		FeedSubscription{Type: TradeEvent, Symbol: "SPY"},
+	))
+	require.NoError(t, replayer.Unsubscribe(FeedSubscription{Type: TradeEvent, Symbol: "SPY"}))
+
+	go replayer.Play(context.Background())
+
+	var symbols []string
+	for event := range replayer.Events() {
+		symbols = append(symbols, string(event.EventType())+":"+event.Symbol())
+	}
+
+	// account events are skipped when nobody asked for them
+	require.Equal(t, []string{"Quote:SPY"}, symbols)
+}
+
+func TestRecordingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ndjson.gz")
+
+	recorder, err := CreateRecording(path)
+	require.NoError(t, err)
+
+	feed := newFakeFeed()
+	recording := NewRecordingFeed(feed, recorder)
+
+	require.NoError(t, recording.Subscribe(FeedSubscription{Type: QuoteEvent, Symbol: "SPY"}))
+	require.NoError(t, recording.Unsubscribe(FeedSubscription{Type: QuoteEvent, Symbol: "SPY"}))
+	require.Len(t, feed.subscribed, 1)
+	require.Len(t, feed.unsubscribed, 1)
+
+	feed.events <- Quote{EventSymbol: "SPY"}
+	feed.events <- Summary{EventSymbol: "SPY", OpenInterest: decimal.NewFromInt(10)}
+	close(feed.events)
+
+	var forwarded int
+	for range recording.Events() {
+		forwarded++
+	}
+	require.Equal(t, 2, forwarded)
+
+	account := make(chan AccountEvent, 1)
+	account <- AccountEvent{Type: PositionNotification, Data: json.RawMessage(`{}`)}
+	close(account)
+	for range recorder.TeeAccountEvents(account) {
+		forwarded++
+	}
+	require.Equal(t, 3, forwarded)
+
+	require.NoError(t, recorder.Close())
+
+	replayer, err := OpenRecording(path, ReplayConfig{})
+	require.NoError(t, err)
+
+	replayed := replayer.Events()
+	go replayer.Play(context.Background())
+
+	var events []MarketEvent
+	for event := range replayed {
+		events = append(events, event)
+	}
+
+	require.Len(t, events, 2)
+	summary, ok := events[1].(Summary)
+	require.True(t, ok)
+	require.Equal(t, "10", summary.OpenInterest.String())
+
+	_, err = OpenRecording(filepath.Join(t.TempDir(), "missing.ndjson"), ReplayConfig{})
+	require.Error(t, err)
+}
+
+func TestReplayerErrors(t *testing.T) {
+	replayer := NewReplayer(strings.NewReader("{not json}\n"), ReplayConfig{})
+	require.ErrorContains(t, replayer.Play(context.Background()), "recording line 1")
+
+	replayer = NewReplayer(strings.NewReader(`{"source":"other","type":"Quote","event":{}}`+"\n"), ReplayConfig{})
+	require.EqualError(t, replayer.Play(context.Background()), "recording line 1: unknown event source: other")
+
+	replayer = NewReplayer(strings.NewReader("\n"), ReplayConfig{})
+	require.NoError(t, replayer.Play(context.Background()))
+}
+
+func TestReplayerCancel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	recorder := NewRecorder(buf)
+	require.NoError(t, recorder.RecordMarketEvent(Quote{EventSymbol: "SPY"}))
+	require.NoError(t, recorder.RecordMarketEvent(Quote{EventSymbol: "QQQ"}))
+
+	// nobody reads the events asked for
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	replayer := NewReplayer(bytes.NewReader(buf.Bytes()), ReplayConfig{})
+	events := replayer.Events()
+	go func() { errs <- replayer.Play(ctx) }()
+
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+
+	_, open := <-events
+	require.False(t, open)
+}
+
+func TestReplayerAccountEventsOnly(t *testing.T) {
+	buf := new(bytes.Buffer)
+	recorder := NewRecorder(buf)
+	require.NoError(t, recorder.RecordMarketEvent(Quote{EventSymbol: "SPY"}))
+	require.NoError(t, recorder.RecordAccountEvent(AccountEvent{Type: OrderNotification, Data: json.RawMessage(`{"id":1}`)}))
+	require.NoError(t, recorder.RecordMarketEvent(Trade{EventSymbol: "SPY"}))
+	require.NoError(t, recorder.RecordAccountEvent(AccountEvent{Type: BalanceNotification, Data: json.RawMessage(`{}`)}))
+
+	replayer := NewReplayer(buf, ReplayConfig{})
+	accountEvents := replayer.AccountEvents()
+
+	errs := make(chan error)
+	go func() { errs <- replayer.Play(context.Background()) }()
+
+	// the market events nobody reads don't block playback
+	var types []AccountEventType
+	for event := range accountEvents {
+		types = append(types, event.Type)
+	}
+
+	require.NoError(t, <-errs)
+	require.Equal(t, []AccountEventType{OrderNotification, BalanceNotification}, types)
+}
@@ -0,0 +1,469 @@
+package tasty
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrRiskRejected is returned when an order fails a pre-trade risk check.
+	ErrRiskRejected = errors.New("order rejected by risk check")
+	// ErrKillSwitch is returned for opening orders while the kill switch is engaged.
+	ErrKillSwitch = errors.New("kill switch is engaged")
+)
+
+// OrderService submits orders. It is implemented by Client and RiskEngine so
+// risk checks can be wrapped around any order flow.
+type OrderService interface {
+	SubmitOrder(accountNumber string, order NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error)
+	SubmitOrderDryRun(accountNumber string, order NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error)
+}
+
+// RiskRule checks an order before it is submitted, returning an error to block it.
+type RiskRule interface {
+	Check(check *RiskCheck) error
+}
+
+// RiskRuleFunc adapts a function to a RiskRule.
+type RiskRuleFunc func(check *RiskCheck) error
+
+// Check calls the function.
+func (f RiskRuleFunc) Check(check *RiskCheck) error {
+	return f(check)
+}
+
+// RiskCheck is an order being checked. The account data rules need is
+// loaded on first use and shared by every rule of the check.
+type RiskCheck struct {
+	AccountNumber string
+	Order         NewOrder
+
+	client  *Client
+	service OrderService
+
+	mu         sync.Mutex
+	positions  []AccountPosition
+	liveOrders []Order
+	dryRun     *OrderResponse
+}
+
+// RiskEngine runs pre-trade risk rules before orders are submitted through
+// the wrapped order service, and blocks every opening order while its kill
+// switch is engaged. It is safe for concurrent use.
+type RiskEngine struct {
+	client  *Client
+	service OrderService
+	killed  atomic.Bool
+
+	mu    sync.RWMutex
+	rules []RiskRule
+}
+
+// NewRiskEngine creates a risk engine submitting orders through the client.
+func (c *Client) NewRiskEngine(rules ...RiskRule) *RiskEngine {
+	return c.NewRiskEngineFor(c, rules...)
+}
+
+// NewRiskEngineFor creates a risk engine wrapped around the order service,
+// loading account data for the rules with the client.
+func (c *Client) NewRiskEngineFor(service OrderService, rules ...RiskRule) *RiskEngine {
+	return &RiskEngine{client: c, service: service, rules: rules}
+}
+
+// Add appends rules to the engine.
+func (re *RiskEngine) Add(rules ...RiskRule) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	re.rules = append(re.rules, rules...)
+}
+
+// Kill engages the kill switch, blocking every opening order.
+func (re *RiskEngine) Kill() {
+	re.killed.Store(true)
+}
+
+// Resume releases the kill switch.
+func (re *RiskEngine) Resume() {
+	re.killed.Store(false)
+}
+
+// Killed returns whether or not the kill switch is engaged.
+func (re *RiskEngine) Killed() bool {
+	return re.killed.Load()
+}
+
+// Check runs the kill switch and every rule against the order. Failures are
+// joined and wrap ErrRiskRejected.
+func (re *RiskEngine) Check(accountNumber string, order NewOrder) error {
+	check := &RiskCheck{AccountNumber: accountNumber, Order: order, client: re.client, service: re.service}
+
+	if re.Killed() {
+		opening, err := check.Opening()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRiskRejected, err)
+		}
+		if opening {
+			return fmt.Errorf("%w: %w", ErrRiskRejected, ErrKillSwitch)
+		}
+	}
+
+	re.mu.RLock()
+	rules := append([]RiskRule(nil), re.rules...)
+	re.mu.RUnlock()
+
+	var errs []error
+	for _, rule := range rules {
+		if err := rule.Check(check); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", ErrRiskRejected, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SubmitOrder checks the order and submits it when every check passes.
+func (re *RiskEngine) SubmitOrder(accountNumber string, order NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	if err := re.Check(accountNumber, order); err != nil {
+		return OrderResponse{}, nil, nil, err
+	}
+
+	return re.service.SubmitOrder(accountNumber, order)
+}
+
+// SubmitOrderDryRun dry runs the order without risk checks.
+func (re *RiskEngine) SubmitOrderDryRun(accountNumber string, order NewOrder) (OrderResponse, *OrderErrorResponse, *http.Response, error) {
+	return re.service.SubmitOrderDryRun(accountNumber, order)
+}
+
+// Positions returns the account's positions, closed positions included.
+func (rc *RiskCheck) Positions() ([]AccountPosition, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.positions == nil {
+		positions, _, err := rc.client.GetAccountPositions(rc.AccountNumber, AccountPositionQuery{IncludeClosedPositions: true})
+		if err != nil {
+			return nil, err
+		}
+		rc.positions = append([]AccountPosition{}, positions...)
+	}
+
+	return rc.positions, nil
+}
+
+// LiveOrders returns the account's live orders.
+func (rc *RiskCheck) LiveOrders() ([]Order, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.liveOrders == nil {
+		orders, _, err := rc.client.GetAccountLiveOrders(rc.AccountNumber)
+		if err != nil {
+			return nil, err
+		}
+		rc.liveOrders = append([]Order{}, orders...)
+	}
+
+	return rc.liveOrders, nil
+}
+
+// DryRun returns the dry run of the order.
+func (rc *RiskCheck) DryRun() (OrderResponse, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.dryRun == nil {
+		resp, orderErr, _, err := rc.service.SubmitOrderDryRun(rc.AccountNumber, rc.Order)
+		if err != nil {
+			return OrderResponse{}, err
+		}
+		if orderErr != nil {
+			return OrderResponse{}, fmt.Errorf("dry run rejected: %s", orderErr.Message)
+		}
+		rc.dryRun = &resp
+	}
+
+	return *rc.dryRun, nil
+}
+
+// Opening returns whether or not the order opens or adds to a position. Buy
+// and Sell legs are opening unless they reduce an existing position.
+func (rc *RiskCheck) Opening() (bool, error) {
+	for _, leg := range rc.Order.Legs {
+		switch leg.Action {
+		case BTO, STO:
+			return true, nil
+		case BTC, STC:
+			continue
+		}
+
+		positions, err := rc.Positions()
+		if err != nil {
+			return false, err
+		}
+
+		current := positionQuantity(positions, leg.Symbol)
+		next := current.Add(legQuantity(leg))
+		if next.Abs().GreaterThan(current.Abs()) || next.Sign()*current.Sign() < 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// MaxOrderSize blocks legs whose quantity exceeds the limit of their
+// instrument type. Instrument types without a positive limit aren't checked.
+func MaxOrderSize(limits map[InstrumentType]decimal.Decimal) RiskRule {
+	return RiskRuleFunc(func(check *RiskCheck) error {
+		var errs []error
+		for _, leg := range check.Order.Legs {
+			limit := limits[leg.InstrumentType]
+			if limit.IsPositive() && leg.Quantity.GreaterThan(limit) {
+				errs = append(errs, fmt.Errorf("%s order size %s exceeds the limit of %s", leg.Symbol, leg.Quantity, limit))
+			}
+		}
+
+		return errors.Join(errs...)
+	})
+}
+
+// MaxPositionSize blocks legs that would grow a position beyond the limit of
+// their instrument type. Instrument types without a positive limit aren't
+// checked.
+func MaxPositionSize(limits map[InstrumentType]decimal.Decimal) RiskRule {
+	return RiskRuleFunc(func(check *RiskCheck) error {
+		positions, err := check.Positions()
+		if err != nil {
+			return err
+		}
+
+		var errs []error
+		for _, leg := range check.Order.Legs {
+			limit := limits[leg.InstrumentType]
+			if !limit.IsPositive() {
+				continue
+			}
+
+			current := positionQuantity(positions, leg.Symbol)
+			next := current.Add(legQuantity(leg))
+			if next.Abs().GreaterThan(limit) && next.Abs().GreaterThan(current.Abs()) {
+				errs = append(errs, fmt.Errorf("%s position size %s exceeds the limit of %s", leg.Symbol, next.Abs(), limit))
+			}
+		}
+
+		return errors.Join(errs...)
+	})
+}
+
+// MaxNotional blocks orders worth more than the maximum, the value of
+// notional orders or the price per unit times the units and the multiplier
+// of the instrument type, 100 for options and 1 otherwise. Unpriced orders
+// use the change in buying power of their dry run.
+func MaxNotional(maximum decimal.Decimal, multipliers map[InstrumentType]decimal.Decimal) RiskRule {
+	return RiskRuleFunc(func(check *RiskCheck) error {
+		order := check.Order
+
+		var notional decimal.Decimal
+		switch {
+		case order.Value.IsPositive():
+			notional = order.Value
+		case order.Price.IsPositive():
+			notional = order.Price.Mul(orderUnits(order)).Mul(orderMultiplier(order, multipliers))
+		default:
+			dryRun, err := check.DryRun()
+			if err != nil {
+				return err
+			}
+			notional = dryRun.BuyingPowerEffect.ChangeInBuyingPower.Abs()
+		}
+
+		if notional.GreaterThan(maximum) {
+			return fmt.Errorf("order notional %s exceeds the limit of %s", notional, maximum)
+		}
+
+		return nil
+	})
+}
+
+// MaxOpenOrdersPerUnderlying blocks opening orders when the account already
+// has the maximum of working orders in the order's underlying.
+func MaxOpenOrdersPerUnderlying(maximum int) RiskRule {
+	return RiskRuleFunc(func(check *RiskCheck) error {
+		opening, err := check.Opening()
+		if err != nil || !opening {
+			return err
+		}
+
+		orders, err := check.LiveOrders()
+		if err != nil {
+			return err
+		}
+
+		underlying := orderUnderlying(check.Order)
+		working := 0
+		for _, order := range orders {
+			if order.UnderlyingSymbol == underlying && order.Status.IsWorking() {
+				working++
+			}
+		}
+
+		if working >= maximum {
+			return fmt.Errorf("%d working orders in %s reaches the limit of %d", working, underlying, maximum)
+		}
+
+		return nil
+	})
+}
+
+// MaxDailyLoss blocks opening orders once the account's realized loss today,
+// from the RealizedToday of its positions, reaches the maximum.
+func MaxDailyLoss(maximum decimal.Decimal) RiskRule {
+	return RiskRuleFunc(func(check *RiskCheck) error {
+		opening, err := check.Opening()
+		if err != nil || !opening {
+			return err
+		}
+
+		positions, err := check.Positions()
+		if err != nil {
+			return err
+		}
+
+		realized := decimal.Zero
+		for _, position := range positions {
+			if position.RealizedTodayEffect == Debit {
+				realized = realized.Sub(position.RealizedToday)
+			} else {
+				realized = realized.Add(position.RealizedToday)
+			}
+		}
+
+		if loss := realized.Neg(); !loss.LessThan(maximum) {
+			return fmt.Errorf("realized loss today of %s reaches the limit of %s", loss, maximum)
+		}
+
+		return nil
+	})
+}
+
+// MinBuyingPower blocks orders leaving less than the minimum buying power,
+// from the BuyingPowerEffect of their dry run.
+func MinBuyingPower(minimum decimal.Decimal) RiskRule {
+	return RiskRuleFunc(func(check *RiskCheck) error {
+		dryRun, err := check.DryRun()
+		if err != nil {
+			return err
+		}
+
+		effect := dryRun.BuyingPowerEffect
+		remaining := effect.NewBuyingPower
+		if effect.NewBuyingPowerEffect == Debit {
+			remaining = remaining.Neg()
+		}
+
+		if remaining.LessThan(minimum) {
+			return fmt.Errorf("buying power after the order of %s is below the minimum of %s", remaining, minimum)
+		}
+
+		return nil
+	})
+}
+
+// PositionLimitRules returns the order size, position size and open orders
+// per underlying rules of the account's position limit.
+func PositionLimitRules(limit PositionLimit) []RiskRule {
+	rules := []RiskRule{
+		MaxOrderSize(map[InstrumentType]decimal.Decimal{
+			EquityIT:       decimal.NewFromInt(int64(limit.EquityOrderSize)),
+			EquityOptionIT: decimal.NewFromInt(int64(limit.EquityOptionOrderSize)),
+			FutureIT:       decimal.NewFromInt(int64(limit.FutureOrderSize)),
+			FutureOptionIT: decimal.NewFromInt(int64(limit.FutureOptionOrderSize)),
+		}),
+		MaxPositionSize(map[InstrumentType]decimal.Decimal{
+			EquityIT:       decimal.NewFromInt(int64(limit.EquityPositionSize)),
+			EquityOptionIT: decimal.NewFromInt(int64(limit.EquityOptionPositionSize)),
+			FutureIT:       decimal.NewFromInt(int64(limit.FuturePositionSize)),
+			FutureOptionIT: decimal.NewFromInt(int64(limit.FutureOptionPositionSize)),
+		}),
+	}
+
+	if limit.UnderlyingOpeningOrderLimit > 0 {
+		rules = append(rules, MaxOpenOrdersPerUnderlying(limit.UnderlyingOpeningOrderLimit))
+	}
+
+	return rules
+}
+
+// LoadPositionLimitRules returns the PositionLimitRules of the account.
+func (c *Client) LoadPositionLimitRules(accountNumber string) ([]RiskRule, error) {
+	limit, _, err := c.GetAccountPositionLimit(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return PositionLimitRules(limit), nil
+}
+
+// positionQuantity returns the signed quantity held of the symbol, negative when short.
+func positionQuantity(positions []AccountPosition, symbol string) decimal.Decimal {
+	for _, position := range positions {
+		if position.Symbol == symbol {
+			return decimal.NewFromInt(int64(position.Quantity)).Abs().Mul(position.Sign())
+		}
+	}
+
+	return decimal.Zero
+}
+
+// orderUnits returns the number of units of the order, the greatest common
+// divisor of its leg quantities.
+func orderUnits(order NewOrder) decimal.Decimal {
+	units := legsGCD(order.Legs)
+	if units == 0 {
+		return decimal.NewFromInt(1)
+	}
+
+	return decimal.NewFromInt(int64(units))
+}
+
+// orderMultiplier returns the largest multiplier of the order's legs.
+func orderMultiplier(order NewOrder, multipliers map[InstrumentType]decimal.Decimal) decimal.Decimal {
+	multiplier := decimal.NewFromInt(1)
+	for _, leg := range order.Legs {
+		m, ok := multipliers[leg.InstrumentType]
+		if !ok && isOption(leg.InstrumentType) {
+			m = decimal.NewFromInt(defaultSharesPerContract)
+		}
+		multiplier = decimal.Max(multiplier, m)
+	}
+
+	return multiplier
+}
+
+// orderUnderlying returns the underlying of the order's first leg.
+func orderUnderlying(order NewOrder) string {
+	if len(order.Legs) == 0 {
+		return ""
+	}
+
+	leg := order.Legs[0]
+	switch leg.InstrumentType {
+	case EquityOptionIT:
+		if occ, err := NewOCCFromString(leg.Symbol); err == nil {
+			return occ.Symbol
+		}
+	case FutureOptionIT:
+		if fos, err := NewFOSFromString(leg.Symbol); err == nil {
+			return fos.FutureContractCode
+		}
+	}
+
+	return leg.Symbol
+}
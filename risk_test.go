@@ -0,0 +1,211 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+const riskPositionsResp = `{"data":{"items":[
+	{"symbol":"AAPL","instrument-type":"Equity","quantity":100,"quantity-direction":"Long","realized-today":"150.0","realized-today-effect":"Debit"},
+	{"symbol":"AAPL  230616C00185000","instrument-type":"Equity Option","quantity":3,"quantity-direction":"Short","realized-today":"50.0","realized-today-effect":"Credit"}
+]}}`
+
+const riskLiveOrdersResp = `{"data":{"items":[
+	{"id":1,"underlying-symbol":"AAPL","status":"Live"},
+	{"id":2,"underlying-symbol":"AAPL","status":"Filled"},
+	{"id":3,"underlying-symbol":"SPY","status":"Live"}
+]}}`
+
+// riskServer fakes the account endpoints rules load and counts submissions.
+func riskServer(t *testing.T) *int {
+	t.Helper()
+
+	submitted := 0
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, "true", request.URL.Query().Get("include-closed-positions"))
+		fmt.Fprint(writer, riskPositionsResp)
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders/live", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, riskLiveOrdersResp)
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders/dry-run", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"buying-power-effect":{
+			"change-in-buying-power":"2500.0","change-in-buying-power-effect":"Debit",
+			"new-buying-power":"7500.0","new-buying-power-effect":"Credit"}}}`)
+	})
+	mux.HandleFunc("/accounts/5YZ55555/orders", func(writer http.ResponseWriter, request *http.Request) {
+		submitted++
+		fmt.Fprint(writer, `{"data":{"order":{"id":9,"status":"Received"}}}`)
+	})
+
+	return &submitted
+}
+
+func riskOrder(symbol string, instrumentType InstrumentType, quantity int64, action OrderAction) NewOrder {
+	return NewOrder{
+		TimeInForce: Day,
+		OrderType:   Limit,
+		Price:       decimal.RequireFromString("1.50"),
+		PriceEffect: Debit,
+		Legs: []NewOrderLeg{
+			{InstrumentType: instrumentType, Symbol: symbol, Quantity: decimal.NewFromInt(quantity), Action: action},
+		},
+	}
+}
+
+func TestRiskEngineKillSwitch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	submitted := riskServer(t)
+
+	engine := client.NewRiskEngine()
+	engine.Kill()
+	require.True(t, engine.Killed())
+
+	_, _, _, err := engine.SubmitOrder("5YZ55555", riskOrder("AAPL  230616C00190000", EquityOptionIT, 1, BTO))
+	require.ErrorIs(t, err, ErrRiskRejected)
+	require.ErrorIs(t, err, ErrKillSwitch)
+
+	// adding to the long equity position opens
+	_, _, _, err = engine.SubmitOrder("5YZ55555", riskOrder("AAPL", EquityIT, 10, Buy))
+	require.ErrorIs(t, err, ErrKillSwitch)
+
+	// selling more than the long position flips it short
+	_, _, _, err = engine.SubmitOrder("5YZ55555", riskOrder("AAPL", EquityIT, 150, Sell))
+	require.ErrorIs(t, err, ErrKillSwitch)
+	require.Equal(t, 0, *submitted)
+
+	resp, orderErr, _, err := engine.SubmitOrder("5YZ55555", riskOrder("AAPL", EquityIT, 100, Sell))
+	require.NoError(t, err)
+	require.Nil(t, orderErr)
+	require.Equal(t, 9, resp.Order.ID)
+
+	_, _, _, err = engine.SubmitOrder("5YZ55555", riskOrder("AAPL  230616C00185000", EquityOptionIT, 3, BTC))
+	require.NoError(t, err)
+	require.Equal(t, 2, *submitted)
+
+	engine.Resume()
+	require.False(t, engine.Killed())
+
+	_, _, _, err = engine.SubmitOrder("5YZ55555", riskOrder("AAPL", EquityIT, 10, Buy))
+	require.NoError(t, err)
+	require.Equal(t, 3, *submitted)
+}
+
+func TestRiskEnginePositionLimitRules(t *testing.T) {
+	setup()
+	defer teardown()
+
+	submitted := riskServer(t)
+
+	mux.HandleFunc("/accounts/5YZ55555/position-limit", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `{"data":{"equity-order-size":500,"equity-option-order-size":5,
+			"equity-position-size":150,"equity-option-position-size":4,"underlying-opening-order-limit":2}}`)
+	})
+
+	rules, err := client.LoadPositionLimitRules("5YZ55555")
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	engine := client.NewRiskEngine(rules...)
+
+	err = engine.Check("5YZ55555", riskOrder("AAPL  230616C00190000", EquityOptionIT, 6, BTO))
+	require.ErrorIs(t, err, ErrRiskRejected)
+	require.ErrorContains(t, err, "AAPL  230616C00190000 order size 6 exceeds the limit of 5")
+	require.ErrorContains(t, err, "AAPL  230616C00190000 position size 6 exceeds the limit of 4")
+
+	err = engine.Check("5YZ55555", riskOrder("AAPL", EquityIT, 60, Buy))
+	require.EqualError(t, err, "order rejected by risk check: AAPL position size 160 exceeds the limit of 150")
+
+	// reducing a position beyond the limit is allowed
+	require.NoError(t, engine.Check("5YZ55555", riskOrder("AAPL", EquityIT, 50, Sell)))
+
+	require.NoError(t, engine.Check("5YZ55555", riskOrder("AAPL", EquityIT, 50, Buy)))
+
+	engine.Add(MaxOpenOrdersPerUnderlying(1))
+	_, _, _, err = engine.SubmitOrder("5YZ55555", riskOrder("AAPL", EquityIT, 50, Buy))
+	require.EqualError(t, err, "order rejected by risk check: 1 working orders in AAPL reaches the limit of 1")
+
+	_, _, _, err = engine.SubmitOrder("5YZ55555", riskOrder("SPY   230616C00420000", EquityOptionIT, 1, BTO))
+	require.EqualError(t, err, "order rejected by risk check: 1 working orders in SPY reaches the limit of 1")
+	require.Equal(t, 0, *submitted)
+}
+
+func TestRiskEngineDailyLoss(t *testing.T) {
+	setup()
+	defer teardown()
+
+	riskServer(t)
+
+	// 150 realized loss less 50 realized gain
+	engine := client.NewRiskEngine(MaxDailyLoss(decimal.NewFromInt(100)))
+
+	err := engine.Check("5YZ55555", riskOrder("AAPL", EquityIT, 10, Buy))
+	require.EqualError(t, err, "order rejected by risk check: realized loss today of 100 reaches the limit of 100")
+
+	require.NoError(t, engine.Check("5YZ55555", riskOrder("AAPL", EquityIT, 10, Sell)))
+
+	engine = client.NewRiskEngine(MaxDailyLoss(decimal.NewFromInt(101)))
+	require.NoError(t, engine.Check("5YZ55555", riskOrder("AAPL", EquityIT, 10, Buy)))
+}
+
+func TestRiskEngineNotionalAndBuyingPower(t *testing.T) {
+	setup()
+	defer teardown()
+
+	riskServer(t)
+
+	engine := client.NewRiskEngine(MaxNotional(decimal.NewFromInt(1000), nil))
+
+	// 1.50 for 6 contracts of 100 shares
+	require.NoError(t, engine.Check("5YZ55555", riskOrder("AAPL  230616C00190000", EquityOptionIT, 6, BTO)))
+
+	err := engine.Check("5YZ55555", riskOrder("AAPL  230616C00190000", EquityOptionIT, 7, BTO))
+	require.EqualError(t, err, "order rejected by risk check: order notional 1050 exceeds the limit of 1000")
+
+	notional := riskOrder("AAPL", EquityIT, 0, Buy)
+	notional.OrderType = NotionalMarket
+	notional.Price = decimal.Zero
+	notional.Value = decimal.NewFromInt(1200)
+	err = engine.Check("5YZ55555", notional)
+	require.EqualError(t, err, "order rejected by risk check: order notional 1200 exceeds the limit of 1000")
+
+	// market orders use the change in buying power of their dry run
+	market := riskOrder("AAPL", EquityIT, 20, Buy)
+	market.OrderType = Market
+	market.Price = decimal.Zero
+	engine = client.NewRiskEngine(MaxNotional(decimal.NewFromInt(2000), nil))
+	err = engine.Check("5YZ55555", market)
+	require.EqualError(t, err, "order rejected by risk check: order notional 2500 exceeds the limit of 2000")
+
+	engine = client.NewRiskEngine(MinBuyingPower(decimal.NewFromInt(5000)))
+	require.NoError(t, engine.Check("5YZ55555", market))
+
+	engine.Add(MinBuyingPower(decimal.NewFromInt(8000)))
+	err = engine.Check("5YZ55555", market)
+	require.EqualError(t, err, "order rejected by risk check: buying power after the order of 7500 is below the minimum of 8000")
+}
+
+func TestRiskEngineRuleError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/5YZ55555/positions", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	engine := client.NewRiskEngine(MaxPositionSize(map[InstrumentType]decimal.Decimal{EquityIT: decimal.NewFromInt(10)}))
+	err := engine.Check("5YZ55555", riskOrder("AAPL", EquityIT, 1, Buy))
+	require.ErrorIs(t, err, ErrRiskRejected)
+
+	var tastyErr *Error
+	require.ErrorAs(t, err, &tastyErr)
+	require.Equal(t, 401, tastyErr.StatusCode)
+}
@@ -0,0 +1,644 @@
+package tasty
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// Shares delivered per equity option contract when the chain doesn't say.
+const defaultSharesPerContract = 100
+
+var (
+	// ErrExpirationNotFound is returned when an ExpirationSelector matches no expiration.
+	ErrExpirationNotFound = errors.New("expiration not found")
+	// ErrStrikeNotFound is returned when a StrikeSelector matches no strike.
+	ErrStrikeNotFound = errors.New("strike not found")
+)
+
+// StrategyChain is an option chain flattened for building strategies. Use
+// LoadEquityStrategyChain or LoadFuturesStrategyChain so symbols are never
+// typed by hand.
+type StrategyChain struct {
+	Underlying string
+	// Equity Option or Future Option
+	InstrumentType InstrumentType
+	// Shares delivered per contract, used for the stock leg of covered calls
+	SharesPerContract int
+	Expirations       []Expiration
+}
+
+// StrategyLeg is a resolved leg of a Strategy.
+type StrategyLeg struct {
+	InstrumentType InstrumentType
+	Symbol         string
+	StreamerSymbol string
+	// Empty for the stock leg of a covered call
+	OptionType     OptionType
+	Strike         decimal.Decimal
+	ExpirationDate string
+	Action         OrderAction
+	// Quantity of the leg per unit of the strategy i.e. 2 for the body of a butterfly
+	Ratio int
+}
+
+// Strategy is a multi-leg option position with its legs ordered by
+// expiration and strike.
+type Strategy struct {
+	Type       StrategyType
+	Underlying string
+	Legs       []StrategyLeg
+	// Whether opening the strategy pays (Debit) or receives (Credit) premium
+	PriceEffect PriceEffect
+	// Widest distance between the strikes of the strategy
+	Width decimal.Decimal
+}
+
+// ExpirationSelector picks an expiration from a chain.
+type ExpirationSelector func(expirations []Expiration) (Expiration, bool)
+
+// StrikeSelector picks a strike of the option type from an expiration.
+type StrikeSelector func(expiration Expiration, optionType OptionType) (Strike, bool)
+
+// LoadEquityStrategyChain loads the nested equity option chain of the underlying.
+// The standard chain is used when the underlying has adjusted chains.
+func (c *Client) LoadEquityStrategyChain(underlying string) (StrategyChain, error) {
+	chains, _, err := c.GetNestedEquityOptionChains(underlying)
+	if err != nil {
+		return StrategyChain{}, err
+	}
+
+	chain, ok := standardChain(chains)
+	if !ok {
+		return StrategyChain{}, fmt.Errorf("no option chain for %s", underlying)
+	}
+
+	return StrategyChain{
+		Underlying:        chain.UnderlyingSymbol,
+		InstrumentType:    EquityOptionIT,
+		SharesPerContract: chain.SharesPerContract,
+		Expirations:       chain.Expirations,
+	}, nil
+}
+
+// standardChain returns the standard chain of an underlying's chains,
+// falling back to the first chain when none is standard.
+func standardChain(chains []NestedOptionChains) (NestedOptionChains, bool) {
+	if len(chains) == 0 {
+		return NestedOptionChains{}, false
+	}
+
+	for _, chain := range chains {
+		if chain.OptionChainType == "Standard" {
+			return chain, true
+		}
+	}
+
+	return chains[0], true
+}
+
+// LoadFuturesStrategyChain loads the nested futures option chains of the
+// product code i.e. ES, merging every option root ordered by days to expiration.
+func (c *Client) LoadFuturesStrategyChain(productCode string) (StrategyChain, error) {
+	chains, _, err := c.GetNestedFuturesOptionChains(productCode)
+	if err != nil {
+		return StrategyChain{}, err
+	}
+
+	if len(chains.OptionChains) == 0 {
+		return StrategyChain{}, fmt.Errorf("no option chain for %s", productCode)
+	}
+
+	sc := StrategyChain{
+		Underlying:     chains.OptionChains[0].UnderlyingSymbol,
+		InstrumentType: FutureOptionIT,
+	}
+
+	for _, chain := range chains.OptionChains {
+		for _, exp := range chain.Expirations {
+			sc.Expirations = append(sc.Expirations, Expiration{
+				ExpirationType:   exp.ExpirationType,
+				ExpirationDate:   exp.ExpirationDate,
+				DaysToExpiration: exp.DaysToExpiration,
+				SettlementType:   exp.SettlementType,
+				Strikes:          exp.Strikes,
+			})
+		}
+	}
+
+	sort.SliceStable(sc.Expirations, func(i, j int) bool {
+		return sc.Expirations[i].DaysToExpiration < sc.Expirations[j].DaysToExpiration
+	})
+
+	return sc, nil
+}
+
+// ClosestDTE selects the expiration closest to the days to expiration.
+// Ties go to the earlier expiration.
+func ClosestDTE(dte int) ExpirationSelector {
+	return func(expirations []Expiration) (Expiration, bool) {
+		var best Expiration
+		found := false
+
+		for _, exp := range expirations {
+			diff := absInt(exp.DaysToExpiration - dte)
+			bestDiff := absInt(best.DaysToExpiration - dte)
+			if !found || diff < bestDiff || (diff == bestDiff && exp.DaysToExpiration < best.DaysToExpiration) {
+				best = exp
+				found = true
+			}
+		}
+
+		return best, found
+	}
+}
+
+// ExpirationOn selects the expiration on the date formatted as 2006-01-02.
+func ExpirationOn(date string) ExpirationSelector {
+	return func(expirations []Expiration) (Expiration, bool) {
+		for _, exp := range expirations {
+			if exp.ExpirationDate == date {
+				return exp, true
+			}
+		}
+
+		return Expiration{}, false
+	}
+}
+
+// AtStrike selects the strike at exactly the price.
+func AtStrike(price decimal.Decimal) StrikeSelector {
+	return func(exp Expiration, _ OptionType) (Strike, bool) {
+		for _, strike := range exp.Strikes {
+			if strike.StrikePrice.Equal(price) {
+				return strike, true
+			}
+		}
+
+		return Strike{}, false
+	}
+}
+
+// NearestStrike selects the strike closest to the price. Ties go to the lower strike.
+func NearestStrike(price decimal.Decimal) StrikeSelector {
+	return func(exp Expiration, _ OptionType) (Strike, bool) {
+		var best Strike
+		found := false
+
+		for _, strike := range sortedStrikes(exp) {
+			if !found || strike.StrikePrice.Sub(price).Abs().LessThan(best.StrikePrice.Sub(price).Abs()) {
+				best = strike
+				found = true
+			}
+		}
+
+		return best, found
+	}
+}
+
+// OffsetStrike selects the strike offset strikes away from the strike picked
+// by base, higher strikes for a positive offset and lower for a negative one
+// i.e. the long put of a 2 strike wide put spread is OffsetStrike(short, -2).
+func OffsetStrike(base StrikeSelector, offset int) StrikeSelector {
+	return func(exp Expiration, optionType OptionType) (Strike, bool) {
+		from, ok := base(exp, optionType)
+		if !ok {
+			return Strike{}, false
+		}
+
+		strikes := sortedStrikes(exp)
+		for i, strike := range strikes {
+			if !strike.StrikePrice.Equal(from.StrikePrice) {
+				continue
+			}
+			if i+offset < 0 || i+offset >= len(strikes) {
+				return Strike{}, false
+			}
+			return strikes[i+offset], true
+		}
+
+		return Strike{}, false
+	}
+}
+
+// ByDelta selects the strike whose delta in the live chain is closest to the
+// delta, compared by absolute value so put deltas may be given as positive
+// numbers. Options without greeks are skipped.
+func ByDelta(chain *LiveChain, delta decimal.Decimal) StrikeSelector {
+	return func(exp Expiration, optionType OptionType) (Strike, bool) {
+		var best LiveChainRow
+		found := false
+
+		for _, row := range chain.Rows() {
+			if row.ExpirationDate != exp.ExpirationDate || row.OptionType != optionType || row.Delta.IsZero() {
+				continue
+			}
+
+			diff := row.Delta.Abs().Sub(delta.Abs()).Abs()
+			if !found || diff.LessThan(best.Delta.Abs().Sub(delta.Abs()).Abs()) {
+				best = row
+				found = true
+			}
+		}
+
+		if !found {
+			return Strike{}, false
+		}
+
+		return AtStrike(best.Strike)(exp, optionType)
+	}
+}
+
+// Vertical is a spread buying the long strike and selling the short strike of
+// the same expiration and option type. It is a credit spread when the short
+// strike is closer to the money.
+func (sc StrategyChain) Vertical(expiration ExpirationSelector, optionType OptionType, long, short StrikeSelector) (Strategy, error) {
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	longLeg, err := sc.option(exp, optionType, long, BTO, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	shortLeg, err := sc.option(exp, optionType, short, STO, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	if longLeg.Strike.Equal(shortLeg.Strike) {
+		return Strategy{}, fmt.Errorf("vertical strikes must differ: %s", longLeg.Strike)
+	}
+
+	effect := Debit
+	if (optionType == Put) == shortLeg.Strike.GreaterThan(longLeg.Strike) {
+		effect = Credit
+	}
+
+	return sc.strategy(Vertical, effect, longLeg.Strike.Sub(shortLeg.Strike).Abs(), longLeg, shortLeg), nil
+}
+
+// Strangle sells (Short) or buys (Long) an out of the money put and call of the same expiration.
+func (sc StrategyChain) Strangle(expiration ExpirationSelector, put, call StrikeSelector, direction Direction) (Strategy, error) {
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	action, effect := directionAction(direction)
+
+	putLeg, err := sc.option(exp, Put, put, action, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	callLeg, err := sc.option(exp, Call, call, action, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	if !putLeg.Strike.LessThan(callLeg.Strike) {
+		return Strategy{}, fmt.Errorf("strangle put strike %s must be below call strike %s", putLeg.Strike, callLeg.Strike)
+	}
+
+	return sc.strategy(Strangle, effect, callLeg.Strike.Sub(putLeg.Strike), putLeg, callLeg), nil
+}
+
+// Straddle sells (Short) or buys (Long) the put and call of the same strike and expiration.
+func (sc StrategyChain) Straddle(expiration ExpirationSelector, strike StrikeSelector, direction Direction) (Strategy, error) {
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	action, effect := directionAction(direction)
+
+	putLeg, err := sc.option(exp, Put, strike, action, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	callLeg, err := sc.option(exp, Call, strike, action, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	return sc.strategy(Straddle, effect, decimal.Zero, putLeg, callLeg), nil
+}
+
+// IronCondor sells a put spread and a call spread of the same expiration for a credit.
+// The short strikes may be equal for an iron butterfly.
+func (sc StrategyChain) IronCondor(expiration ExpirationSelector, longPut, shortPut, shortCall, longCall StrikeSelector) (Strategy, error) {
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	legs, err := sc.options(exp,
+		optionSpec{Put, longPut, BTO, 1},
+		optionSpec{Put, shortPut, STO, 1},
+		optionSpec{Call, shortCall, STO, 1},
+		optionSpec{Call, longCall, BTO, 1})
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	if !legs[0].Strike.LessThan(legs[1].Strike) || legs[1].Strike.GreaterThan(legs[2].Strike) ||
+		!legs[2].Strike.LessThan(legs[3].Strike) {
+		return Strategy{}, errors.New("iron condor strikes must be ordered long put < short put <= short call < long call")
+	}
+
+	width := decimal.Max(legs[1].Strike.Sub(legs[0].Strike), legs[3].Strike.Sub(legs[2].Strike))
+
+	return sc.strategy(IronCondor, Credit, width, legs...), nil
+}
+
+// Butterfly buys the lower and upper strikes and sells two of the middle
+// strike of the same expiration and option type for a debit.
+func (sc StrategyChain) Butterfly(expiration ExpirationSelector, optionType OptionType, lower, middle, upper StrikeSelector) (Strategy, error) {
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	legs, err := sc.options(exp,
+		optionSpec{optionType, lower, BTO, 1},
+		optionSpec{optionType, middle, STO, 2},
+		optionSpec{optionType, upper, BTO, 1})
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	if !legs[0].Strike.LessThan(legs[1].Strike) || !legs[1].Strike.LessThan(legs[2].Strike) {
+		return Strategy{}, errors.New("butterfly strikes must be ordered lower < middle < upper")
+	}
+
+	width := decimal.Max(legs[1].Strike.Sub(legs[0].Strike), legs[2].Strike.Sub(legs[1].Strike))
+
+	return sc.strategy(Butterfly, Debit, width, legs...), nil
+}
+
+// Calendar sells the near expiration and buys the far expiration at the same
+// strike and option type for a debit.
+func (sc StrategyChain) Calendar(near, far ExpirationSelector, optionType OptionType, strike StrikeSelector) (Strategy, error) {
+	return sc.timeSpread(Calendar, near, far, optionType, strike, strike)
+}
+
+// Diagonal sells the near expiration at the near strike and buys the far
+// expiration at the far strike of the same option type for a debit.
+func (sc StrategyChain) Diagonal(near, far ExpirationSelector, optionType OptionType, nearStrike, farStrike StrikeSelector) (Strategy, error) {
+	return sc.timeSpread(Diagonal, near, far, optionType, nearStrike, farStrike)
+}
+
+// CoveredCall buys the shares delivered by one contract and sells a call against them.
+// Only equity option chains support covered calls.
+func (sc StrategyChain) CoveredCall(expiration ExpirationSelector, call StrikeSelector) (Strategy, error) {
+	if sc.InstrumentType != EquityOptionIT {
+		return Strategy{}, fmt.Errorf("covered calls require an equity option chain, not %s", sc.InstrumentType)
+	}
+
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	callLeg, err := sc.option(exp, Call, call, STO, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	shares := sc.SharesPerContract
+	if shares == 0 {
+		shares = defaultSharesPerContract
+	}
+
+	stock := StrategyLeg{
+		InstrumentType: EquityIT,
+		Symbol:         sc.Underlying,
+		StreamerSymbol: sc.Underlying,
+		Action:         Buy,
+		Ratio:          shares,
+	}
+
+	return sc.strategy(CoveredCall, Debit, decimal.Zero, stock, callLeg), nil
+}
+
+// JadeLizard sells a put and a call spread of the same expiration for a credit.
+func (sc StrategyChain) JadeLizard(expiration ExpirationSelector, shortPut, shortCall, longCall StrikeSelector) (Strategy, error) {
+	exp, err := sc.expiration(expiration)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	legs, err := sc.options(exp,
+		optionSpec{Put, shortPut, STO, 1},
+		optionSpec{Call, shortCall, STO, 1},
+		optionSpec{Call, longCall, BTO, 1})
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	if !legs[0].Strike.LessThan(legs[1].Strike) || !legs[1].Strike.LessThan(legs[2].Strike) {
+		return Strategy{}, errors.New("jade lizard strikes must be ordered short put < short call < long call")
+	}
+
+	return sc.strategy(JadeLizard, Credit, legs[2].Strike.Sub(legs[1].Strike), legs...), nil
+}
+
+// Reverse returns the opposite strategy, buying what was sold and selling
+// what was bought i.e. a long iron condor.
+func (s Strategy) Reverse() Strategy {
+	reversed := s
+	reversed.Legs = make([]StrategyLeg, len(s.Legs))
+
+	for i, leg := range s.Legs {
+		switch leg.Action {
+		case BTO:
+			leg.Action = STO
+		case STO:
+			leg.Action = BTO
+		case Buy:
+			leg.Action = Sell
+		case Sell:
+			leg.Action = Buy
+		}
+		reversed.Legs[i] = leg
+	}
+
+	if s.PriceEffect == Credit {
+		reversed.PriceEffect = Debit
+	} else {
+		reversed.PriceEffect = Credit
+	}
+
+	return reversed
+}
+
+// OrderLegs returns the legs for quantity units of the strategy.
+func (s Strategy) OrderLegs(quantity decimal.Decimal) []NewOrderLeg {
+	legs := make([]NewOrderLeg, 0, len(s.Legs))
+	for _, leg := range s.Legs {
+		legs = append(legs, NewOrderLeg{
+			InstrumentType: leg.InstrumentType,
+			Symbol:         leg.Symbol,
+			Quantity:       quantity.Mul(decimal.NewFromInt(int64(leg.Ratio))),
+			Action:         leg.Action,
+		})
+	}
+
+	return legs
+}
+
+// Builder returns an OrderBuilder with the legs for quantity units of the
+// strategy and its natural price effect.
+func (s Strategy) Builder(quantity decimal.Decimal) *OrderBuilder {
+	b := NewOrderBuilder()
+	for _, leg := range s.OrderLegs(quantity) {
+		b.Leg(leg.InstrumentType, leg.Symbol, leg.Quantity, leg.Action)
+	}
+
+	if s.PriceEffect == Credit {
+		return b.Credit()
+	}
+
+	return b.Debit()
+}
+
+// Order returns a validated Day Limit order for quantity units of the strategy at the price.
+func (s Strategy) Order(quantity, price decimal.Decimal) (NewOrder, error) {
+	return s.Builder(quantity).Limit(price).Build()
+}
+
+type optionSpec struct {
+	optionType OptionType
+	strike     StrikeSelector
+	action     OrderAction
+	ratio      int
+}
+
+func (sc StrategyChain) timeSpread(strategyType StrategyType, near, far ExpirationSelector, optionType OptionType,
+	nearStrike, farStrike StrikeSelector) (Strategy, error) {
+	nearExp, err := sc.expiration(near)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	farExp, err := sc.expiration(far)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	if nearExp.ExpirationDate >= farExp.ExpirationDate {
+		return Strategy{}, fmt.Errorf("%s near expiration %s must be before far expiration %s",
+			strategyType, nearExp.ExpirationDate, farExp.ExpirationDate)
+	}
+
+	nearLeg, err := sc.option(nearExp, optionType, nearStrike, STO, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	farLeg, err := sc.option(farExp, optionType, farStrike, BTO, 1)
+	if err != nil {
+		return Strategy{}, err
+	}
+
+	return sc.strategy(strategyType, Debit, farLeg.Strike.Sub(nearLeg.Strike).Abs(), nearLeg, farLeg), nil
+}
+
+func (sc StrategyChain) expiration(selector ExpirationSelector) (Expiration, error) {
+	exp, ok := selector(sc.Expirations)
+	if !ok {
+		return Expiration{}, fmt.Errorf("%w for %s", ErrExpirationNotFound, sc.Underlying)
+	}
+
+	return exp, nil
+}
+
+func (sc StrategyChain) options(exp Expiration, specs ...optionSpec) ([]StrategyLeg, error) {
+	legs := make([]StrategyLeg, 0, len(specs))
+	for _, spec := range specs {
+		leg, err := sc.option(exp, spec.optionType, spec.strike, spec.action, spec.ratio)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+
+	return legs, nil
+}
+
+func (sc StrategyChain) option(exp Expiration, optionType OptionType, selector StrikeSelector, action OrderAction, ratio int) (StrategyLeg, error) {
+	strike, ok := selector(exp, optionType)
+	if !ok {
+		return StrategyLeg{}, fmt.Errorf("%w: %s %s %s", ErrStrikeNotFound, sc.Underlying, exp.ExpirationDate, optionType)
+	}
+
+	symbol, streamerSymbol := strike.Call, strike.CallStreamerSymbol
+	if optionType == Put {
+		symbol, streamerSymbol = strike.Put, strike.PutStreamerSymbol
+	}
+
+	if symbol == "" {
+		return StrategyLeg{}, fmt.Errorf("%w: %s %s %s%s has no symbol",
+			ErrStrikeNotFound, sc.Underlying, exp.ExpirationDate, strike.StrikePrice, optionType)
+	}
+
+	return StrategyLeg{
+		InstrumentType: sc.InstrumentType,
+		Symbol:         symbol,
+		StreamerSymbol: streamerSymbol,
+		OptionType:     optionType,
+		Strike:         strike.StrikePrice,
+		ExpirationDate: exp.ExpirationDate,
+		Action:         action,
+		Ratio:          ratio,
+	}, nil
+}
+
+// strategy orders the legs with the stock leg first, then by expiration,
+// strike and puts before calls.
+func (sc StrategyChain) strategy(strategyType StrategyType, effect PriceEffect, width decimal.Decimal, legs ...StrategyLeg) Strategy {
+	sort.SliceStable(legs, func(i, j int) bool {
+		a, b := legs[i], legs[j]
+		if (a.OptionType == "") != (b.OptionType == "") {
+			return a.OptionType == ""
+		}
+		if a.ExpirationDate != b.ExpirationDate {
+			return a.ExpirationDate < b.ExpirationDate
+		}
+		if !a.Strike.Equal(b.Strike) {
+			return a.Strike.LessThan(b.Strike)
+		}
+		return a.OptionType == Put && b.OptionType == Call
+	})
+
+	return Strategy{
+		Type:        strategyType,
+		Underlying:  sc.Underlying,
+		Legs:        legs,
+		PriceEffect: effect,
+		Width:       width,
+	}
+}
+
+func directionAction(direction Direction) (OrderAction, PriceEffect) {
+	if direction == Short {
+		return STO, Credit
+	}
+
+	return BTO, Debit
+}
+
+func sortedStrikes(exp Expiration) []Strike {
+	strikes := append([]Strike(nil), exp.Strikes...)
+	sort.Slice(strikes, func(i, j int) bool {
+		return strikes[i].StrikePrice.LessThan(strikes[j].StrikePrice)
+	})
+
+	return strikes
+}
@@ -0,0 +1,297 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func testStrategyExpiration(date string, dte int) Expiration {
+	exp := Expiration{ExpirationDate: date, DaysToExpiration: dte}
+	occDate := date[2:4] + date[5:7] + date[8:10]
+
+	for strike := 400; strike <= 460; strike += 10 {
+		exp.Strikes = append(exp.Strikes, Strike{
+			StrikePrice:        decimal.NewFromInt(int64(strike)),
+			Call:               fmt.Sprintf("SPY   %sC00%d000", occDate, strike),
+			CallStreamerSymbol: fmt.Sprintf(".SPY%sC%d", occDate, strike),
+			Put:                fmt.Sprintf("SPY   %sP00%d000", occDate, strike),
+			PutStreamerSymbol:  fmt.Sprintf(".SPY%sP%d", occDate, strike),
+		})
+	}
+
+	return exp
+}
+
+func testStrategyChain() StrategyChain {
+	return StrategyChain{
+		Underlying:        "SPY",
+		InstrumentType:    EquityOptionIT,
+		SharesPerContract: 100,
+		Expirations: []Expiration{
+			testStrategyExpiration("2023-09-15", 32),
+			testStrategyExpiration("2023-09-29", 46),
+		},
+	}
+}
+
+func strategySymbols(s Strategy) []string {
+	symbols := make([]string, 0, len(s.Legs))
+	for _, leg := range s.Legs {
+		symbols = append(symbols, fmt.Sprintf("%s %s x%d", leg.Action, leg.Symbol, leg.Ratio))
+	}
+
+	return symbols
+}
+
+func strike(price int64) StrikeSelector {
+	return AtStrike(decimal.NewFromInt(price))
+}
+
+func TestStrategyVertical(t *testing.T) {
+	sc := testStrategyChain()
+
+	s, err := sc.Vertical(ClosestDTE(45), Put, strike(420), strike(430))
+	require.NoError(t, err)
+	require.Equal(t, Vertical, s.Type)
+	require.Equal(t, "SPY", s.Underlying)
+	require.Equal(t, Credit, s.PriceEffect)
+	require.Equal(t, "10", s.Width.String())
+	require.Equal(t, []string{
+		"Buy to Open SPY   230929P00420000 x1",
+		"Sell to Open SPY   230929P00430000 x1",
+	}, strategySymbols(s))
+	require.Equal(t, ".SPY230929P420", s.Legs[0].StreamerSymbol)
+
+	s, err = sc.Vertical(ClosestDTE(45), Call, strike(420), strike(430))
+	require.NoError(t, err)
+	require.Equal(t, Debit, s.PriceEffect)
+
+	s, err = sc.Vertical(ClosestDTE(45), Call, OffsetStrike(strike(430), 2), strike(430))
+	require.NoError(t, err)
+	require.Equal(t, Credit, s.PriceEffect)
+	require.Equal(t, "450", s.Legs[1].Strike.String())
+
+	_, err = sc.Vertical(ClosestDTE(45), Call, strike(430), strike(430))
+	require.EqualError(t, err, "vertical strikes must differ: 430")
+
+	_, err = sc.Vertical(ClosestDTE(45), Call, OffsetStrike(strike(460), 1), strike(430))
+	require.ErrorIs(t, err, ErrStrikeNotFound)
+
+	_, err = sc.Vertical(ExpirationOn("2023-10-20"), Call, strike(440), strike(430))
+	require.ErrorIs(t, err, ErrExpirationNotFound)
+}
+
+func TestStrategyStrangleStraddle(t *testing.T) {
+	sc := testStrategyChain()
+
+	s, err := sc.Strangle(ExpirationOn("2023-09-15"), strike(410), NearestStrike(decimal.NewFromInt(448)), Short)
+	require.NoError(t, err)
+	require.Equal(t, Credit, s.PriceEffect)
+	require.Equal(t, "40", s.Width.String())
+	require.Equal(t, []string{
+		"Sell to Open SPY   230915P00410000 x1",
+		"Sell to Open SPY   230915C00450000 x1",
+	}, strategySymbols(s))
+
+	_, err = sc.Strangle(ClosestDTE(30), strike(450), strike(410), Long)
+	require.EqualError(t, err, "strangle put strike 450 must be below call strike 410")
+
+	s, err = sc.Straddle(ClosestDTE(30), strike(430), Long)
+	require.NoError(t, err)
+	require.Equal(t, Debit, s.PriceEffect)
+	require.True(t, s.Width.IsZero())
+	require.Equal(t, []string{
+		"Buy to Open SPY   230915P00430000 x1",
+		"Buy to Open SPY   230915C00430000 x1",
+	}, strategySymbols(s))
+}
+
+func TestStrategyIronCondor(t *testing.T) {
+	sc := testStrategyChain()
+
+	s, err := sc.IronCondor(ClosestDTE(45), strike(400), strike(420), strike(440), strike(450))
+	require.NoError(t, err)
+	require.Equal(t, Credit, s.PriceEffect)
+	require.Equal(t, "20", s.Width.String())
+	require.Equal(t, []string{
+		"Buy to Open SPY   230929P00400000 x1",
+		"Sell to Open SPY   230929P00420000 x1",
+		"Sell to Open SPY   230929C00440000 x1",
+		"Buy to Open SPY   230929C00450000 x1",
+	}, strategySymbols(s))
+
+	// iron butterfly
+	_, err = sc.IronCondor(ClosestDTE(45), strike(420), strike(430), strike(430), strike(440))
+	require.NoError(t, err)
+
+	_, err = sc.IronCondor(ClosestDTE(45), strike(420), strike(440), strike(430), strike(450))
+	require.EqualError(t, err, "iron condor strikes must be ordered long put < short put <= short call < long call")
+
+	reversed := s.Reverse()
+	require.Equal(t, Debit, reversed.PriceEffect)
+	require.Equal(t, BTO, reversed.Legs[1].Action)
+	require.Equal(t, STO, s.Legs[1].Action)
+}
+
+func TestStrategyButterflyJadeLizard(t *testing.T) {
+	sc := testStrategyChain()
+
+	s, err := sc.Butterfly(ClosestDTE(30), Call, strike(420), strike(430), strike(450))
+	require.NoError(t, err)
+	require.Equal(t, Debit, s.PriceEffect)
+	require.Equal(t, "20", s.Width.String())
+	require.Equal(t, []string{
+		"Buy to Open SPY   230915C00420000 x1",
+		"Sell to Open SPY   230915C00430000 x2",
+		"Buy to Open SPY   230915C00450000 x1",
+	}, strategySymbols(s))
+
+	_, err = sc.Butterfly(ClosestDTE(30), Call, strike(420), strike(420), strike(450))
+	require.EqualError(t, err, "butterfly strikes must be ordered lower < middle < upper")
+
+	s, err = sc.JadeLizard(ClosestDTE(30), strike(410), strike(440), strike(450))
+	require.NoError(t, err)
+	require.Equal(t, Credit, s.PriceEffect)
+	require.Equal(t, "10", s.Width.String())
+	require.Equal(t, []string{
+		"Sell to Open SPY   230915P00410000 x1",
+		"Sell to Open SPY   230915C00440000 x1",
+		"Buy to Open SPY   230915C00450000 x1",
+	}, strategySymbols(s))
+
+	_, err = sc.JadeLizard(ClosestDTE(30), strike(440), strike(440), strike(450))
+	require.EqualError(t, err, "jade lizard strikes must be ordered short put < short call < long call")
+}
+
+func TestStrategyCalendarDiagonal(t *testing.T) {
+	sc := testStrategyChain()
+
+	s, err := sc.Calendar(ClosestDTE(30), ClosestDTE(45), Put, strike(430))
+	require.NoError(t, err)
+	require.Equal(t, Debit, s.PriceEffect)
+	require.True(t, s.Width.IsZero())
+	require.Equal(t, []string{
+		"Sell to Open SPY   230915P00430000 x1",
+		"Buy to Open SPY   230929P00430000 x1",
+	}, strategySymbols(s))
+
+	s, err = sc.Diagonal(ClosestDTE(30), ClosestDTE(45), Call, strike(440), strike(430))
+	require.NoError(t, err)
+	require.Equal(t, Diagonal, s.Type)
+	require.Equal(t, "10", s.Width.String())
+
+	_, err = sc.Calendar(ClosestDTE(45), ClosestDTE(30), Put, strike(430))
+	require.EqualError(t, err, "Calendar near expiration 2023-09-29 must be before far expiration 2023-09-15")
+}
+
+func TestStrategyCoveredCall(t *testing.T) {
+	sc := testStrategyChain()
+
+	s, err := sc.CoveredCall(ClosestDTE(30), strike(450))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Buy SPY x100",
+		"Sell to Open SPY   230915C00450000 x1",
+	}, strategySymbols(s))
+
+	order, err := s.Order(decimal.NewFromInt(2), decimal.RequireFromString("441.5"))
+	require.NoError(t, err)
+	require.Equal(t, Debit, order.PriceEffect)
+	require.Equal(t, Limit, order.OrderType)
+	require.Equal(t, []NewOrderLeg{
+		{InstrumentType: EquityIT, Symbol: "SPY", Quantity: decimal.NewFromInt(200), Action: Buy},
+		{InstrumentType: EquityOptionIT, Symbol: "SPY   230915C00450000", Quantity: decimal.NewFromInt(2), Action: STO},
+	}, order.Legs)
+
+	sc.InstrumentType = FutureOptionIT
+	_, err = sc.CoveredCall(ClosestDTE(30), strike(450))
+	require.EqualError(t, err, "covered calls require an equity option chain, not Future Option")
+}
+
+func TestStrategyByDelta(t *testing.T) {
+	sc := testStrategyChain()
+
+	chain, err := NewLiveChain(nil, "SPY", sc.Expirations)
+	require.NoError(t, err)
+	chain.Apply(Greeks{EventSymbol: ".SPY230929P410", Delta: decimal.RequireFromString("-0.12")})
+	chain.Apply(Greeks{EventSymbol: ".SPY230929P420", Delta: decimal.RequireFromString("-0.17")})
+	chain.Apply(Greeks{EventSymbol: ".SPY230929P430", Delta: decimal.RequireFromString("-0.25")})
+	chain.Apply(Greeks{EventSymbol: ".SPY230915P430", Delta: decimal.RequireFromString("-0.16")})
+
+	short := ByDelta(chain, decimal.RequireFromString("0.16"))
+	s, err := sc.Vertical(ClosestDTE(45), Put, OffsetStrike(short, -1), short)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Buy to Open SPY   230929P00410000 x1",
+		"Sell to Open SPY   230929P00420000 x1",
+	}, strategySymbols(s))
+
+	_, err = sc.Vertical(ClosestDTE(45), Call, OffsetStrike(short, -1), short)
+	require.ErrorIs(t, err, ErrStrikeNotFound)
+}
+
+func TestLoadEquityStrategyChain(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/option-chains/AAPL/nested", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, equityOptionChainsNestedResp)
+	})
+
+	sc, err := client.LoadEquityStrategyChain("AAPL")
+	require.NoError(t, err)
+	require.Equal(t, "AAPL", sc.Underlying)
+	require.Equal(t, EquityOptionIT, sc.InstrumentType)
+	require.Equal(t, 100, sc.SharesPerContract)
+
+	s, err := sc.Vertical(ClosestDTE(0), Put, strike(60), strike(65))
+	require.NoError(t, err)
+	require.Equal(t, "AAPL  230616P00060000", s.Legs[0].Symbol)
+}
+
+func TestLoadFuturesStrategyChain(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/futures-option-chains/ES/nested", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, futuresOptionChainsNested)
+	})
+
+	sc, err := client.LoadFuturesStrategyChain("ES")
+	require.NoError(t, err)
+	require.Equal(t, "/ES", sc.Underlying)
+	require.Equal(t, FutureOptionIT, sc.InstrumentType)
+
+	s, err := sc.Straddle(ClosestDTE(47), strike(3990), Short)
+	require.NoError(t, err)
+	require.Equal(t, "./ESU3 EW4N3 230728P3990", s.Legs[0].Symbol)
+
+	order, err := s.Order(decimal.NewFromInt(1), decimal.RequireFromString("120.5"))
+	require.NoError(t, err)
+	require.Equal(t, FutureOptionIT, order.Legs[1].InstrumentType)
+	require.Equal(t, Credit, order.PriceEffect)
+}
+
+func TestLoadStrategyChainError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/option-chains/AAPL/nested", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+	mux.HandleFunc("/futures-option-chains/ES/nested", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.LoadEquityStrategyChain("AAPL")
+	expectedUnauthorized(t, err)
+
+	_, err = client.LoadFuturesStrategyChain("ES")
+	expectedUnauthorized(t, err)
+}
@@ -1,5 +1,11 @@
 package tasty
 
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
 // Response from the API quote streamer request.
 type QuoteStreamerTokenAuthResult struct {
 	// API quote token unique to the customer identified by the session
@@ -8,3 +14,130 @@ type QuoteStreamerTokenAuthResult struct {
 	DXLinkURL string `json:"dxlink-url"`
 	Level     string `json:"level"`
 }
+
+// FeedSubscription identifies a single market event stream for a symbol.
+type FeedSubscription struct {
+	Type MarketEventType `json:"type"`
+	// Streamer symbol i.e. AAPL, .AAPL230818C185, /ES:XCME
+	Symbol string `json:"symbol"`
+}
+
+// Quote is a snapshot of the best bid and offer for a symbol.
+type Quote struct {
+	EventSymbol     string          `json:"eventSymbol"`
+	EventTime       EpochMillis     `json:"eventTime"`
+	Sequence        int             `json:"sequence"`
+	BidTime         EpochMillis     `json:"bidTime"`
+	BidExchangeCode string          `json:"bidExchangeCode"`
+	BidPrice        decimal.Decimal `json:"bidPrice"`
+	BidSize         decimal.Decimal `json:"bidSize"`
+	AskTime         EpochMillis     `json:"askTime"`
+	AskExchangeCode string          `json:"askExchangeCode"`
+	AskPrice        decimal.Decimal `json:"askPrice"`
+	AskSize         decimal.Decimal `json:"askSize"`
+}
+
+// Trade is the last trade snapshot for a symbol.
+type Trade struct {
+	EventSymbol          string          `json:"eventSymbol"`
+	EventTime            EpochMillis     `json:"eventTime"`
+	Time                 EpochMillis     `json:"time"`
+	Sequence             int             `json:"sequence"`
+	ExchangeCode         string          `json:"exchangeCode"`
+	Price                decimal.Decimal `json:"price"`
+	Change               decimal.Decimal `json:"change"`
+	Size                 decimal.Decimal `json:"size"`
+	DayID                int             `json:"dayId"`
+	DayVolume            decimal.Decimal `json:"dayVolume"`
+	DayTurnover          decimal.Decimal `json:"dayTurnover"`
+	TickDirection        string          `json:"tickDirection"`
+	ExtendedTradingHours bool            `json:"extendedTradingHours"`
+}
+
+// TimeAndSale is a single print on the tape.
+type TimeAndSale struct {
+	EventSymbol            string          `json:"eventSymbol"`
+	EventTime              EpochMillis     `json:"eventTime"`
+	EventFlags             int             `json:"eventFlags"`
+	Index                  int64           `json:"index"`
+	Time                   EpochMillis     `json:"time"`
+	Sequence               int             `json:"sequence"`
+	ExchangeCode           string          `json:"exchangeCode"`
+	Price                  decimal.Decimal `json:"price"`
+	Size                   decimal.Decimal `json:"size"`
+	BidPrice               decimal.Decimal `json:"bidPrice"`
+	AskPrice               decimal.Decimal `json:"askPrice"`
+	ExchangeSaleConditions string          `json:"exchangeSaleConditions"`
+	TradeThroughExempt     string          `json:"tradeThroughExempt"`
+	// BUY, SELL or UNDEFINED
+	AggressorSide        string `json:"aggressorSide"`
+	SpreadLeg            bool   `json:"spreadLeg"`
+	ExtendedTradingHours bool   `json:"extendedTradingHours"`
+	ValidTick            bool   `json:"validTick"`
+	// NEW, CORRECTION or CANCEL
+	Type   string `json:"type"`
+	Buyer  string `json:"buyer"`
+	Seller string `json:"seller"`
+}
+
+// Greeks are the option greeks and implied volatility for an option symbol.
+type Greeks struct {
+	EventSymbol string          `json:"eventSymbol"`
+	EventTime   EpochMillis     `json:"eventTime"`
+	EventFlags  int             `json:"eventFlags"`
+	Index       int64           `json:"index"`
+	Time        EpochMillis     `json:"time"`
+	Sequence    int             `json:"sequence"`
+	Price       decimal.Decimal `json:"price"`
+	Volatility  decimal.Decimal `json:"volatility"`
+	Delta       decimal.Decimal `json:"delta"`
+	Gamma       decimal.Decimal `json:"gamma"`
+	Theta       decimal.Decimal `json:"theta"`
+	Rho         decimal.Decimal `json:"rho"`
+	Vega        decimal.Decimal `json:"vega"`
+}
+
+// Summary is the daily summary for a symbol including open interest.
+type Summary struct {
+	EventSymbol           string          `json:"eventSymbol"`
+	EventTime             EpochMillis     `json:"eventTime"`
+	DayID                 int             `json:"dayId"`
+	DayOpenPrice          decimal.Decimal `json:"dayOpenPrice"`
+	DayHighPrice          decimal.Decimal `json:"dayHighPrice"`
+	DayLowPrice           decimal.Decimal `json:"dayLowPrice"`
+	DayClosePrice         decimal.Decimal `json:"dayClosePrice"`
+	DayClosePriceType     string          `json:"dayClosePriceType"`
+	PrevDayID             int             `json:"prevDayId"`
+	PrevDayClosePrice     decimal.Decimal `json:"prevDayClosePrice"`
+	PrevDayClosePriceType string          `json:"prevDayClosePriceType"`
+	PrevDayVolume         decimal.Decimal `json:"prevDayVolume"`
+	OpenInterest          decimal.Decimal `json:"openInterest"`
+}
+
+// AccountEvent is a notification from the account streamer.
+type AccountEvent struct {
+	Type AccountEventType `json:"type"`
+	// Raw payload of the notification, decode it based on Type
+	// i.e. Order for OrderNotification.
+	Data      json.RawMessage `json:"data"`
+	Timestamp EpochMillis     `json:"timestamp"`
+}
+
+// Message sent to the account streamer.
+type AccountStreamerMessage struct {
+	// connect, heartbeat, public-watchlists-subscribe, quote-alerts-subscribe, user-message-subscribe
+	Action    string   `json:"action"`
+	Value     []string `json:"value,omitempty"`
+	AuthToken string   `json:"auth-token"`
+	RequestID int      `json:"request-id"`
+}
+
+// Response from the account streamer to an AccountStreamerMessage.
+type AccountStreamerResponse struct {
+	Status             string   `json:"status"`
+	Action             string   `json:"action"`
+	WebSocketSessionID string   `json:"web-socket-session-id"`
+	Value              []string `json:"value"`
+	RequestID          int      `json:"request-id"`
+	Message            string   `json:"message"`
+}
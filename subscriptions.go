@@ -0,0 +1,340 @@
+package tasty
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultConsumerBuffer = 256
+
+var errConsumerClosed = errors.New("feed consumer is closed")
+
+// ConsumerConfig configures the buffering of a FeedConsumer.
+type ConsumerConfig struct {
+	// Size of the consumer's event buffer. Defaults to 256.
+	Buffer int
+	// What to do when the buffer is full. Defaults to DropNewest.
+	// Backpressure blocks the manager's single dispatch goroutine, holding up
+	// every other consumer until this one reads or the block timeout passes.
+	Policy OverflowPolicy
+	// How long the Backpressure policy waits before dropping an event.
+	// Zero waits until the consumer reads or is closed, stalling the whole
+	// manager behind a consumer that stops reading.
+	BlockTimeout time.Duration
+}
+
+// SubscriptionManager fans a single MarketDataFeed out to multiple consumers.
+// Feed subscriptions are reference counted: a symbol is subscribed on the
+// feed when the first consumer asks for it and removed when the last
+// consumer unsubscribes.
+type SubscriptionManager struct {
+	feed   MarketDataFeed
+	mu     sync.RWMutex
+	routes map[FeedSubscription]map[*FeedConsumer]struct{}
+	all    map[*FeedConsumer]struct{}
+	closed bool
+}
+
+// FeedConsumer is a single consumer of a SubscriptionManager. It implements
+// MarketDataFeed so it can be handed to anything that reads from a feed.
+type FeedConsumer struct {
+	manager *SubscriptionManager
+	config  ConsumerConfig
+	events  chan MarketEvent
+	done    chan struct{}
+	subs    map[FeedSubscription]struct{}
+	mu      sync.Mutex
+	once    sync.Once
+	closed  bool
+	dropped uint64
+}
+
+// NewSubscriptionManager creates a manager for the feed. Call Run to start dispatching events.
+func NewSubscriptionManager(feed MarketDataFeed) *SubscriptionManager {
+	return &SubscriptionManager{
+		feed:   feed,
+		routes: map[FeedSubscription]map[*FeedConsumer]struct{}{},
+		all:    map[*FeedConsumer]struct{}{},
+	}
+}
+
+// NewConsumer registers a new consumer with its own event channel.
+func (m *SubscriptionManager) NewConsumer(config ConsumerConfig) *FeedConsumer {
+	if config.Buffer <= 0 {
+		config.Buffer = defaultConsumerBuffer
+	}
+	if config.Policy == "" {
+		config.Policy = DropNewest
+	}
+
+	c := &FeedConsumer{
+		manager: m,
+		config:  config,
+		events:  make(chan MarketEvent, config.Buffer),
+		done:    make(chan struct{}),
+		subs:    map[FeedSubscription]struct{}{},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		c.close()
+		return c
+	}
+
+	m.all[c] = struct{}{}
+
+	return c
+}
+
+// Run dispatches feed events to the subscribed consumers until the feed's
+// event channel is closed, at which point every consumer is closed.
+func (m *SubscriptionManager) Run() {
+	for event := range m.feed.Events() {
+		m.dispatch(event)
+	}
+
+	m.mu.Lock()
+	m.closed = true
+	consumers := make([]*FeedConsumer, 0, len(m.all))
+	for c := range m.all {
+		consumers = append(consumers, c)
+	}
+	m.all = map[*FeedConsumer]struct{}{}
+	m.routes = map[FeedSubscription]map[*FeedConsumer]struct{}{}
+	m.mu.Unlock()
+
+	for _, c := range consumers {
+		c.close()
+	}
+}
+
+// Subscriptions returns the feed subscriptions and their number of consumers.
+func (m *SubscriptionManager) Subscriptions() map[FeedSubscription]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[FeedSubscription]int, len(m.routes))
+	for sub, consumers := range m.routes {
+		counts[sub] = len(consumers)
+	}
+
+	return counts
+}
+
+func (m *SubscriptionManager) dispatch(event MarketEvent) {
+	key := FeedSubscription{Type: event.EventType(), Symbol: event.Symbol()}
+
+	m.mu.RLock()
+	consumers := make([]*FeedConsumer, 0, len(m.routes[key]))
+	for c := range m.routes[key] {
+		consumers = append(consumers, c)
+	}
+	m.mu.RUnlock()
+
+	for _, c := range consumers {
+		c.deliver(event)
+	}
+}
+
+func (m *SubscriptionManager) subscribe(c *FeedConsumer, subs []FeedSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return errConsumerClosed
+	}
+
+	var added, first []FeedSubscription
+
+	for _, sub := range subs {
+		if _, ok := c.subs[sub]; ok || containsSubscription(added, sub) {
+			continue
+		}
+		added = append(added, sub)
+		if len(m.routes[sub]) == 0 {
+			first = append(first, sub)
+		}
+	}
+
+	if err := m.feed.Subscribe(first...); err != nil {
+		return err
+	}
+
+	for _, sub := range added {
+		if m.routes[sub] == nil {
+			m.routes[sub] = map[*FeedConsumer]struct{}{}
+		}
+		m.routes[sub][c] = struct{}{}
+		c.subs[sub] = struct{}{}
+	}
+
+	return nil
+}
+
+// unsubscribe removes the consumer's subscriptions, keeping them when the
+// feed fails to unsubscribe so that the call can be retried.
+func (m *SubscriptionManager) unsubscribe(c *FeedConsumer, subs []FeedSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.closed {
+		var last []FeedSubscription
+		for _, sub := range subs {
+			_, ok := c.subs[sub]
+			if ok && len(m.routes[sub]) == 1 && !containsSubscription(last, sub) {
+				last = append(last, sub)
+			}
+		}
+
+		if err := m.feed.Unsubscribe(last...); err != nil {
+			return err
+		}
+	}
+
+	m.release(c, subs)
+
+	return nil
+}
+
+// release removes the consumer's subscriptions from the routes.
+func (m *SubscriptionManager) release(c *FeedConsumer, subs []FeedSubscription) {
+	for _, sub := range subs {
+		if _, ok := c.subs[sub]; !ok {
+			continue
+		}
+		delete(c.subs, sub)
+		delete(m.routes[sub], c)
+		if len(m.routes[sub]) == 0 {
+			delete(m.routes, sub)
+		}
+	}
+}
+
+// Subscribe adds subscriptions for this consumer, subscribing the feed
+// for any that no other consumer holds.
+func (c *FeedConsumer) Subscribe(subs ...FeedSubscription) error {
+	if c.isClosed() {
+		return errConsumerClosed
+	}
+
+	return c.manager.subscribe(c, subs)
+}
+
+// Unsubscribe removes subscriptions for this consumer, unsubscribing the
+// feed for any that no other consumer holds. The subscriptions are kept when
+// the feed fails to unsubscribe.
+func (c *FeedConsumer) Unsubscribe(subs ...FeedSubscription) error {
+	return c.manager.unsubscribe(c, subs)
+}
+
+// Events returns the consumer's events. The channel is closed when the
+// consumer or the manager's feed is closed.
+func (c *FeedConsumer) Events() <-chan MarketEvent {
+	return c.events
+}
+
+// Dropped returns the number of events dropped because the consumer fell behind.
+func (c *FeedConsumer) Dropped() uint64 {
+	return atomic.LoadUint64(&c.dropped)
+}
+
+// Close releases all of the consumer's subscriptions and closes its event
+// channel. The subscriptions are released even when the feed fails to
+// unsubscribe, the error is returned and the feed resubscribed by the next
+// consumer subscribing to them.
+func (c *FeedConsumer) Close() error {
+	c.manager.mu.Lock()
+	delete(c.manager.all, c)
+	subs := make([]FeedSubscription, 0, len(c.subs))
+	for sub := range c.subs {
+		subs = append(subs, sub)
+	}
+	c.manager.mu.Unlock()
+
+	err := c.manager.unsubscribe(c, subs)
+	if err != nil {
+		c.manager.mu.Lock()
+		c.manager.release(c, subs)
+		c.manager.mu.Unlock()
+	}
+
+	c.close()
+
+	return err
+}
+
+func (c *FeedConsumer) close() {
+	c.once.Do(func() { close(c.done) })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.events)
+	}
+}
+
+func (c *FeedConsumer) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
+
+func (c *FeedConsumer) deliver(event MarketEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.events <- event:
+		return
+	default:
+	}
+
+	switch c.config.Policy {
+	case DropOldest:
+		select {
+		case <-c.events:
+		default:
+		}
+		select {
+		case c.events <- event:
+		default:
+		}
+		atomic.AddUint64(&c.dropped, 1)
+	case Backpressure:
+		var timeout <-chan time.Time
+		if c.config.BlockTimeout > 0 {
+			timer := time.NewTimer(c.config.BlockTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case c.events <- event:
+		case <-timeout:
+			atomic.AddUint64(&c.dropped, 1)
+		case <-c.done:
+		}
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+// containsSubscription returns whether or not the subscription exists in the slice.
+func containsSubscription(s []FeedSubscription, e FeedSubscription) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
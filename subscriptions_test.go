@@ -0,0 +1,138 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionManagerRefCounting(t *testing.T) {
+	feed := newFakeFeed()
+	manager := NewSubscriptionManager(feed)
+
+	spy := FeedSubscription{Type: QuoteEvent, Symbol: "SPY"}
+	qqq := FeedSubscription{Type: QuoteEvent, Symbol: "QQQ"}
+
+	pnl := manager.NewConsumer(ConsumerConfig{})
+	risk := manager.NewConsumer(ConsumerConfig{})
+
+	require.NoError(t, pnl.Subscribe(spy, spy))
+	require.NoError(t, risk.Subscribe(spy, qqq))
+	require.Equal(t, []FeedSubscription{spy, qqq}, feed.subscribed)
+	require.Equal(t, map[FeedSubscription]int{spy: 2, qqq: 1}, manager.Subscriptions())
+
+	// resubscribing is a no-op
+	require.NoError(t, pnl.Subscribe(spy))
+	require.Len(t, feed.subscribed, 2)
+
+	require.NoError(t, pnl.Unsubscribe(spy))
+	require.Empty(t, feed.unsubscribed)
+
+	require.NoError(t, risk.Close())
+	require.ElementsMatch(t, []FeedSubscription{spy, qqq}, feed.unsubscribed)
+	require.Empty(t, manager.Subscriptions())
+
+	_, open := <-risk.Events()
+	require.False(t, open)
+	require.ErrorIs(t, risk.Subscribe(spy), errConsumerClosed)
+}
+
+func TestSubscriptionManagerUnsubscribeError(t *testing.T) {
+	feed := newFakeFeed()
+	manager := NewSubscriptionManager(feed)
+
+	spy := FeedSubscription{Type: QuoteEvent, Symbol: "SPY"}
+	pnl := manager.NewConsumer(ConsumerConfig{})
+	require.NoError(t, pnl.Subscribe(spy))
+
+	// the subscription is kept so the unsubscribe can be retried
+	feed.unsubscribeErr = errors.New("connection closed")
+	require.EqualError(t, pnl.Unsubscribe(spy), "connection closed")
+	require.Equal(t, map[FeedSubscription]int{spy: 1}, manager.Subscriptions())
+
+	feed.unsubscribeErr = nil
+	require.NoError(t, pnl.Unsubscribe(spy))
+	require.Equal(t, []FeedSubscription{spy}, feed.unsubscribed)
+	require.Empty(t, manager.Subscriptions())
+
+	// closing releases the subscriptions regardless
+	require.NoError(t, pnl.Subscribe(spy))
+	feed.unsubscribeErr = errors.New("connection closed")
+	require.EqualError(t, pnl.Close(), "connection closed")
+	require.Empty(t, manager.Subscriptions())
+}
+
+func TestSubscriptionManagerDispatch(t *testing.T) {
+	feed := newFakeFeed()
+	manager := NewSubscriptionManager(feed)
+
+	pnl := manager.NewConsumer(ConsumerConfig{})
+	risk := manager.NewConsumer(ConsumerConfig{})
+
+	require.NoError(t, pnl.Subscribe(FeedSubscription{Type: QuoteEvent, Symbol: "SPY"}))
+	require.NoError(t, risk.Subscribe(
+		FeedSubscription{Type: QuoteEvent, Symbol: "SPY"},
+		FeedSubscription{Type: GreeksEvent, Symbol: "SPY"},
+	))
+
+	feed.events <- Quote{EventSymbol: "SPY"}
+	feed.events <- Greeks{EventSymbol: "SPY"}
+	feed.events <- Quote{EventSymbol: "QQQ"}
+	close(feed.events)
+
+	manager.Run()
+
+	var pnlEvents, riskEvents []MarketEvent
+	for e := range pnl.Events() {
+		pnlEvents = append(pnlEvents, e)
+	}
+	for e := range risk.Events() {
+		riskEvents = append(riskEvents, e)
+	}
+
+	require.Equal(t, []MarketEvent{Quote{EventSymbol: "SPY"}}, pnlEvents)
+	require.Equal(t, []MarketEvent{Quote{EventSymbol: "SPY"}, Greeks{EventSymbol: "SPY"}}, riskEvents)
+
+	// consumers created after the feed closes are closed immediately
+	late := manager.NewConsumer(ConsumerConfig{})
+	_, open := <-late.Events()
+	require.False(t, open)
+	require.ErrorIs(t, late.Subscribe(FeedSubscription{Type: QuoteEvent, Symbol: "SPY"}), errConsumerClosed)
+}
+
+func TestFeedConsumerOverflow(t *testing.T) {
+	manager := NewSubscriptionManager(newFakeFeed())
+
+	newest := manager.NewConsumer(ConsumerConfig{Buffer: 1})
+	newest.deliver(Quote{EventSymbol: "1"})
+	newest.deliver(Quote{EventSymbol: "2"})
+	require.Equal(t, uint64(1), newest.Dropped())
+	require.Equal(t, "1", (<-newest.Events()).Symbol())
+
+	oldest := manager.NewConsumer(ConsumerConfig{Buffer: 1, Policy: DropOldest})
+	oldest.deliver(Quote{EventSymbol: "1"})
+	oldest.deliver(Quote{EventSymbol: "2"})
+	require.Equal(t, uint64(1), oldest.Dropped())
+	require.Equal(t, "2", (<-oldest.Events()).Symbol())
+
+	blocking := manager.NewConsumer(ConsumerConfig{Buffer: 1, Policy: Backpressure, BlockTimeout: 10 * time.Millisecond})
+	blocking.deliver(Quote{EventSymbol: "1"})
+	blocking.deliver(Quote{EventSymbol: "2"})
+	require.Equal(t, uint64(1), blocking.Dropped())
+
+	waiting := manager.NewConsumer(ConsumerConfig{Buffer: 1, Policy: Backpressure})
+	waiting.deliver(Quote{EventSymbol: "1"})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-waiting.Events()
+	}()
+	waiting.deliver(Quote{EventSymbol: "2"})
+	require.Zero(t, waiting.Dropped())
+	require.Equal(t, "2", (<-waiting.Events()).Symbol())
+
+	require.NoError(t, waiting.Close())
+	waiting.deliver(Quote{EventSymbol: "3"})
+	require.Zero(t, waiting.Dropped())
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -34,6 +35,8 @@ type Client struct {
 	baseHost   string
 	websocket  string
 	Session    Session
+	// accountStreamer is used to watch orders when connected.
+	accountStreamer atomic.Pointer[AccountStreamer]
 }
 
 // NewClient creates a new Tasty Client.
@@ -67,7 +70,7 @@ func NewCertClient(httpClient *http.Client) *Client {
 }
 
 // Getter for the tastytrade account streaming websocket url.
-func (c Client) GetWebsocketURL() string {
+func (c *Client) GetWebsocketURL() string {
 	return c.websocket
 }
 
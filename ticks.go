@@ -0,0 +1,340 @@
+package tasty
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrOffTick is returned when a price isn't a multiple of its tick size.
+var ErrOffTick = errors.New("price is off tick")
+
+// Ticks is the tick schedule used to price an instrument. Each tick size
+// applies to prices below its threshold with the last one, without a
+// threshold, applying to every price above i.e. penny pilot options trade in
+// pennies below $3 and in nickels above.
+type Ticks struct {
+	Schedule []TickSize
+	// Symbol the ticks price. Tick sizes with a symbol only apply to that
+	// symbol, generic tick sizes apply when none match.
+	Symbol string
+	// Denominators of futures quoted in fractions i.e. 32 and 2 for half
+	// 32nds. Ticks are snapped to exact multiples of the fraction.
+	MainFraction decimal.Decimal
+	SubFraction  decimal.Decimal
+}
+
+// Ticks returns the tick schedule of the equity.
+func (e Equity) Ticks() Ticks {
+	return Ticks{Schedule: e.TickSizes, Symbol: e.Symbol}
+}
+
+// OptionTicks returns the tick schedule of the equity's options.
+func (e Equity) OptionTicks() Ticks {
+	return Ticks{Schedule: e.OptionTickSizes, Symbol: e.Symbol}
+}
+
+// Ticks returns the tick schedule of the future, its tick size when it has
+// no schedule.
+func (f Future) Ticks() Ticks {
+	schedule := f.TickSizes
+	if len(schedule) == 0 && f.TickSize.IsPositive() {
+		schedule = []TickSize{{Value: f.TickSize}}
+	}
+
+	return Ticks{Schedule: schedule, Symbol: f.Symbol, MainFraction: f.MainFraction, SubFraction: f.SubFraction}
+}
+
+// SpreadTicks returns the tick schedule of spreads of the future, its
+// outright ticks when it has no spread schedule. Spread tick sizes are keyed
+// by the symbol of the other leg, set Symbol to price a given spread.
+func (f Future) SpreadTicks() Ticks {
+	ticks := f.Ticks()
+	if len(f.SpreadTickSizes) > 0 {
+		ticks.Schedule = f.SpreadTickSizes
+		ticks.Symbol = ""
+	}
+
+	return ticks
+}
+
+// Ticks returns the tick schedule of the expiration's future options.
+func (fe FuturesExpiration) Ticks() Ticks {
+	return Ticks{Schedule: fe.TickSizes}
+}
+
+// Ticks returns the tick schedule of the chain's options.
+func (noc NestedOptionChains) Ticks() Ticks {
+	return Ticks{Schedule: noc.TickSizes}
+}
+
+// Ticks returns the tick size of the cryptocurrency.
+func (ci CryptocurrencyInfo) Ticks() Ticks {
+	return Ticks{Schedule: []TickSize{{Value: ci.TickSize}}, Symbol: ci.Symbol}
+}
+
+// pennyTicks prices in pennies, the increment of multi-leg equity option
+// orders.
+func pennyTicks() Ticks {
+	return Ticks{Schedule: []TickSize{{Value: decimal.New(1, -2)}}}
+}
+
+// Tick returns the tick size that applies to the price.
+func (t Ticks) Tick(price decimal.Decimal) (decimal.Decimal, error) {
+	for _, ts := range t.schedule() {
+		if !ts.Value.IsPositive() {
+			continue
+		}
+		if ts.Threshold.IsPositive() && !price.Abs().LessThan(ts.Threshold) {
+			continue
+		}
+
+		return t.fractional(ts.Value), nil
+	}
+
+	return decimal.Zero, fmt.Errorf("no tick size for price %s", price)
+}
+
+// Round rounds the price onto the schedule. Rounding across a threshold onto
+// a coarser tick is rounded again with that tick.
+func (t Ticks) Round(price decimal.Decimal, rounding Rounding) (decimal.Decimal, error) {
+	tick, err := t.Tick(price)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	rounded, err := roundToTick(price, tick, rounding)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	if next, err := t.Tick(rounded); err == nil && !next.Equal(tick) {
+		return roundToTick(rounded, next, rounding)
+	}
+
+	return rounded, nil
+}
+
+// Validate checks the price is a multiple of its tick size.
+func (t Ticks) Validate(price decimal.Decimal) error {
+	tick, err := t.Tick(price)
+	if err != nil {
+		return err
+	}
+
+	if !price.Mod(tick).IsZero() {
+		return fmt.Errorf("%w: %s is not a multiple of %s", ErrOffTick, price, tick)
+	}
+
+	return nil
+}
+
+// RoundOrder rounds the price and stop trigger of the order onto the schedule.
+func (t Ticks) RoundOrder(order NewOrder, rounding Rounding) (NewOrder, error) {
+	var err error
+
+	if !order.Price.IsZero() {
+		if order.Price, err = t.Round(order.Price, rounding); err != nil {
+			return NewOrder{}, fmt.Errorf("price: %w", err)
+		}
+	}
+
+	if !order.StopTrigger.IsZero() {
+		if order.StopTrigger, err = t.Round(order.StopTrigger, rounding); err != nil {
+			return NewOrder{}, fmt.Errorf("stop trigger: %w", err)
+		}
+	}
+
+	return order, nil
+}
+
+// ValidateOrder checks the price and stop trigger of the order are on tick.
+func (t Ticks) ValidateOrder(order NewOrder) error {
+	var errs []error
+
+	if !order.Price.IsZero() {
+		if err := t.Validate(order.Price); err != nil {
+			errs = append(errs, fmt.Errorf("price: %w", err))
+		}
+	}
+
+	if !order.StopTrigger.IsZero() {
+		if err := t.Validate(order.StopTrigger); err != nil {
+			errs = append(errs, fmt.Errorf("stop trigger: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetOrderTicks returns the tick schedule that prices the order: the
+// underlying's option ticks for single option orders, pennies for multi-leg
+// equity option orders, the expiration's ticks for future options, the
+// future's spread ticks for multi-leg futures orders and the instrument's
+// ticks otherwise.
+func (c *Client) GetOrderTicks(order NewOrder) (Ticks, error) {
+	if len(order.Legs) == 0 {
+		return Ticks{}, errors.New("order requires legs")
+	}
+
+	leg := order.Legs[0]
+	for _, l := range order.Legs {
+		if l.InstrumentType == EquityOptionIT || l.InstrumentType == FutureOptionIT {
+			leg = l
+			break
+		}
+	}
+
+	switch leg.InstrumentType {
+	case EquityIT:
+		equity, _, err := c.GetEquity(leg.Symbol)
+		if err != nil {
+			return Ticks{}, err
+		}
+		return equity.Ticks(), nil
+	case EquityOptionIT:
+		if len(order.Legs) > 1 {
+			return pennyTicks(), nil
+		}
+		occ, err := NewOCCFromString(leg.Symbol)
+		if err != nil {
+			return Ticks{}, err
+		}
+		option, _, err := c.GetEquityOption(occ, true)
+		if err != nil {
+			return Ticks{}, err
+		}
+		equity, _, err := c.GetEquity(option.UnderlyingSymbol)
+		if err != nil {
+			return Ticks{}, err
+		}
+		return equity.OptionTicks(), nil
+	case FutureIT:
+		future, _, err := c.GetFuture(strings.TrimPrefix(leg.Symbol, "/"))
+		if err != nil {
+			return Ticks{}, err
+		}
+		if len(order.Legs) > 1 {
+			ticks := future.SpreadTicks()
+			for _, l := range order.Legs {
+				if l.InstrumentType == FutureIT && l.Symbol != leg.Symbol {
+					ticks.Symbol = l.Symbol
+					break
+				}
+			}
+			return ticks, nil
+		}
+		return future.Ticks(), nil
+	case FutureOptionIT:
+		return c.futureOptionTicks(leg.Symbol)
+	case Crypto:
+		crypto, _, err := c.GetCryptocurrency(Cryptocurrency(leg.Symbol))
+		if err != nil {
+			return Ticks{}, err
+		}
+		return crypto.Ticks(), nil
+	default:
+		return Ticks{}, fmt.Errorf("no tick schedule for %s leg %s", leg.InstrumentType, leg.Symbol)
+	}
+}
+
+// schedule returns the tick sizes of the symbol, the generic tick sizes when
+// none are specific to it and the whole schedule when the ticks have no
+// symbol.
+func (t Ticks) schedule() []TickSize {
+	var matching, generic []TickSize
+	for _, ts := range t.Schedule {
+		switch {
+		case ts.Symbol == "":
+			generic = append(generic, ts)
+		case t.Symbol != "" && ts.Symbol == t.Symbol:
+			matching = append(matching, ts)
+		}
+	}
+
+	switch {
+	case len(matching) > 0:
+		return matching
+	case len(generic) > 0:
+		return generic
+	case t.Symbol == "":
+		return t.Schedule
+	default:
+		return nil
+	}
+}
+
+// futureOptionTicks finds the expiration of the future option in the
+// product's nested chains.
+func (c *Client) futureOptionTicks(symbol string) (Ticks, error) {
+	fos, err := NewFOSFromString(symbol)
+	if err != nil {
+		return Ticks{}, err
+	}
+
+	future, err := NewFSFromString(fos.FutureContractCode)
+	if err != nil {
+		return Ticks{}, err
+	}
+
+	chains, _, err := c.GetNestedFuturesOptionChains(future.ProductCode)
+	if err != nil {
+		return Ticks{}, err
+	}
+
+	for _, chain := range chains.OptionChains {
+		for _, exp := range chain.Expirations {
+			if exp.OptionContractSymbol == fos.OptionContractCode {
+				return exp.Ticks(), nil
+			}
+		}
+	}
+
+	return Ticks{}, fmt.Errorf("no %s expiration for %s", fos.OptionContractCode, symbol)
+}
+
+// fractional snaps the tick to the nearest multiple of the smallest fraction
+// i.e. 1/64 for half 32nds, so ticks sent rounded stay exact.
+func (t Ticks) fractional(tick decimal.Decimal) decimal.Decimal {
+	if !t.MainFraction.IsPositive() {
+		return tick
+	}
+
+	denominator := t.MainFraction
+	if t.SubFraction.GreaterThan(decimal.NewFromInt(1)) {
+		denominator = denominator.Mul(t.SubFraction)
+	}
+
+	units := tick.Mul(denominator).Round(0)
+	if units.LessThan(decimal.NewFromInt(1)) {
+		units = decimal.NewFromInt(1)
+	}
+
+	return units.Div(denominator)
+}
+
+func roundToTick(price, tick decimal.Decimal, rounding Rounding) (decimal.Decimal, error) {
+	quotient, remainder := price.QuoRem(tick, 0)
+	one := decimal.NewFromInt(1)
+
+	switch rounding {
+	case RoundDown:
+		if remainder.IsNegative() {
+			quotient = quotient.Sub(one)
+		}
+	case RoundUp:
+		if remainder.IsPositive() {
+			quotient = quotient.Add(one)
+		}
+	case RoundNearest:
+		if remainder.Abs().Mul(decimal.NewFromInt(2)).GreaterThanOrEqual(tick) {
+			quotient = quotient.Add(decimal.NewFromInt(int64(remainder.Sign())))
+		}
+	default:
+		return decimal.Zero, fmt.Errorf("unknown rounding %q", rounding)
+	}
+
+	return quotient.Mul(tick), nil
+}
@@ -0,0 +1,268 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func testEquity(t *testing.T) Equity {
+	t.Helper()
+
+	var resp struct {
+		Equity Equity `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(equityResp), &resp))
+
+	return resp.Equity
+}
+
+func TestTicksPennyPilot(t *testing.T) {
+	ticks := testEquity(t).OptionTicks()
+
+	for price, tick := range map[string]string{"0.5": "0.01", "2.99": "0.01", "3": "0.05", "12.4": "0.05"} {
+		got, err := ticks.Tick(decimal.RequireFromString(price))
+		require.NoError(t, err)
+		require.Equal(t, tick, got.String(), price)
+	}
+
+	for _, tc := range []struct {
+		price    string
+		rounding Rounding
+		expected string
+	}{
+		{"1.234", RoundDown, "1.23"},
+		{"1.231", RoundUp, "1.24"},
+		{"1.235", RoundNearest, "1.24"},
+		{"1.234", RoundNearest, "1.23"},
+		{"3.03", RoundDown, "3"},
+		{"3.03", RoundUp, "3.05"},
+		{"3.024", RoundNearest, "3"},
+		{"3.025", RoundNearest, "3.05"},
+		// pennies round up onto the nickel schedule
+		{"2.996", RoundUp, "3"},
+		{"2.995", RoundNearest, "3"},
+		{"1.25", RoundUp, "1.25"},
+	} {
+		rounded, err := ticks.Round(decimal.RequireFromString(tc.price), tc.rounding)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, rounded.String(), "%s %s", tc.rounding, tc.price)
+	}
+
+	require.NoError(t, ticks.Validate(decimal.RequireFromString("2.97")))
+	require.NoError(t, ticks.Validate(decimal.RequireFromString("3.15")))
+	require.ErrorIs(t, ticks.Validate(decimal.RequireFromString("3.17")), ErrOffTick)
+	require.EqualError(t, ticks.Validate(decimal.RequireFromString("1.005")), "price is off tick: 1.005 is not a multiple of 0.01")
+
+	_, err := ticks.Round(decimal.NewFromInt(1), "Sideways")
+	require.EqualError(t, err, `unknown rounding "Sideways"`)
+}
+
+func TestTicksNonPennyAndEquity(t *testing.T) {
+	ticks := Ticks{Schedule: []TickSize{
+		{Value: decimal.RequireFromString("0.05"), Threshold: decimal.NewFromInt(3)},
+		{Value: decimal.RequireFromString("0.1")},
+	}}
+
+	rounded, err := ticks.Round(decimal.RequireFromString("2.97"), RoundNearest)
+	require.NoError(t, err)
+	require.Equal(t, "2.95", rounded.String())
+
+	rounded, err = ticks.Round(decimal.RequireFromString("4.36"), RoundDown)
+	require.NoError(t, err)
+	require.Equal(t, "4.3", rounded.String())
+
+	equity := testEquity(t).Ticks()
+	require.NoError(t, equity.Validate(decimal.RequireFromString("0.4512")))
+	require.ErrorIs(t, equity.Validate(decimal.RequireFromString("1.4512")), ErrOffTick)
+
+	_, err = Ticks{}.Tick(decimal.NewFromInt(1))
+	require.EqualError(t, err, "no tick size for price 1")
+}
+
+func TestTicksFractional(t *testing.T) {
+	// 10 year notes trade in half 32nds, sent rounded as 0.0156
+	notes := Future{
+		TickSize:     decimal.RequireFromString("0.0156"),
+		MainFraction: decimal.NewFromInt(32),
+		SubFraction:  decimal.NewFromInt(2),
+	}.Ticks()
+
+	tick, err := notes.Tick(decimal.NewFromInt(110))
+	require.NoError(t, err)
+	require.Equal(t, "0.015625", tick.String())
+
+	// 110'16.5 is 110 + 16.5/32
+	require.NoError(t, notes.Validate(decimal.RequireFromString("110.515625")))
+	require.ErrorIs(t, notes.Validate(decimal.RequireFromString("110.52")), ErrOffTick)
+
+	rounded, err := notes.Round(decimal.RequireFromString("110.52"), RoundNearest)
+	require.NoError(t, err)
+	require.Equal(t, "110.515625", rounded.String())
+
+	rounded, err = notes.Round(decimal.RequireFromString("110.52"), RoundUp)
+	require.NoError(t, err)
+	require.Equal(t, "110.53125", rounded.String())
+
+	// corn trades in quarter cents quoted in 8ths
+	corn := Future{
+		TickSize:     decimal.RequireFromString("0.25"),
+		MainFraction: decimal.NewFromInt(8),
+	}.Ticks()
+
+	tick, err = corn.Tick(decimal.NewFromInt(450))
+	require.NoError(t, err)
+	require.Equal(t, "0.25", tick.String())
+
+	es := Future{
+		TickSize:        decimal.RequireFromString("0.25"),
+		SpreadTickSizes: []TickSize{{Value: decimal.RequireFromString("0.05")}},
+	}
+	require.NoError(t, es.SpreadTicks().Validate(decimal.RequireFromString("12.35")))
+	require.ErrorIs(t, es.Ticks().Validate(decimal.RequireFromString("4512.35")), ErrOffTick)
+}
+
+func TestTicksSymbol(t *testing.T) {
+	ticks := Ticks{Symbol: "/ESU3", Schedule: []TickSize{
+		{Value: decimal.RequireFromString("0.1"), Symbol: "/ESZ3"},
+		{Value: decimal.RequireFromString("0.05"), Symbol: "/ESU3"},
+		{Value: decimal.RequireFromString("0.25")},
+	}}
+
+	tick, err := ticks.Tick(decimal.NewFromInt(12))
+	require.NoError(t, err)
+	require.Equal(t, "0.05", tick.String())
+
+	ticks.Symbol = "/ESH4"
+	tick, err = ticks.Tick(decimal.NewFromInt(12))
+	require.NoError(t, err)
+	require.Equal(t, "0.25", tick.String())
+
+	ticks.Schedule = ticks.Schedule[:2]
+	_, err = ticks.Tick(decimal.NewFromInt(12))
+	require.EqualError(t, err, "no tick size for price 12")
+
+	ticks.Symbol = ""
+	tick, err = ticks.Tick(decimal.NewFromInt(12))
+	require.NoError(t, err)
+	require.Equal(t, "0.1", tick.String())
+}
+
+func TestTicksOrder(t *testing.T) {
+	ticks := testEquity(t).OptionTicks()
+
+	order := NewOrder{
+		OrderType:   StopLimit,
+		Price:       decimal.RequireFromString("3.03"),
+		StopTrigger: decimal.RequireFromString("3.12"),
+	}
+
+	err := ticks.ValidateOrder(order)
+	require.ErrorIs(t, err, ErrOffTick)
+	require.Contains(t, err.Error(), "price: price is off tick: 3.03")
+	require.Contains(t, err.Error(), "stop trigger: price is off tick: 3.12")
+
+	rounded, err := ticks.RoundOrder(order, RoundNearest)
+	require.NoError(t, err)
+	require.Equal(t, "3.05", rounded.Price.String())
+	require.Equal(t, "3.1", rounded.StopTrigger.String())
+	require.NoError(t, ticks.ValidateOrder(rounded))
+
+	_, err = NewOrderBuilder().
+		BuyToOpen("AAPL  230616C00185000", decimal.NewFromInt(1)).
+		Limit(decimal.RequireFromString("3.03")).
+		Debit().
+		Ticks(ticks).
+		Build()
+	require.ErrorIs(t, err, ErrInvalidOrder)
+	require.ErrorIs(t, err, ErrOffTick)
+
+	_, err = NewOrderBuilder().
+		BuyToOpen("AAPL  230616C00185000", decimal.NewFromInt(1)).
+		Limit(decimal.RequireFromString("3.05")).
+		Debit().
+		Ticks(ticks).
+		Build()
+	require.NoError(t, err)
+}
+
+func TestGetOrderTicks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/instruments/equity-options/AAPL  230616C00185000", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, equityOptionResp)
+	})
+	mux.HandleFunc("/instruments/equities/AAPL", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, equityResp)
+	})
+	mux.HandleFunc("/instruments/futures/ESM3", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, futureResp)
+	})
+	mux.HandleFunc("/futures-option-chains/ES/nested", func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, futuresOptionChainsNested)
+	})
+
+	spread := NewOrder{Legs: []NewOrderLeg{
+		{InstrumentType: EquityIT, Symbol: "AAPL", Action: Buy},
+		{InstrumentType: EquityOptionIT, Symbol: "AAPL  230616C00185000", Action: STO},
+	}}
+
+	ticks, err := client.GetOrderTicks(spread)
+	require.NoError(t, err)
+	require.Equal(t, pennyTicks(), ticks)
+	require.NoError(t, ticks.Validate(decimal.RequireFromString("3.03")))
+
+	ticks, err = client.GetOrderTicks(NewOrder{Legs: spread.Legs[1:]})
+	require.NoError(t, err)
+	require.Equal(t, testEquity(t).OptionTicks(), ticks)
+
+	ticks, err = client.GetOrderTicks(NewOrder{Legs: []NewOrderLeg{{InstrumentType: EquityIT, Symbol: "AAPL"}}})
+	require.NoError(t, err)
+	require.Equal(t, testEquity(t).Ticks(), ticks)
+
+	ticks, err = client.GetOrderTicks(NewOrder{Legs: []NewOrderLeg{{InstrumentType: FutureIT, Symbol: "/ESM3"}}})
+	require.NoError(t, err)
+	tick, err := ticks.Tick(decimal.NewFromInt(4300))
+	require.NoError(t, err)
+	require.Equal(t, "0.25", tick.String())
+
+	ticks, err = client.GetOrderTicks(NewOrder{Legs: []NewOrderLeg{
+		{InstrumentType: FutureIT, Symbol: "/ESM3", Action: Buy},
+		{InstrumentType: FutureIT, Symbol: "/ESU3", Action: Sell},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "/ESU3", ticks.Symbol)
+	require.NoError(t, ticks.Validate(decimal.RequireFromString("12.35")))
+
+	ticks, err = client.GetOrderTicks(NewOrder{Legs: []NewOrderLeg{{InstrumentType: FutureOptionIT, Symbol: "./ESU3 EW4N3 230728C4530"}}})
+	require.NoError(t, err)
+	require.NoError(t, ticks.Validate(decimal.RequireFromString("4.95")))
+	require.ErrorIs(t, ticks.Validate(decimal.RequireFromString("5.05")), ErrOffTick)
+
+	_, err = client.GetOrderTicks(NewOrder{Legs: []NewOrderLeg{{InstrumentType: FutureOptionIT, Symbol: "./ESU3 EW1Q3 230804C4530"}}})
+	require.EqualError(t, err, "no EW1Q3 expiration for ./ESU3 EW1Q3 230804C4530")
+
+	_, err = client.GetOrderTicks(NewOrder{})
+	require.EqualError(t, err, "order requires legs")
+
+	_, err = client.GetOrderTicks(NewOrder{Legs: []NewOrderLeg{{InstrumentType: Bond, Symbol: "T"}}})
+	require.EqualError(t, err, "no tick schedule for Bond leg T")
+}
+
+func TestGetOrderTicksError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/instruments/equities/AAPL", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(401)
+		fmt.Fprint(writer, tastyUnauthorizedError)
+	})
+
+	_, err := client.GetOrderTicks(NewOrder{Legs: []NewOrderLeg{{InstrumentType: EquityIT, Symbol: "AAPL"}}})
+	expectedUnauthorized(t, err)
+}
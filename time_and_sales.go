@@ -0,0 +1,336 @@
+package tasty
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const defaultTapeRetention = 15 * time.Minute
+
+var errNoFeed = errors.New("no market data feed configured")
+
+// TapePrint is a single print on the tape classified against the market at the time of the trade.
+type TapePrint struct {
+	Symbol string
+	Index  int64
+	Time   time.Time
+	Price  decimal.Decimal
+	Size   decimal.Decimal
+	Bid    decimal.Decimal
+	Ask    decimal.Decimal
+	Side   PrintSide
+}
+
+// TapeStats are the aggregated prints for a symbol over a window.
+type TapeStats struct {
+	Symbol string
+	Window time.Duration
+	Trades int
+	Volume decimal.Decimal
+	// Volume weighted average price
+	VWAP decimal.Decimal
+	High decimal.Decimal
+	Low  decimal.Decimal
+	// Volume printed at or near the ask
+	BuyVolume decimal.Decimal
+	// Volume printed at or near the bid
+	SellVolume decimal.Decimal
+	// Volume printed at the mid or without a market
+	NeutralVolume decimal.Decimal
+	// (BuyVolume - SellVolume) / Volume, ranges from -1 to 1
+	Pressure decimal.Decimal
+}
+
+// TapeConfig configures the retention of a TimeAndSalesTape.
+type TapeConfig struct {
+	// How long prints are retained relative to the latest print.
+	// Defaults to 15 minutes; windows longer than this will be truncated.
+	Retention time.Duration
+	// Maximum number of prints retained per symbol, 0 for no limit.
+	MaxPrints int
+}
+
+// TimeAndSalesTape keeps a rolling tape of classified prints per symbol
+// from TimeAndSale events, using Quote events for the market when a print
+// doesn't carry one.
+type TimeAndSalesTape struct {
+	feed   MarketDataFeed
+	config TapeConfig
+	mu     sync.RWMutex
+	prints map[string][]TapePrint
+	quotes map[string]Quote
+	// events of unfinished transactions and snapshots by symbol
+	pending   map[string][]TimeAndSale
+	snapshots map[string]bool
+}
+
+// NewTimeAndSalesTape creates a tape reading from the given feed.
+// The feed may be nil when events are supplied through Apply.
+func NewTimeAndSalesTape(feed MarketDataFeed, config TapeConfig) *TimeAndSalesTape {
+	if config.Retention <= 0 {
+		config.Retention = defaultTapeRetention
+	}
+
+	return &TimeAndSalesTape{
+		feed:      feed,
+		config:    config,
+		prints:    map[string][]TapePrint{},
+		quotes:    map[string]Quote{},
+		pending:   map[string][]TimeAndSale{},
+		snapshots: map[string]bool{},
+	}
+}
+
+// Watch subscribes to TimeAndSale and Quote events for the symbols.
+func (t *TimeAndSalesTape) Watch(symbols ...string) error {
+	if t.feed == nil {
+		return errNoFeed
+	}
+
+	return t.feed.Subscribe(tapeSubscriptions(symbols)...)
+}
+
+// Unwatch unsubscribes the symbols and drops their tape.
+func (t *TimeAndSalesTape) Unwatch(symbols ...string) error {
+	if t.feed == nil {
+		return errNoFeed
+	}
+
+	t.mu.Lock()
+	for _, symbol := range symbols {
+		delete(t.prints, symbol)
+		delete(t.quotes, symbol)
+		delete(t.pending, symbol)
+		delete(t.snapshots, symbol)
+	}
+	t.mu.Unlock()
+
+	return t.feed.Unsubscribe(tapeSubscriptions(symbols)...)
+}
+
+// Run applies events from the feed until its event channel is closed.
+func (t *TimeAndSalesTape) Run() error {
+	if t.feed == nil {
+		return errNoFeed
+	}
+
+	for event := range t.feed.Events() {
+		t.Apply(event)
+	}
+
+	return nil
+}
+
+// Apply updates the tape with a market event. Events other than
+// TimeAndSale and Quote are ignored. Prints are kept in time order whatever
+// order they arrive in, snapshots replacing the tape of their symbol once
+// complete and transactions applied once no longer pending.
+func (t *TimeAndSalesTape) Apply(event MarketEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch e := event.(type) {
+	case Quote:
+		t.quotes[e.EventSymbol] = e
+	case TimeAndSale:
+		t.applyTimeAndSale(e)
+	}
+}
+
+// Prints returns a copy of the retained prints for the symbol, oldest first.
+func (t *TimeAndSalesTape) Prints(symbol string) []TapePrint {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	prints := make([]TapePrint, len(t.prints[symbol]))
+	copy(prints, t.prints[symbol])
+
+	return prints
+}
+
+// Stats aggregates the prints for the symbol within the window ending at the latest print.
+func (t *TimeAndSalesTape) Stats(symbol string, window time.Duration) TapeStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	stats := TapeStats{Symbol: symbol, Window: window}
+
+	prints := t.prints[symbol]
+	if len(prints) == 0 {
+		return stats
+	}
+
+	since := prints[len(prints)-1].Time.Add(-window)
+	notional := decimal.Zero
+
+	for _, p := range prints {
+		if p.Time.Before(since) {
+			continue
+		}
+
+		if stats.Trades == 0 || p.Price.GreaterThan(stats.High) {
+			stats.High = p.Price
+		}
+		if stats.Trades == 0 || p.Price.LessThan(stats.Low) {
+			stats.Low = p.Price
+		}
+
+		stats.Trades++
+		stats.Volume = stats.Volume.Add(p.Size)
+		notional = notional.Add(p.Price.Mul(p.Size))
+
+		switch p.Side {
+		case AtAsk, NearAsk:
+			stats.BuyVolume = stats.BuyVolume.Add(p.Size)
+		case AtBid, NearBid:
+			stats.SellVolume = stats.SellVolume.Add(p.Size)
+		default:
+			stats.NeutralVolume = stats.NeutralVolume.Add(p.Size)
+		}
+	}
+
+	if !stats.Volume.IsZero() {
+		stats.VWAP = notional.Div(stats.Volume)
+		stats.Pressure = stats.BuyVolume.Sub(stats.SellVolume).Div(stats.Volume)
+	}
+
+	return stats
+}
+
+// ClassifyPrint classifies a trade price against the bid and ask.
+func ClassifyPrint(price, bid, ask decimal.Decimal) PrintSide {
+	if bid.IsZero() || ask.IsZero() || ask.LessThan(bid) {
+		return UnknownSide
+	}
+
+	mid := bid.Add(ask).Div(decimal.NewFromInt(2))
+
+	switch {
+	case price.GreaterThanOrEqual(ask):
+		return AtAsk
+	case price.LessThanOrEqual(bid):
+		return AtBid
+	case price.Equal(mid):
+		return AtMid
+	case price.GreaterThan(mid):
+		return NearAsk
+	default:
+		return NearBid
+	}
+}
+
+// applyTimeAndSale buffers the event until its transaction or snapshot is
+// complete and then applies the buffered events.
+func (t *TimeAndSalesTape) applyTimeAndSale(e TimeAndSale) {
+	symbol := e.EventSymbol
+
+	if e.EventFlags&SnapshotBegin != 0 {
+		t.pending[symbol] = nil
+		t.snapshots[symbol] = true
+	}
+
+	t.pending[symbol] = append(t.pending[symbol], e)
+
+	snapshot := t.snapshots[symbol]
+	if e.EventFlags&TxPending != 0 || (snapshot && e.EventFlags&(SnapshotEnd|SnapshotSnip) == 0) {
+		return
+	}
+
+	if snapshot {
+		delete(t.prints, symbol)
+		delete(t.snapshots, symbol)
+	}
+
+	for _, event := range t.pending[symbol] {
+		t.applyPrint(event)
+	}
+	delete(t.pending, symbol)
+
+	t.retain(symbol)
+}
+
+// applyPrint adds, corrects or removes the print with the event's index.
+func (t *TimeAndSalesTape) applyPrint(e TimeAndSale) {
+	prints := t.prints[e.EventSymbol]
+
+	for i := range prints {
+		if prints[i].Index == e.Index {
+			prints = append(prints[:i], prints[i+1:]...)
+			break
+		}
+	}
+
+	if e.EventFlags&RemoveEvent == 0 && e.Type != "CANCEL" {
+		p := t.newPrint(e)
+		i := sort.Search(len(prints), func(i int) bool {
+			if prints[i].Time.Equal(p.Time) {
+				return prints[i].Index > p.Index
+			}
+			return prints[i].Time.After(p.Time)
+		})
+
+		prints = append(prints, TapePrint{})
+		copy(prints[i+1:], prints[i:])
+		prints[i] = p
+	}
+
+	t.prints[e.EventSymbol] = prints
+}
+
+// retain removes prints that fall outside of the retention period of the
+// newest print or beyond the maximum number of prints.
+func (t *TimeAndSalesTape) retain(symbol string) {
+	prints := t.prints[symbol]
+	if len(prints) == 0 {
+		delete(t.prints, symbol)
+		return
+	}
+
+	cutoff := prints[len(prints)-1].Time.Add(-t.config.Retention)
+	start := 0
+	for start < len(prints) && prints[start].Time.Before(cutoff) {
+		start++
+	}
+	if t.config.MaxPrints > 0 && len(prints)-start > t.config.MaxPrints {
+		start = len(prints) - t.config.MaxPrints
+	}
+
+	if start > 0 {
+		// copied so the pruned prints aren't kept by the backing array
+		t.prints[symbol] = append([]TapePrint(nil), prints[start:]...)
+	}
+}
+
+func (t *TimeAndSalesTape) newPrint(e TimeAndSale) TapePrint {
+	bid, ask := e.BidPrice, e.AskPrice
+	if bid.IsZero() || ask.IsZero() {
+		quote := t.quotes[e.EventSymbol]
+		bid, ask = quote.BidPrice, quote.AskPrice
+	}
+
+	return TapePrint{
+		Symbol: e.EventSymbol,
+		Index:  e.Index,
+		Time:   e.Time.Time(),
+		Price:  e.Price,
+		Size:   e.Size,
+		Bid:    bid,
+		Ask:    ask,
+		Side:   ClassifyPrint(e.Price, bid, ask),
+	}
+}
+
+func tapeSubscriptions(symbols []string) []FeedSubscription {
+	subs := make([]FeedSubscription, 0, len(symbols)*2)
+	for _, symbol := range symbols {
+		subs = append(subs,
+			FeedSubscription{Type: TimeAndSaleEvent, Symbol: symbol},
+			FeedSubscription{Type: QuoteEvent, Symbol: symbol})
+	}
+
+	return subs
+}
@@ -0,0 +1,204 @@
+package tasty //nolint:testpackage // testing private field
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func tapeEvent(index int64, at time.Time, price, size, bid, ask string) TimeAndSale {
+	e := TimeAndSale{
+		EventSymbol: "SPY",
+		Index:       index,
+		Time:        EpochMillis(at.UnixMilli()),
+		Price:       decimal.RequireFromString(price),
+		Size:        decimal.RequireFromString(size),
+		Type:        "NEW",
+	}
+	if bid != "" {
+		e.BidPrice = decimal.RequireFromString(bid)
+		e.AskPrice = decimal.RequireFromString(ask)
+	}
+	return e
+}
+
+func TestClassifyPrint(t *testing.T) {
+	bid := decimal.RequireFromString("10.00")
+	ask := decimal.RequireFromString("10.10")
+
+	require.Equal(t, AtAsk, ClassifyPrint(decimal.RequireFromString("10.10"), bid, ask))
+	require.Equal(t, AtAsk, ClassifyPrint(decimal.RequireFromString("10.15"), bid, ask))
+	require.Equal(t, NearAsk, ClassifyPrint(decimal.RequireFromString("10.08"), bid, ask))
+	require.Equal(t, AtMid, ClassifyPrint(decimal.RequireFromString("10.05"), bid, ask))
+	require.Equal(t, NearBid, ClassifyPrint(decimal.RequireFromString("10.01"), bid, ask))
+	require.Equal(t, AtBid, ClassifyPrint(decimal.RequireFromString("9.99"), bid, ask))
+	require.Equal(t, UnknownSide, ClassifyPrint(decimal.RequireFromString("10.00"), decimal.Zero, ask))
+	require.Equal(t, UnknownSide, ClassifyPrint(decimal.RequireFromString("10.00"), ask, bid))
+}
+
+func TestTimeAndSalesTapeStats(t *testing.T) {
+	tape := NewTimeAndSalesTape(nil, TapeConfig{})
+	start := time.Date(2023, 8, 14, 14, 30, 0, 0, time.UTC)
+
+	tape.Apply(tapeEvent(1, start, "450.00", "100", "449.90", "450.00"))
+	tape.Apply(tapeEvent(2, start.Add(time.Minute), "449.90", "300", "449.90", "450.00"))
+	// no market on the print, falls back to the latest quote
+	tape.Apply(Quote{EventSymbol: "SPY", BidPrice: decimal.RequireFromString("449.80"), AskPrice: decimal.RequireFromString("450.00")})
+	tape.Apply(tapeEvent(3, start.Add(2*time.Minute), "449.90", "200", "", ""))
+	// ignored events
+	tape.Apply(Greeks{EventSymbol: "SPY"})
+
+	prints := tape.Prints("SPY")
+	require.Len(t, prints, 3)
+	require.Equal(t, AtAsk, prints[0].Side)
+	require.Equal(t, AtBid, prints[1].Side)
+	require.Equal(t, AtMid, prints[2].Side)
+	require.Equal(t, "449.8", prints[2].Bid.String())
+
+	stats := tape.Stats("SPY", 5*time.Minute)
+	require.Equal(t, 3, stats.Trades)
+	require.Equal(t, "600", stats.Volume.String())
+	require.Equal(t, "100", stats.BuyVolume.String())
+	require.Equal(t, "300", stats.SellVolume.String())
+	require.Equal(t, "200", stats.NeutralVolume.String())
+	require.Equal(t, "-0.3333333333333333", stats.Pressure.String())
+	require.Equal(t, "449.9166666666666667", stats.VWAP.String())
+	require.Equal(t, "450", stats.High.String())
+	require.Equal(t, "449.9", stats.Low.String())
+
+	// only the last two prints fall within the window
+	stats = tape.Stats("SPY", time.Minute)
+	require.Equal(t, 2, stats.Trades)
+	require.Equal(t, "500", stats.Volume.String())
+	require.Equal(t, "449.9", stats.VWAP.String())
+
+	require.Zero(t, tape.Stats("QQQ", time.Minute).Trades)
+}
+
+func TestTimeAndSalesTapeCorrections(t *testing.T) {
+	tape := NewTimeAndSalesTape(nil, TapeConfig{})
+	start := time.Date(2023, 8, 14, 14, 30, 0, 0, time.UTC)
+
+	tape.Apply(tapeEvent(1, start, "450.00", "100", "449.90", "450.00"))
+	tape.Apply(tapeEvent(2, start, "450.00", "100", "449.90", "450.00"))
+
+	correction := tapeEvent(1, start, "449.90", "50", "449.90", "450.00")
+	correction.Type = "CORRECTION"
+	tape.Apply(correction)
+
+	cancel := tapeEvent(2, start, "450.00", "100", "449.90", "450.00")
+	cancel.Type = "CANCEL"
+	tape.Apply(cancel)
+
+	prints := tape.Prints("SPY")
+	require.Len(t, prints, 1)
+	require.Equal(t, "50", prints[0].Size.String())
+	require.Equal(t, AtBid, prints[0].Side)
+}
+
+func TestTimeAndSalesTapeOutOfOrder(t *testing.T) {
+	tape := NewTimeAndSalesTape(nil, TapeConfig{})
+	start := time.Date(2023, 8, 14, 14, 30, 0, 0, time.UTC)
+
+	tape.Apply(tapeEvent(3, start.Add(2*time.Minute), "449.90", "200", "449.90", "450.00"))
+	tape.Apply(tapeEvent(1, start, "450.00", "100", "449.90", "450.00"))
+	tape.Apply(tapeEvent(2, start.Add(time.Minute), "449.90", "300", "449.90", "450.00"))
+
+	prints := tape.Prints("SPY")
+	require.Len(t, prints, 3)
+	require.Equal(t, int64(1), prints[0].Index)
+	require.Equal(t, int64(2), prints[1].Index)
+	require.Equal(t, int64(3), prints[2].Index)
+
+	// the window ends at the latest print, not the last one received
+	stats := tape.Stats("SPY", time.Minute)
+	require.Equal(t, 2, stats.Trades)
+	require.Equal(t, "500", stats.Volume.String())
+}
+
+func TestTimeAndSalesTapeEventFlags(t *testing.T) {
+	tape := NewTimeAndSalesTape(nil, TapeConfig{})
+	start := time.Date(2023, 8, 14, 14, 30, 0, 0, time.UTC)
+
+	tape.Apply(tapeEvent(9, start, "1", "1", "", ""))
+
+	// snapshots arrive newest first and replace the tape once complete
+	first := tapeEvent(3, start.Add(2*time.Minute), "3", "1", "", "")
+	first.EventFlags = SnapshotBegin
+	tape.Apply(first)
+	tape.Apply(tapeEvent(2, start.Add(time.Minute), "2", "1", "", ""))
+	require.Len(t, tape.Prints("SPY"), 1)
+
+	last := tapeEvent(1, start, "1", "1", "", "")
+	last.EventFlags = SnapshotEnd
+	tape.Apply(last)
+
+	prints := tape.Prints("SPY")
+	require.Len(t, prints, 3)
+	require.Equal(t, int64(1), prints[0].Index)
+	require.Equal(t, int64(3), prints[2].Index)
+
+	// transactions apply once no longer pending
+	removed := tapeEvent(2, start.Add(time.Minute), "2", "1", "", "")
+	removed.EventFlags = TxPending | RemoveEvent
+	tape.Apply(removed)
+	require.Len(t, tape.Prints("SPY"), 3)
+
+	tape.Apply(tapeEvent(4, start.Add(3*time.Minute), "4", "1", "", ""))
+
+	prints = tape.Prints("SPY")
+	require.Len(t, prints, 3)
+	require.Equal(t, int64(1), prints[0].Index)
+	require.Equal(t, int64(3), prints[1].Index)
+	require.Equal(t, int64(4), prints[2].Index)
+}
+
+func TestTimeAndSalesTapeRetention(t *testing.T) {
+	tape := NewTimeAndSalesTape(nil, TapeConfig{Retention: time.Minute, MaxPrints: 2})
+	start := time.Date(2023, 8, 14, 14, 30, 0, 0, time.UTC)
+
+	tape.Apply(tapeEvent(1, start, "1", "1", "", ""))
+	tape.Apply(tapeEvent(2, start.Add(2*time.Minute), "2", "1", "", ""))
+
+	prints := tape.Prints("SPY")
+	require.Len(t, prints, 1)
+	require.Equal(t, int64(2), prints[0].Index)
+	require.Equal(t, UnknownSide, prints[0].Side)
+
+	tape.Apply(tapeEvent(3, start.Add(2*time.Minute), "3", "1", "", ""))
+	tape.Apply(tapeEvent(4, start.Add(2*time.Minute), "4", "1", "", ""))
+
+	prints = tape.Prints("SPY")
+	require.Len(t, prints, 2)
+	require.Equal(t, int64(3), prints[0].Index)
+
+	// the pruned prints aren't kept by the backing array
+	require.Equal(t, 2, cap(tape.prints["SPY"]))
+}
+
+func TestTimeAndSalesTapeFeed(t *testing.T) {
+	tape := NewTimeAndSalesTape(nil, TapeConfig{})
+	require.ErrorIs(t, tape.Watch("SPY"), errNoFeed)
+	require.ErrorIs(t, tape.Unwatch("SPY"), errNoFeed)
+	require.ErrorIs(t, tape.Run(), errNoFeed)
+
+	feed := newFakeFeed()
+	tape = NewTimeAndSalesTape(feed, TapeConfig{})
+
+	require.NoError(t, tape.Watch("SPY"))
+	require.Equal(t, []FeedSubscription{
+		{Type: TimeAndSaleEvent, Symbol: "SPY"},
+		{Type: QuoteEvent, Symbol: "SPY"},
+	}, feed.subscribed)
+
+	feed.events <- tapeEvent(1, time.Now(), "450.00", "100", "449.90", "450.00")
+	close(feed.events)
+	require.NoError(t, tape.Run())
+	require.Len(t, tape.Prints("SPY"), 1)
+
+	require.NoError(t, tape.Unwatch("SPY"))
+	require.Len(t, feed.unsubscribed, 2)
+	require.Empty(t, tape.Prints("SPY"))
+}
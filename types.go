@@ -2,10 +2,17 @@ package tasty
 
 import (
 	"encoding/json"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+// StringToFloat32 is a float32 sent by the API as a string.
+//
+// Deprecated: prices lose precision as binary floating point, use StringToDecimal.
 type StringToFloat32 float32
 
 // UnmarshalJSON is the custom unmarshaler interface.
@@ -39,6 +46,35 @@ func (foe StringToFloat32) MarshalJSON() ([]byte, error) {
 	return json.Marshal(float32(foe))
 }
 
+// StringToDecimal is a decimal sent by the API as a string that may be empty
+// or NaN, both of which decode as zero.
+type StringToDecimal struct {
+	decimal.Decimal
+}
+
+// UnmarshalJSON is the custom unmarshaler interface.
+func (sd *StringToDecimal) UnmarshalJSON(data []byte) error {
+	num := strings.ReplaceAll(string(data), "\"", "")
+
+	if num == "" || num == "NaN" || num == "null" {
+		*sd = StringToDecimal{}
+		return nil
+	}
+
+	return sd.Decimal.UnmarshalJSON(data)
+}
+
+// queryDecimal is a decimal encoded into query parameters, omitting zero.
+type queryDecimal decimal.Decimal
+
+// EncodeValues is the custom query encoder interface.
+func (qd queryDecimal) EncodeValues(key string, v *url.Values) error {
+	if d := decimal.Decimal(qd); !d.IsZero() {
+		v.Set(key, d.String())
+	}
+	return nil
+}
+
 type Pagination struct {
 	PerPage            int     `json:"per-page"`
 	PageOffset         int     `json:"page-offset"`
@@ -50,3 +86,11 @@ type Pagination struct {
 	NextLink           *string `json:"next-link"`
 	PagingLinkTemplate *string `json:"paging-link-template"`
 }
+
+// EpochMillis is a unix timestamp in milliseconds as sent by the streamers.
+type EpochMillis int64
+
+// Time converts the timestamp into a time.Time.
+func (em EpochMillis) Time() time.Time {
+	return time.UnixMilli(int64(em))
+}
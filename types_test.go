@@ -56,3 +56,23 @@ func TestStringToFloat32(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "0", string(res))
 }
+
+func TestStringToDecimal(t *testing.T) {
+	type test struct {
+		Key tasty.StringToDecimal
+	}
+
+	for input, expected := range map[string]string{
+		`{"Key":"1.15"}`: "1.15",
+		`{"Key":1.15}`:   "1.15",
+		`{"Key":""}`:     "0",
+		`{"Key":"NaN"}`:  "0",
+		`{"Key":null}`:   "0",
+	} {
+		res := new(test)
+		require.NoError(t, json.Unmarshal([]byte(input), res))
+		require.Equal(t, expected, res.Key.String())
+	}
+
+	require.Error(t, json.Unmarshal([]byte(`{"Key":"."}`), new(test)))
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 	"unicode"
+
+	"github.com/shopspring/decimal"
 )
 
 var validMonthCodes = []string{string(January), string(February), string(March),
@@ -92,15 +94,16 @@ type FutureOptionsSymbology struct {
 	// Should start with / (You can use the FutureSymbology struct's Build method)
 	FutureContractCode string
 	OptionType         OptionType
-	Strike             int
-	Expiration         time.Time
+	// Strike price, fractional for products such as /ZN i.e. 108.5
+	Strike     decimal.Decimal
+	Expiration time.Time
 }
 
 // Builds the future option into correct symbology.
 func (foSym FutureOptionsSymbology) Build() string {
 	codes := fmt.Sprintf(".%s %s", foSym.FutureContractCode, foSym.OptionContractCode)
 	expiryString := foSym.Expiration.Format("060102")
-	return fmt.Sprintf("%s %s%s%d", codes, expiryString, foSym.OptionType, foSym.Strike)
+	return fmt.Sprintf("%s %s%s%s", codes, expiryString, foSym.OptionType, foSym.Strike.String())
 }
 
 // Parse the future options symbol into FutureOptionsSymbology struct.
@@ -138,7 +141,7 @@ func NewFOSFromString(symbol string) (FutureOptionsSymbology, error) {
 
 	sym.Expiration = expiry
 	sym.OptionType = optionType
-	sym.Strike = int(strike)
+	sym.Strike = strike
 
 	return sym, nil
 }
@@ -170,7 +173,7 @@ func containsString(s []string, e string) bool {
 type EquityOptionsSymbology struct {
 	Symbol     string
 	OptionType OptionType
-	Strike     float32
+	Strike     decimal.Decimal
 	Expiration time.Time
 }
 
@@ -182,6 +185,12 @@ func (sym EquityOptionsSymbology) Build() string {
 	return fmt.Sprintf("%s%s%s%s", symbol, expiryString, sym.OptionType, strikeString)
 }
 
+// Builds the equity option into the streamer symbol used by DXLink i.e. .AAPL230818C185.
+func (sym EquityOptionsSymbology) StreamerSymbol() string {
+	expiryString := sym.Expiration.Format("060102")
+	return fmt.Sprintf(".%s%s%s%s", sym.Symbol, expiryString, sym.OptionType, sym.Strike.String())
+}
+
 // Parse occ symbol into EquityOptionsSymbology struct.
 func NewOCCFromString(occSymbol string) (EquityOptionsSymbology, error) {
 	var sym EquityOptionsSymbology
@@ -199,14 +208,14 @@ func NewOCCFromString(occSymbol string) (EquityOptionsSymbology, error) {
 
 	sym.Expiration = expiry
 	sym.OptionType = optionType
-	sym.Strike = strike / float32(1000)
+	sym.Strike = strike.Shift(-3)
 
 	return sym, nil
 }
 
 // convert the strike into a string with correct padding.
-func getStrikeWithPadding(strike float32) string {
-	strikeString := fmt.Sprintf("%d", int(strike*1000))
+func getStrikeWithPadding(strike decimal.Decimal) string {
+	strikeString := strike.Shift(3).Truncate(0).String()
 	for len(strikeString) < 8 {
 		strikeString = "0" + strikeString
 	}
@@ -222,10 +231,10 @@ func getSymbolWithPadding(symbol string) string {
 	return symbol
 }
 
-func getExpiryTypeStrike(symbol string) (time.Time, OptionType, float32, error) {
+func getExpiryTypeStrike(symbol string) (time.Time, OptionType, decimal.Decimal, error) {
 	var expiry time.Time
 	var optionType OptionType
-	var strike float32
+	var strike decimal.Decimal
 
 	expiry, err := time.Parse("060102", symbol[:6])
 	if err != nil {
@@ -237,12 +246,46 @@ func getExpiryTypeStrike(symbol string) (time.Time, OptionType, float32, error)
 		return expiry, optionType, strike, fmt.Errorf("unknown option type: %s", optionType)
 	}
 
-	strike64, err := strconv.ParseFloat(symbol[7:], 32)
+	strike, err = decimal.NewFromString(symbol[7:])
 	if err != nil {
 		return expiry, optionType, strike, fmt.Errorf("invalid option strike: %s", symbol[7:])
 	}
 
-	strike = float32(strike64)
-
 	return expiry, optionType, strike, nil
 }
+
+// isSellAction returns whether or not the action sells.
+func isSellAction(action OrderAction) bool {
+	return action == STO || action == STC || action == Sell
+}
+
+// legQuantity returns the signed quantity of the leg, negative when selling.
+func legQuantity(leg NewOrderLeg) decimal.Decimal {
+	if isSellAction(leg.Action) {
+		return leg.Quantity.Neg()
+	}
+
+	return leg.Quantity
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// legsGCD returns the greatest common divisor of the leg quantities, 0 when a
+// quantity isn't whole.
+func legsGCD(legs []NewOrderLeg) int {
+	units := 0
+	for _, leg := range legs {
+		if !leg.Quantity.IsInteger() {
+			return 0
+		}
+		units = gcd(units, int(leg.Quantity.Abs().IntPart()))
+	}
+
+	return units
+}
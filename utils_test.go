@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,7 +16,7 @@ func TestFutureSymbology(t *testing.T) {
 		OptionContractCode: "EW4U9",
 		FutureContractCode: future.Build(),
 		OptionType:         Put,
-		Strike:             2975,
+		Strike:             decimal.NewFromInt(2975),
 		Expiration:         time.Date(2019, 9, 27, 0, 0, 0, 0, time.Local),
 	}
 
@@ -76,7 +77,7 @@ func TestNewFOSFromString(t *testing.T) {
 	require.Equal(t, "LO1X2", sym.OptionContractCode)
 	require.Equal(t, "/CLZ2", sym.FutureContractCode)
 	require.Equal(t, Call, sym.OptionType)
-	require.Equal(t, 91, sym.Strike)
+	require.Equal(t, "91", sym.Strike.String())
 	require.Equal(t, time.Date(2022, time.November, 4, 0, 0, 0, 0, time.UTC).Format(time.RFC1123),
 		sym.Expiration.Format(time.RFC1123))
 
@@ -128,17 +129,17 @@ func TestGetSymbolWithPadding(t *testing.T) {
 }
 
 func TestGetStrikeWithPadding(t *testing.T) {
-	require.Equal(t, "00645500", getStrikeWithPadding(645.5))
-	require.Equal(t, "00185000", getStrikeWithPadding(185))
-	require.Equal(t, "00015500", getStrikeWithPadding(15.5))
-	require.Equal(t, "00012000", getStrikeWithPadding(12))
-	require.Equal(t, "00005000", getStrikeWithPadding(5))
+	require.Equal(t, "00645500", getStrikeWithPadding(decimal.RequireFromString("645.5")))
+	require.Equal(t, "00185000", getStrikeWithPadding(decimal.NewFromInt(185)))
+	require.Equal(t, "00015500", getStrikeWithPadding(decimal.RequireFromString("15.5")))
+	require.Equal(t, "00012000", getStrikeWithPadding(decimal.NewFromInt(12)))
+	require.Equal(t, "00005000", getStrikeWithPadding(decimal.NewFromInt(5)))
 }
 
 func TestGetEquitySymbol(t *testing.T) {
 	sym := EquityOptionsSymbology{
 		Symbol:     "AAPL",
-		Strike:     185,
+		Strike:     decimal.NewFromInt(185),
 		OptionType: Call,
 		Expiration: time.Date(2023, 6, 16, 0, 0, 0, 0, time.UTC),
 	}
@@ -147,10 +148,26 @@ func TestGetEquitySymbol(t *testing.T) {
 	require.Equal(t, "AAPL  230616C00185000", occSymbol)
 }
 
+func TestGetEquityStreamerSymbol(t *testing.T) {
+	sym := EquityOptionsSymbology{
+		Symbol:     "AAPL",
+		Strike:     decimal.NewFromInt(185),
+		OptionType: Call,
+		Expiration: time.Date(2023, 8, 18, 0, 0, 0, 0, time.UTC),
+	}
+
+	require.Equal(t, ".AAPL230818C185", sym.StreamerSymbol())
+
+	sym.Strike = decimal.RequireFromString("182.5")
+	sym.OptionType = Put
+
+	require.Equal(t, ".AAPL230818P182.5", sym.StreamerSymbol())
+}
+
 func TestGetEquitySymbolFromSymbol(t *testing.T) {
 	sym := EquityOptionsSymbology{
 		Symbol:     "AAPL",
-		Strike:     185,
+		Strike:     decimal.NewFromInt(185),
 		OptionType: Call,
 		Expiration: time.Date(2023, 6, 16, 0, 0, 0, 0, time.UTC),
 	}
@@ -158,7 +175,7 @@ func TestGetEquitySymbolFromSymbol(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Equal(t, sym.Symbol, occSymbol.Symbol)
-	require.Equal(t, sym.Strike, occSymbol.Strike)
+	require.True(t, sym.Strike.Equal(occSymbol.Strike))
 	require.Equal(t, sym.OptionType, occSymbol.OptionType)
 	require.Equal(t,
 		sym.Expiration.Format(time.RFC1123),